@@ -1,11 +1,22 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
 	"fyne.io/fyne/v2/app"
+	"github.com/vision-go/vision-go/pkg/cli"
 	userinterface "github.com/vision-go/vision-go/pkg/userInterface"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if err := cli.New().Run(os.Args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, "vision-go:", err)
+			os.Exit(1)
+		}
+		return
+	}
 	a := app.New()
 	w := a.NewWindow("vision-go")
 	w.SetOnClosed(a.Quit)
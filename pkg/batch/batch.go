@@ -0,0 +1,218 @@
+package batch
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/vision-go/vision-go/pkg/pipeline"
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// DefaultPattern names the results the same way the tabs of the UI are named.
+const DefaultPattern = "{derived}"
+
+// Options describe a batch job. Pattern builds the name of every result
+// from these placeholders:
+//
+//	{name}    name of the input file without the extension
+//	{ops}     what the operations add to the name, e.g. "(Ecualization)(Gamma)"
+//	{ext}     extension of the output format
+//	{derived} {name}{ops}.{ext}
+type Options struct {
+	InputDir  string
+	OutputDir string
+	Pattern   string
+	Format    string                 // Output format, the one of every input file if empty
+	Save      processing.SaveOptions // Settings of the encoders
+	Raw       *processing.RawOptions // Of every input file, which are raw, when given
+	Overwrite bool                   // Replace existing files, see Run
+	Pipeline  pipeline.Pipeline
+	Progress  func(done, total int, file string) // Optional
+}
+
+// Failure is a file that could not be processed.
+type Failure struct {
+	File string
+	Err  error
+}
+
+func (failure Failure) Error() string {
+	return failure.File + ": " + failure.Err.Error()
+}
+
+// Report lists what happened to every file of the batch.
+type Report struct {
+	Written  []string
+	Failures []Failure
+}
+
+func (report Report) String() string {
+	message := fmt.Sprintf("%v images written, %v failed", len(report.Written), len(report.Failures))
+	for _, failure := range report.Failures {
+		message += "\n" + failure.Error()
+	}
+	return message
+}
+
+// Run applies the pipeline to every image of InputDir. Files that fail are
+// recorded in the report and the rest of the batch goes on; the returned
+// error is only for problems that stop the whole batch. Nothing is written
+// if a result would replace an input or, unless Overwrite, another result
+// or a file that already exists.
+func Run(options Options) (Report, error) {
+	var report Report
+	apply, err := options.Pipeline.Build()
+	if err != nil {
+		return report, err
+	}
+	if options.Pattern == "" {
+		options.Pattern = DefaultPattern
+	}
+	files, err := Images(options.InputDir)
+	if err != nil {
+		return report, err
+	}
+	outputs, err := outputsOf(options, files)
+	if err != nil {
+		return report, err
+	}
+	if err := os.MkdirAll(options.OutputDir, 0755); err != nil {
+		return report, err
+	}
+	for i, file := range files {
+		if options.Progress != nil {
+			options.Progress(i, len(files), file)
+		}
+		if err := processFile(options, apply, file, outputs[i]); err != nil {
+			report.Failures = append(report.Failures, Failure{File: file, Err: err})
+			continue
+		}
+		report.Written = append(report.Written, outputs[i])
+	}
+	if options.Progress != nil {
+		options.Progress(len(files), len(files), "")
+	}
+	return report, nil
+}
+
+// Images returns the files of dir that look like images, sorted by name.
+func Images(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		extension := strings.ToLower(filepath.Ext(entry.Name()))
+		for _, known := range processing.Extensions {
+			if extension == known {
+				files = append(files, filepath.Join(dir, entry.Name()))
+				break
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// outputsOf returns the result of every file, checking that they can be
+// written as Run says and in a format the encoders know.
+func outputsOf(options Options, files []string) ([]string, error) {
+	inputs := make(map[string]bool, len(files))
+	for _, file := range files {
+		inputs[absolute(file)] = true
+	}
+	outputs := make([]string, len(files))
+	from := make(map[string]string, len(files)) // Input of every output
+	for i, file := range files {
+		format := outputFormat(options, file)
+		if !processing.CanEncode(format) {
+			return nil, fmt.Errorf("%q can't be written, the format must be one of %v", format, strings.Join(processing.SaveFormats, ", "))
+		}
+		output := filepath.Join(options.OutputDir, OutputName(options.Pattern, filepath.Base(file), format, options.Pipeline))
+		key := absolute(output)
+		switch {
+		case inputs[key]:
+			return nil, fmt.Errorf("the result of %v would overwrite the input %v", file, output)
+		case options.Overwrite:
+		case from[key] != "":
+			return nil, fmt.Errorf("the results of %v and %v would both be %v, change the name or allow overwriting", from[key], file, output)
+		default:
+			if _, err := os.Stat(output); err == nil {
+				return nil, fmt.Errorf("%v already exists, allow overwriting to replace it", output)
+			}
+		}
+		from[key] = file
+		outputs[i] = output
+	}
+	return outputs, nil
+}
+
+func absolute(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// outputFormat is options.Format or else the format of file.
+func outputFormat(options Options, file string) string {
+	if options.Format != "" {
+		return options.Format
+	}
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(file)), ".")
+	if options.Raw != nil || !processing.CanEncode(format) {
+		return "png" // Raw and webp files can't be written back
+	}
+	return format
+}
+
+func processFile(options Options, apply pipeline.Func, file, output string) error {
+	img, _, err := open(file, options.Raw)
+	if err != nil {
+		return err
+	}
+	result, err := apply(img)
+	if err != nil {
+		return err
+	}
+	outputFile, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if err := processing.EncodeOptions(outputFile, result, outputFormat(options, file), options.Save); err != nil {
+		outputFile.Close()
+		os.Remove(output)
+		return err
+	}
+	return outputFile.Close()
+}
+
+// OutputName expands pattern for the input file called name.
+func OutputName(pattern, name, format string, p pipeline.Pipeline) string {
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	ops := strings.TrimSuffix(strings.TrimPrefix(p.Name(base+"."+format), base), "."+format)
+	ops = strings.ReplaceAll(ops, "/", "-") // e.g. (B/C)
+	return strings.NewReplacer(
+		"{derived}", base+ops+"."+format,
+		"{name}", base,
+		"{ops}", ops,
+		"{ext}", format,
+	).Replace(pattern)
+}
+
+// open reads file as raw pixels if raw is given, in its own format otherwise.
+func open(file string, raw *processing.RawOptions) (image.Image, string, error) {
+	if raw == nil {
+		return processing.Open(file)
+	}
+	img, err := processing.OpenRaw(file, *raw)
+	return img, processing.RawDescription(*raw), err
+}
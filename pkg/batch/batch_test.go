@@ -0,0 +1,91 @@
+package batch
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vision-go/vision-go/pkg/pipeline"
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// inputs writes a small image in dir under every name.
+func inputs(t *testing.T, dir string, names ...string) {
+	for _, name := range names {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		format := filepath.Ext(name)[1:]
+		if err := processing.Encode(f, image.NewGray(image.Rect(0, 0, 2, 2)), format); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+}
+
+func TestRunCollisions(t *testing.T) {
+	negative := pipeline.Pipeline{Steps: []pipeline.Step{{Operation: "negative"}}}
+	tests := []struct {
+		name     string
+		inputs   []string
+		existing []string // Already in the output folder
+		options  Options
+		ok       bool
+	}{
+		{"derived names", []string{"a.png", "b.png"}, nil, Options{}, true},
+		{"same name", []string{"a.png", "a.jpg"}, nil, Options{Format: "png"}, false},
+		{"same name overwriting", []string{"a.png", "a.jpg"}, nil, Options{Format: "png", Overwrite: true}, true},
+		{"existing", []string{"a.png"}, []string{"a(Negative).png"}, Options{}, false},
+		{"existing overwriting", []string{"a.png"}, []string{"a(Negative).png"}, Options{Overwrite: true}, true},
+		{"fixed name", []string{"a.png", "b.png"}, nil, Options{Pattern: "result.png"}, false},
+	}
+	for _, test := range tests {
+		in, out := t.TempDir(), t.TempDir()
+		inputs(t, in, test.inputs...)
+		inputs(t, out, test.existing...)
+		options := test.options
+		options.InputDir, options.OutputDir, options.Pipeline = in, out, negative
+		report, err := Run(options)
+		if test.ok && (err != nil || len(report.Failures) != 0) {
+			t.Errorf("%v: %v %v", test.name, err, report)
+		}
+		if !test.ok {
+			if err == nil {
+				t.Errorf("%v: no error", test.name)
+			}
+			if len(report.Written) != 0 {
+				t.Errorf("%v: %v written before failing", test.name, report.Written)
+			}
+		}
+	}
+}
+
+func TestRunKeepsInputs(t *testing.T) {
+	dir := t.TempDir()
+	inputs(t, dir, "a.png")
+	for _, overwrite := range []bool{false, true} {
+		_, err := Run(Options{InputDir: dir, OutputDir: dir, Pattern: "{name}.{ext}", Overwrite: overwrite,
+			Pipeline: pipeline.Pipeline{Steps: []pipeline.Step{{Operation: "negative"}}}})
+		if err == nil {
+			t.Errorf("overwrite=%v: an input is replaced", overwrite)
+		}
+	}
+}
+
+func TestRunUnknownFormat(t *testing.T) {
+	in, out := t.TempDir(), t.TempDir()
+	inputs(t, in, "a.png")
+	inputs(t, out, "a(Negative).png")
+	for _, format := range []string{"PNG", "xyz"} {
+		report, err := Run(Options{InputDir: in, OutputDir: out, Format: format, Pattern: "{name}(Negative).png", Overwrite: true,
+			Pipeline: pipeline.Pipeline{Steps: []pipeline.Step{{Operation: "negative"}}}})
+		if err == nil {
+			t.Errorf("%v: no error, %v", format, report)
+		}
+		if info, err := os.Stat(filepath.Join(out, "a(Negative).png")); err != nil || info.Size() == 0 {
+			t.Errorf("%v: the existing output is lost: %v", format, err)
+		}
+	}
+}
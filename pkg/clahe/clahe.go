@@ -0,0 +1,162 @@
+// Package clahe implements contrast-limited adaptive histogram equalization:
+// every tile of a grid is equalized with its own histogram, clipped so that
+// the noise of flat areas is not amplified, and the pixels interpolate the
+// mappings of the four nearest tiles so no seams appear.
+package clahe
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/vision-go/vision-go/pkg/colorspace"
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// Mode says which channels are equalized.
+type Mode int
+
+const (
+	RGB   Mode = iota // Every colour channel, which may shift the hues
+	YCbCr             // Only the luminance Y
+	Lab               // Only the lightness L
+)
+
+var modeNames = []string{"rgb", "ycbcr", "lab"}
+
+// Modes returns the names of the modes.
+func Modes() []string {
+	return append([]string(nil), modeNames...)
+}
+
+func ParseMode(name string) (Mode, error) {
+	for i, modeName := range modeNames {
+		if strings.EqualFold(name, modeName) {
+			return Mode(i), nil
+		}
+	}
+	return 0, fmt.Errorf("the mode must be one of %v", strings.Join(modeNames, ", "))
+}
+
+func (mode Mode) String() string {
+	return modeNames[mode]
+}
+
+// Validate checks the parameters of Equalize.
+func Validate(columns, rows int, clipLimit float64) error {
+	if columns < 1 || columns > 64 || rows < 1 || rows > 64 {
+		return fmt.Errorf("the grid must have from 1 to 64 columns and rows")
+	}
+	if clipLimit < 1 || clipLimit > 256 {
+		return fmt.Errorf("the clip limit must be in the range [1, 256]")
+	}
+	return nil
+}
+
+// Equalize applies CLAHE with a grid of columns x rows tiles. clipLimit is
+// how many times the average count of a level a bin may reach, from 1, the
+// mildest, to 256, the plain adaptive equalization.
+func Equalize(img image.Image, columns, rows int, clipLimit float64, mode Mode) (image.Image, error) {
+	if err := Validate(columns, rows, clipLimit); err != nil {
+		return nil, err
+	}
+	planes := processing.Planes(img)
+	if columns > planes[0].Width || rows > planes[0].Height {
+		return nil, fmt.Errorf("the grid of %vx%v tiles is finer than the image", columns, rows)
+	}
+	equalize := func(p *processing.Plane) *processing.Plane {
+		return equalizePlane(p, columns, rows, clipLimit)
+	}
+	switch mode {
+	case RGB:
+		for i := 0; i < 3; i++ {
+			planes[i] = equalize(planes[i])
+		}
+	case YCbCr:
+		y, cb, cr := colorspace.ToYCbCr(planes[0], planes[1], planes[2])
+		planes[0], planes[1], planes[2] = colorspace.FromYCbCr(equalize(y), cb, cr)
+	case Lab:
+		l, a, b := colorspace.ToLab(planes[0], planes[1], planes[2])
+		planes[0], planes[1], planes[2] = colorspace.FromLab(equalize(l), a, b)
+	}
+	return processing.FromPlanesLike(planes, img), nil
+}
+
+func equalizePlane(p *processing.Plane, columns, rows int, clipLimit float64) *processing.Plane {
+	w, h := p.Width, p.Height
+	// Tile i spans from bound(i) to bound(i+1)
+	boundX := func(i int) int { return i * w / columns }
+	boundY := func(j int) int { return j * h / rows }
+	luts := make([][256]float32, columns*rows)
+	for j := 0; j < rows; j++ {
+		for i := 0; i < columns; i++ {
+			var hist [256]int
+			for y := boundY(j); y < boundY(j+1); y++ {
+				for _, value := range p.Pix[y*w+boundX(i) : y*w+boundX(i+1)] {
+					hist[processing.Clamp(value)]++
+				}
+			}
+			luts[j*columns+i] = mapping(&hist, (boundX(i+1)-boundX(i))*(boundY(j+1)-boundY(j)), clipLimit)
+		}
+	}
+	// Every pixel interpolates the mappings of the tiles whose centres
+	// surround it, the borders just the nearest ones.
+	type neighbours struct {
+		low, high int
+		weight    float32 // Of high
+	}
+	locate := func(position, tiles, size int) neighbours {
+		center := (float32(position)+0.5)*float32(tiles)/float32(size) - 0.5
+		if center <= 0 {
+			return neighbours{0, 0, 0}
+		}
+		if center >= float32(tiles-1) {
+			return neighbours{tiles - 1, tiles - 1, 0}
+		}
+		low := int(center)
+		return neighbours{low, low + 1, center - float32(low)}
+	}
+	horizontal := make([]neighbours, w)
+	for x := range horizontal {
+		horizontal[x] = locate(x, columns, w)
+	}
+	result := processing.NewPlane(w, h)
+	processing.ParallelRows(h, func(y int) {
+		v := locate(y, rows, h)
+		for x, value := range p.Pix[y*w : (y+1)*w] {
+			level, u := processing.Clamp(value), horizontal[x]
+			top := luts[v.low*columns+u.low][level]*(1-u.weight) + luts[v.low*columns+u.high][level]*u.weight
+			bottom := luts[v.high*columns+u.low][level]*(1-u.weight) + luts[v.high*columns+u.high][level]*u.weight
+			result.Pix[y*w+x] = top*(1-v.weight) + bottom*v.weight
+		}
+	})
+	return result
+}
+
+// mapping clips hist, spreads the excess over every level and returns its
+// accumulative histogram scaled to 0-255.
+func mapping(hist *[256]int, size int, clipLimit float64) (lut [256]float32) {
+	limit := int(clipLimit * float64(size) / 256)
+	if limit < 1 {
+		limit = 1
+	}
+	excess := 0
+	for i, count := range hist {
+		if count > limit {
+			excess += count - limit
+			hist[i] = limit
+		}
+	}
+	for i := range hist {
+		hist[i] += excess / 256
+	}
+	for i := 0; i < excess%256; i++ { // The rest evenly spaced
+		hist[i*256/(excess%256)]++
+	}
+	accumulated := 0
+	for i, count := range hist {
+		accumulated += count
+		lut[i] = float32(accumulated) * 255 / float32(size)
+	}
+	return lut
+}
@@ -0,0 +1,120 @@
+package clahe
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func flat(level uint8) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 32, 32))
+	for i := range img.Pix {
+		img.Pix[i] = level
+	}
+	return img
+}
+
+func greyAt(img image.Image, x, y int) uint8 {
+	return color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y
+}
+
+func TestMapping(t *testing.T) {
+	tests := []struct {
+		name      string
+		clipLimit float64
+		want      float32 // Mapping of 100, the only level of the tile
+	}{
+		{"clipped", 1, 100}, // The excess spread evenly leaves about the identity
+		{"unclipped", 256, 255},
+	}
+	for _, test := range tests {
+		var hist [256]int
+		hist[100] = 256
+		lut := mapping(&hist, 256, test.clipLimit)
+		if math.Abs(float64(lut[100]-test.want)) > 2 {
+			t.Errorf("%v: 100 is mapped to %v, want %v", test.name, lut[100], test.want)
+		}
+		if lut[255] != 255 {
+			t.Errorf("%v: 255 is mapped to %v", test.name, lut[255])
+		}
+		for i := 1; i < 256; i++ {
+			if lut[i] < lut[i-1] {
+				t.Errorf("%v: the mapping decreases at %v", test.name, i)
+				break
+			}
+		}
+	}
+}
+
+// TestFlat checks that the clip limit keeps the noise of flat areas, here
+// of a flat image, from being amplified.
+func TestFlat(t *testing.T) {
+	result, err := Equalize(flat(100), 2, 2, 1, RGB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			if got := greyAt(result, x, y); got < 99 || got > 103 {
+				t.Fatalf("pixel %v,%v of a flat image becomes %v", x, y, got)
+			}
+		}
+	}
+}
+
+// TestGlobal checks that a single unclipped tile is the plain histogram
+// equalization.
+func TestGlobal(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 4, 4))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(10 * (i % 4)) // Four levels with the same count
+	}
+	result, err := Equalize(img, 1, 1, 256, RGB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for x, want := range []int{64, 128, 191, 255} {
+		if got := int(greyAt(result, x, 0)); got < want-1 || got > want+1 {
+			t.Errorf("level %v becomes %v, want %v", img.Pix[x], got, want)
+		}
+	}
+}
+
+func TestContrast(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			level := uint8(100 + x)
+			img.SetNRGBA(x, y, color.NRGBA{level, level, level, 255})
+		}
+	}
+	for _, mode := range []Mode{RGB, YCbCr, Lab} {
+		result, err := Equalize(img, 2, 2, 4, mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		low, high := greyAt(result, 0, 8), greyAt(result, 15, 8)
+		if int(high)-int(low) <= 15 {
+			t.Errorf("%v: the levels go from %v to %v, the contrast doesn't grow", mode, low, high)
+		}
+		r, g, b, _ := result.At(7, 8).RGBA()
+		if math.Abs(float64(r)-float64(g)) > 0x200 || math.Abs(float64(g)-float64(b)) > 0x200 {
+			t.Errorf("%v: a grey image gets colour %v, %v, %v", mode, r>>8, g>>8, b>>8)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := Equalize(flat(0), 33, 2, 2, RGB); err == nil {
+		t.Error("no error with more tiles than columns")
+	}
+	for _, test := range []struct {
+		columns, rows int
+		clipLimit     float64
+	}{{0, 8, 2}, {8, 65, 2}, {8, 8, 0.5}, {8, 8, 300}} {
+		if err := Validate(test.columns, test.rows, test.clipLimit); err == nil {
+			t.Errorf("%+v: no error", test)
+		}
+	}
+}
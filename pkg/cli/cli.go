@@ -0,0 +1,494 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/vision-go/vision-go/pkg/batch"
+	"github.com/vision-go/vision-go/pkg/histogram"
+	"github.com/vision-go/vision-go/pkg/pipeline"
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// CLI runs vision-go without the graphical interface. Files named "-" are
+// read from Stdin or written to Stdout so commands can be chained in pipes.
+type CLI struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	raw    *processing.RawOptions // Of the input files, set with --raw
+}
+
+// New returns a CLI attached to the standard input and outputs.
+func New() *CLI {
+	return &CLI{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr}
+}
+
+// Run executes one command. args doesn't include the program name.
+func (cli *CLI) Run(args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		cli.usage()
+		return nil
+	}
+	command, args := args[0], args[1:]
+	var err error
+	switch command {
+	case "info":
+		err = cli.info(args)
+	case "histogram":
+		err = cli.histogram(args)
+	case "batch":
+		err = cli.batch(args)
+	case "run":
+		err = cli.run(args)
+	default:
+		if _, lookupErr := pipeline.Lookup(command); lookupErr != nil {
+			cli.usage()
+			return fmt.Errorf("unknown command %q", command)
+		}
+		err = cli.operation(command, args)
+	}
+	if err == flag.ErrHelp {
+		return nil
+	}
+	return err
+}
+
+func (cli *CLI) usage() {
+	fmt.Fprintln(cli.Stderr, "Usage: vision-go <command> [flags] <input> [output]")
+	fmt.Fprintln(cli.Stderr, "Without arguments the graphical interface is opened.")
+	fmt.Fprintln(cli.Stderr, "\nCommands:")
+	fmt.Fprintf(cli.Stderr, "  %-20v %v\n", "info", "print format, size, depth, range, brightness, contrast (also per channel) and entropy")
+	fmt.Fprintf(cli.Stderr, "  %-20v %v\n", "histogram", "print a histogram as <level> <value> lines")
+	fmt.Fprintf(cli.Stderr, "  %-20v %v\n", "batch", "apply a chain of operations to every image of a folder")
+	fmt.Fprintf(cli.Stderr, "  %-20v %v\n", "run", "apply a pipeline file (yaml or json) to an image")
+	for _, op := range pipeline.Operations() {
+		fmt.Fprintf(cli.Stderr, "  %-20v %v\n", op.Name, op.Usage)
+	}
+	fmt.Fprintln(cli.Stderr, "\nRun vision-go <command> -h to see its flags.")
+}
+
+// luminanceFlag adds the flag of the weights of the grey levels to the
+// commands computing them outside of an operation, which has its own.
+func luminanceFlag(flags *flag.FlagSet) *processing.Luminance {
+	l := processing.PAL
+	flags.Func("luminance", "weights of the grey levels: "+strings.Join(processing.Luminances(), ", ")+" or r,g,b (default pal)", func(text string) error {
+		var err error
+		l, err = processing.ParseLuminance(text)
+		return err
+	})
+	return &l
+}
+
+func (cli *CLI) newFlagSet(name, arguments string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(cli.Stderr)
+	flags.Func("raw", "read the input as raw pixels: width=W,height=H[,depth=8|16][,order=little|big][,channels=1|3|4][,offset=bytes]", func(text string) error {
+		o, err := processing.ParseRawOptions(text)
+		if err != nil {
+			return err
+		}
+		cli.raw = &o
+		return nil
+	})
+	flags.Usage = func() {
+		fmt.Fprintf(cli.Stderr, "Usage: vision-go %v [flags] %v\n", name, arguments)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// saveFlags are the settings of the encoders of the commands writing images.
+type saveFlags struct {
+	quality     *int
+	compression *string
+	plain       *bool
+}
+
+func addSaveFlags(flags *flag.FlagSet) saveFlags {
+	return saveFlags{
+		quality: flags.Int("quality", 0, fmt.Sprintf("quality of jpg files, 1-100 (default %v)", jpeg.DefaultQuality)),
+		compression: flags.String("compression", "", "compression of png ("+strings.Join(processing.PNGCompressions(), ", ")+
+			") or tif ("+strings.Join(processing.TIFFCompressions(), ", ")+") files"),
+		plain: flags.Bool("plain", false, "write pgm, ppm and pnm files as ASCII"),
+	}
+}
+
+// options returns the settings given in the flags over those of base, the
+// ones of a pipeline file.
+func (s saveFlags) options(flags *flag.FlagSet, base processing.SaveOptions) (processing.SaveOptions, error) {
+	var err error
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "quality":
+			base.JPEGQuality = *s.quality
+		case "compression":
+			err = base.SetCompression(*s.compression)
+		case "plain":
+			base.PNMPlain = *s.plain
+		}
+	})
+	if err != nil {
+		return base, err
+	}
+	return base, base.Validate()
+}
+
+func (cli *CLI) operation(name string, args []string) error {
+	op, _ := pipeline.Lookup(name) // Already checked
+	flags := cli.newFlagSet(name, "<input> <output>")
+	values := make(map[string]*string, len(op.Params))
+	for _, param := range op.Params {
+		values[param.Name] = flags.String(param.Name, param.Default, param.Usage)
+	}
+	format := flags.String("format", "", "output format ("+strings.Join(processing.SaveFormats, ", ")+"), by default taken from the output extension")
+	save := addSaveFlags(flags)
+	flags.String("region", "", "only apply the operation to x,y,width,height")
+	flags.String("region-shape", "", "shape of the region: rectangle, ellipse, polygon or wand")
+	flags.String("region-points", "", "vertices of a polygon region or seed of a wand, as x:y,x:y")
+	flags.String("region-tolerance", "", "colour tolerance of a wand region (0-255)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return fmt.Errorf("%v needs an input and an output file", name)
+	}
+	step := pipeline.Step{Operation: name, Params: map[string]interface{}{}}
+	var regionErr error
+	flags.Visit(func(f *flag.Flag) {
+		if value, ok := values[f.Name]; ok {
+			step.Params[f.Name] = *value
+		}
+		if strings.HasPrefix(f.Name, "region") {
+			if step.Region == nil {
+				step.Region = &pipeline.Region{}
+			}
+			if err := step.Region.Set(f.Name, f.Value.String()); err != nil {
+				regionErr = err
+			}
+		}
+	})
+	if regionErr != nil {
+		return regionErr
+	}
+	options, err := save.options(flags, processing.SaveOptions{})
+	if err != nil {
+		return err
+	}
+	apply, err := pipeline.Build(step)
+	if err != nil {
+		return err
+	}
+	img, _, err := cli.read(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	result, err := apply(img)
+	if err != nil {
+		return err
+	}
+	return cli.write(flags.Arg(1), *format, options, result)
+}
+
+func (cli *CLI) info(args []string) error {
+	flags := cli.newFlagSet("info", "<input>")
+	l := luminanceFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("info needs an input file")
+	}
+	img, format, err := cli.read(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	stats := processing.NewStatistics(img, *l)
+	size := img.Bounds().Size()
+	fmt.Fprintf(cli.Stdout, "Format: %v\n", format)
+	fmt.Fprintf(cli.Stdout, "Size: %v (%v x %v)\n", humanize.Bytes(uint64(size.X*size.Y)), size.X, size.Y)
+	fmt.Fprintf(cli.Stdout, "Depth: %v\n", processing.DepthOf(img).Description())
+	fmt.Fprintf(cli.Stdout, "Range: [%v, %v]\n", stats.MinColor, stats.MaxColor)
+	fmt.Fprintf(cli.Stdout, "Brightness: %f\n", stats.Brightness)
+	fmt.Fprintf(cli.Stdout, "Contrast: %f\n", stats.Contrast)
+	fmt.Fprintf(cli.Stdout, "Brightness (R, G, B): %f, %f, %f\n", stats.ChannelBrightness[0], stats.ChannelBrightness[1], stats.ChannelBrightness[2])
+	fmt.Fprintf(cli.Stdout, "Contrast (R, G, B): %f, %f, %f\n", stats.ChannelContrast[0], stats.ChannelContrast[1], stats.ChannelContrast[2])
+	fmt.Fprintf(cli.Stdout, "Luminance: %v (R %.4g, G %.4g, B %.4g)\n", *l, l.R, l.G, l.B)
+	fmt.Fprintf(cli.Stdout, "Entropy: %f with %v diferent colors\n", stats.Entropy, stats.NumberOfColors)
+	return nil
+}
+
+func (cli *CLI) histogram(args []string) error {
+	flags := cli.newFlagSet("histogram", "<input>")
+	channel := flags.String("channel", "grey", "channel: grey, r, g or b")
+	kind := flags.String("kind", "absolute", "histogram: absolute, accumulative or normalized")
+	bins := flags.Int("bins", 256, fmt.Sprintf("number of bins [2, %v] spread over the levels, more than 256 for 16-bit and float images", processing.MaxBins))
+	l := luminanceFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("histogram needs an input file")
+	}
+	index := map[string]int{"grey": 0, "gray": 0, "r": 1, "g": 2, "b": 3}
+	channelIndex, ok := index[strings.ToLower(*channel)]
+	if !ok {
+		return fmt.Errorf("channel must be grey, r, g or b")
+	}
+	img, _, err := cli.read(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *bins != 256 {
+		return cli.binnedHistogram(img, *bins, channelIndex, *kind, *l)
+	}
+	hist := processing.NewHistograms(img, *l)
+	switch *kind {
+	case "absolute":
+		values := [4]histogram.Histogram{hist.Histogram, hist.HistogramR, hist.HistogramG, hist.HistogramB}[channelIndex]
+		for level, count := range values {
+			fmt.Fprintf(cli.Stdout, "%v %v\n", level, count)
+		}
+	case "accumulative":
+		values := [4]histogram.Histogram{hist.HistogramAccumulative, hist.HistogramAccumulativeR, hist.HistogramAccumulativeG, hist.HistogramAccumulativeB}[channelIndex]
+		for level, count := range values {
+			fmt.Fprintf(cli.Stdout, "%v %v\n", level, count)
+		}
+	case "normalized":
+		values := [4]histogram.HistogramNormalized{hist.HistogramNormalized, hist.HistogramNormalizedR, hist.HistogramNormalizedG, hist.HistogramNormalizedB}[channelIndex]
+		for level, probability := range values {
+			fmt.Fprintf(cli.Stdout, "%v %v\n", level, probability)
+		}
+	default:
+		return fmt.Errorf("kind must be absolute, accumulative or normalized")
+	}
+	return nil
+}
+
+// binnedHistogram prints a histogram of bins bins, with the level of every
+// bin in [0, 255].
+func (cli *CLI) binnedHistogram(img image.Image, bins, channelIndex int, kind string, l processing.Luminance) error {
+	hist, err := processing.NewBinnedHistograms(img, bins, l)
+	if err != nil {
+		return err
+	}
+	values := [4]histogram.Bins{hist.Histogram, hist.HistogramR, hist.HistogramG, hist.HistogramB}[channelIndex]
+	switch kind {
+	case "absolute":
+		for bin, count := range values {
+			fmt.Fprintf(cli.Stdout, "%g %v\n", values.Level(bin), count)
+		}
+	case "accumulative":
+		for bin, count := range values.Accumulative() {
+			fmt.Fprintf(cli.Stdout, "%g %v\n", values.Level(bin), count)
+		}
+	case "normalized":
+		size := img.Bounds().Dx() * img.Bounds().Dy()
+		for bin, probability := range values.Normalized(size) {
+			fmt.Fprintf(cli.Stdout, "%g %v\n", values.Level(bin), probability)
+		}
+	default:
+		return fmt.Errorf("kind must be absolute, accumulative or normalized")
+	}
+	return nil
+}
+
+// stepList collects repeated --op flags.
+type stepList []pipeline.Step
+
+func (steps *stepList) String() string {
+	names := make([]string, len(*steps))
+	for i, step := range *steps {
+		names[i] = step.Operation
+	}
+	return strings.Join(names, ", ")
+}
+
+func (steps *stepList) Set(spec string) error {
+	step, err := pipeline.ParseStep(spec)
+	if err != nil {
+		return err
+	}
+	*steps = append(*steps, step)
+	return nil
+}
+
+func (cli *CLI) batch(args []string) error {
+	flags := cli.newFlagSet("batch", "<input folder> <output folder>")
+	var steps stepList
+	flags.Var(&steps, "op", `operation of the chain as "name key=value ...", may be repeated`)
+	pipelineFile := flags.String("pipeline", "", "pipeline file (yaml or json) to use instead of --op")
+	pattern := flags.String("pattern", "", "name of the results using {name}, {ops}, {ext} and {derived} (default "+batch.DefaultPattern+")")
+	format := flags.String("format", "", "output format ("+strings.Join(processing.SaveFormats, ", ")+"), by default the one of every input")
+	overwrite := flags.Bool("overwrite", false, "replace existing files and let results with the same name replace each other")
+	save := addSaveFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return fmt.Errorf("batch needs an input and an output folder")
+	}
+	p := pipeline.Pipeline{Steps: steps}
+	var base processing.SaveOptions
+	if *pipelineFile != "" {
+		if len(steps) != 0 {
+			return fmt.Errorf("--op and --pipeline can't be used together")
+		}
+		var err error
+		if p, err = pipeline.Load(*pipelineFile); err != nil {
+			return err
+		}
+		if p.Save != nil {
+			if *pattern == "" {
+				*pattern = p.Save.Pattern
+			}
+			if *format == "" {
+				*format = p.Save.Format
+			}
+			base, _ = p.Save.Options() // Checked by Load
+		}
+	}
+	options, err := save.options(flags, base)
+	if err != nil {
+		return err
+	}
+	report, err := batch.Run(batch.Options{
+		InputDir:  flags.Arg(0),
+		OutputDir: flags.Arg(1),
+		Pattern:   *pattern,
+		Format:    strings.ToLower(*format),
+		Save:      options,
+		Raw:       cli.raw,
+		Overwrite: *overwrite,
+		Pipeline:  p,
+		Progress: func(done, total int, file string) {
+			if file != "" {
+				fmt.Fprintf(cli.Stderr, "[%v/%v] %v\n", done+1, total, file)
+			}
+		},
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cli.Stdout, report)
+	if len(report.Failures) != 0 {
+		return fmt.Errorf("%v of %v images failed", len(report.Failures), len(report.Failures)+len(report.Written))
+	}
+	return nil
+}
+
+func (cli *CLI) run(args []string) error {
+	flags := cli.newFlagSet("run", "<input> [output]")
+	pipelineFile := flags.String("pipeline", "", "pipeline file (yaml or json)")
+	format := flags.String("format", "", "output format, by default the one of the pipeline or of the output extension")
+	save := addSaveFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *pipelineFile == "" || flags.NArg() < 1 || flags.NArg() > 2 {
+		flags.Usage()
+		return fmt.Errorf("run needs a pipeline and an input file")
+	}
+	p, err := pipeline.Load(*pipelineFile)
+	if err != nil {
+		return err
+	}
+	apply, err := p.Build()
+	if err != nil {
+		return err
+	}
+	output := flags.Arg(1)
+	var base processing.SaveOptions
+	if p.Save != nil {
+		if *format == "" {
+			*format = p.Save.Format
+		}
+		base, _ = p.Save.Options() // Checked by Load
+	}
+	options, err := save.options(flags, base)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		if p.Save == nil || flags.Arg(0) == "-" {
+			return fmt.Errorf("give an output file or a save section in the pipeline")
+		}
+		pattern := p.Save.Pattern
+		if pattern == "" {
+			pattern = batch.DefaultPattern
+		}
+		output = filepath.Join(filepath.Dir(flags.Arg(0)), batch.OutputName(pattern, filepath.Base(flags.Arg(0)), *format, p))
+	}
+	img, _, err := cli.read(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	result, err := apply(img)
+	if err != nil {
+		return err
+	}
+	return cli.write(output, *format, options, result)
+}
+
+func (cli *CLI) read(path string) (image.Image, string, error) {
+	if cli.raw != nil {
+		r := cli.Stdin
+		if path != "-" {
+			f, err := os.Open(path)
+			if err != nil {
+				return nil, "", err
+			}
+			defer f.Close()
+			r = f
+		}
+		img, err := processing.DecodeRaw(r, *cli.raw)
+		return img, processing.RawDescription(*cli.raw), err
+	}
+	if path != "-" {
+		return processing.Open(path)
+	}
+	data, err := io.ReadAll(cli.Stdin)
+	if err != nil {
+		return nil, "", err
+	}
+	return processing.Decode(bytes.NewReader(data), "") // Raw pixels need --raw
+}
+
+func (cli *CLI) write(path, format string, options processing.SaveOptions, img image.Image) error {
+	if format == "" {
+		format = filepath.Ext(path)
+		if path == "-" {
+			format = "png"
+		}
+	}
+	format = strings.TrimPrefix(strings.ToLower(format), ".")
+	if !processing.CanEncode(format) { // Before creating the file, which would replace an existing one
+		return fmt.Errorf("%q can't be written, the format must be one of %v", format, strings.Join(processing.SaveFormats, ", "))
+	}
+	if path == "-" {
+		return processing.EncodeOptions(cli.Stdout, img, format, options)
+	}
+	outputFile, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := processing.EncodeOptions(outputFile, img, format, options); err != nil {
+		outputFile.Close()
+		os.Remove(path)
+		return err
+	}
+	return outputFile.Close()
+}
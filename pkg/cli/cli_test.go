@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testCLI returns a CLI that reads input and writes to a buffer.
+func testCLI(input []byte) (*CLI, *bytes.Buffer) {
+	stdout := new(bytes.Buffer)
+	return &CLI{Stdin: bytes.NewReader(input), Stdout: stdout, Stderr: ioutil.Discard}, stdout
+}
+
+// encoded returns a png of 4x2 grey levels.
+func encoded(t *testing.T) []byte {
+	img := image.NewGray(image.Rect(0, 0, 4, 2))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(30 * i)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// checkNegative decodes data and checks that it is the negative of the
+// image of encoded.
+func checkNegative(t *testing.T, name string, data []byte) {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Errorf("%v: %v", name, err)
+		return
+	}
+	for i := 0; i < 8; i++ {
+		got := color.GrayModel.Convert(img.At(i%4, i/4)).(color.Gray).Y
+		if want := uint8(255 - 30*i); got != want {
+			t.Errorf("%v: pixel %v is %v, want %v", name, i, got, want)
+		}
+	}
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOperationPipe(t *testing.T) {
+	cli, stdout := testCLI(encoded(t))
+	if err := cli.Run([]string{"negative", "-", "-"}); err != nil {
+		t.Fatal(err)
+	}
+	checkNegative(t, "stdout", stdout.Bytes())
+}
+
+func TestOperationFiles(t *testing.T) {
+	dir := t.TempDir()
+	input, output := filepath.Join(dir, "a.png"), filepath.Join(dir, "b.png")
+	writeFile(t, input, encoded(t))
+	cli, _ := testCLI(nil)
+	if err := cli.Run([]string{"negative", input, output}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNegative(t, output, data)
+	if err := cli.Run([]string{"negative", "--format", "PNG", input, output}); err != nil {
+		t.Errorf("upper case format: %v", err)
+	}
+}
+
+// TestBadFormat checks that an unknown format is refused before the output
+// file is touched.
+func TestBadFormat(t *testing.T) {
+	dir := t.TempDir()
+	input, output := filepath.Join(dir, "a.png"), filepath.Join(dir, "b.png")
+	writeFile(t, input, encoded(t))
+	writeFile(t, output, []byte("keep"))
+	pipelineFile := filepath.Join(dir, "p.yaml")
+	writeFile(t, pipelineFile, []byte("steps: [{operation: negative}]"))
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"operation", []string{"negative", "--format", "xyz", input, output}},
+		{"extension", []string{"negative", input, filepath.Join(dir, "b.xyz")}},
+		{"stdout", []string{"negative", "--format", "xyz", input, "-"}},
+		{"run", []string{"run", "--pipeline", pipelineFile, "--format", "xyz", input, output}},
+		{"batch", []string{"batch", "--op", "negative", "--format", "xyz", "--overwrite", dir, dir}},
+	}
+	for _, test := range tests {
+		cli, stdout := testCLI(nil)
+		if err := cli.Run(test.args); err == nil {
+			t.Errorf("%v: no error", test.name)
+		}
+		if stdout.Len() != 0 && test.name != "batch" {
+			t.Errorf("%v: %v bytes written to stdout", test.name, stdout.Len())
+		}
+		if data, err := ioutil.ReadFile(output); err != nil || string(data) != "keep" {
+			t.Errorf("%v: the existing output is lost", test.name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b.xyz")); err == nil {
+		t.Error("the output with an unknown extension is created")
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	input, pipelineFile := filepath.Join(dir, "a.png"), filepath.Join(dir, "p.yaml")
+	writeFile(t, input, encoded(t))
+	writeFile(t, pipelineFile, []byte("steps: [{operation: negative}]\nsave: {format: png, pattern: \"{name}-out.{ext}\"}"))
+	cli, stdout := testCLI(encoded(t))
+	if err := cli.Run([]string{"run", "--pipeline", pipelineFile, "-", "-"}); err != nil {
+		t.Fatal(err)
+	}
+	checkNegative(t, "stdout", stdout.Bytes())
+	if err := cli.Run([]string{"run", "--pipeline", pipelineFile, input}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "a-out.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkNegative(t, "save section", data)
+	if err := cli.Run([]string{"run", "--pipeline", pipelineFile, "-"}); err == nil {
+		t.Error("no error reading stdin without an output")
+	}
+}
+
+func TestBatch(t *testing.T) {
+	input, output := t.TempDir(), t.TempDir()
+	for _, name := range []string{"a.png", "b.png"} {
+		writeFile(t, filepath.Join(input, name), encoded(t))
+	}
+	cli, stdout := testCLI(nil)
+	if err := cli.Run([]string{"batch", "--op", "negative", "--pattern", "{name}.{ext}", input, output}); err != nil {
+		t.Fatal(err)
+	}
+	if stdout.Len() == 0 {
+		t.Error("no report")
+	}
+	for _, name := range []string{"a.png", "b.png"} {
+		data, err := ioutil.ReadFile(filepath.Join(output, name))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		checkNegative(t, name, data)
+	}
+	if err := cli.Run([]string{"batch", "--op", "negative", "--pattern", "{name}.{ext}", input, output}); err == nil {
+		t.Error("no error replacing the results without --overwrite")
+	}
+}
@@ -0,0 +1,306 @@
+// Package colorspace converts the red, green and blue planes of an image to
+// other colour spaces and back. The channels are scaled to 0-255, like the
+// 8-bit ones, so the usual operations can work on them.
+package colorspace
+
+import (
+	"math"
+
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// ToYCbCr converts with the full range JFIF equations of image/color.
+func ToYCbCr(r, g, b *processing.Plane) (y, cb, cr *processing.Plane) {
+	y, cb, cr = newPlanes(r)
+	for i := range y.Pix {
+		R, G, B := r.Pix[i], g.Pix[i], b.Pix[i]
+		y.Pix[i] = 0.299*R + 0.587*G + 0.114*B
+		cb.Pix[i] = -0.168736*R - 0.331264*G + 0.5*B + 128
+		cr.Pix[i] = 0.5*R - 0.418688*G - 0.081312*B + 128
+	}
+	return y, cb, cr
+}
+
+func FromYCbCr(y, cb, cr *processing.Plane) (r, g, b *processing.Plane) {
+	r, g, b = newPlanes(y)
+	for i := range y.Pix {
+		Y, Cb, Cr := y.Pix[i], cb.Pix[i]-128, cr.Pix[i]-128
+		r.Pix[i] = Y + 1.402*Cr
+		g.Pix[i] = Y - 0.344136*Cb - 0.714136*Cr
+		b.Pix[i] = Y + 1.772*Cb
+	}
+	return r, g, b
+}
+
+// D65 white of the sRGB primaries.
+const (
+	whiteX = 0.95047
+	whiteY = 1.0
+	whiteZ = 1.08883
+)
+
+// ToLab converts from sRGB to CIE L*a*b* with the D65 white. L (0-100) is
+// scaled to 0-255 and a and b are offset by 128, as OpenCV does for 8-bit
+// images.
+func ToLab(r, g, b *processing.Plane) (l, a, bb *processing.Plane) {
+	l, a, bb = newPlanes(r)
+	processing.ParallelRows(r.Height, func(row int) {
+		for i := row * r.Width; i < (row+1)*r.Width; i++ {
+			x, y, z := toXYZ(r.Pix[i], g.Pix[i], b.Pix[i])
+			fx, fy, fz := labF(x/whiteX), labF(y/whiteY), labF(z/whiteZ)
+			l.Pix[i] = float32((116*fy - 16) * 255 / 100)
+			a.Pix[i] = float32(500*(fx-fy) + 128)
+			bb.Pix[i] = float32(200*(fy-fz) + 128)
+		}
+	})
+	return l, a, bb
+}
+
+func FromLab(l, a, bb *processing.Plane) (r, g, b *processing.Plane) {
+	r, g, b = newPlanes(l)
+	processing.ParallelRows(l.Height, func(row int) {
+		for i := row * l.Width; i < (row+1)*l.Width; i++ {
+			fy := (float64(l.Pix[i])*100/255 + 16) / 116
+			fx := fy + (float64(a.Pix[i])-128)/500
+			fz := fy - (float64(bb.Pix[i])-128)/200
+			r.Pix[i], g.Pix[i], b.Pix[i] = fromXYZ(whiteX*labInverse(fx), whiteY*labInverse(fy), whiteZ*labInverse(fz))
+		}
+	})
+	return r, g, b
+}
+
+// toXYZ converts a gamma encoded sRGB colour (0-255) to CIE XYZ.
+func toXYZ(r, g, b float32) (x, y, z float64) {
+	R, G, B := linear(r), linear(g), linear(b)
+	return 0.4124564*R + 0.3575761*G + 0.1804375*B,
+		0.2126729*R + 0.7151522*G + 0.0721750*B,
+		0.0193339*R + 0.1191920*G + 0.9503041*B
+}
+
+func fromXYZ(x, y, z float64) (r, g, b float32) {
+	return encoded(3.2404542*x - 1.5371385*y - 0.4985314*z),
+		encoded(-0.9692660*x + 1.8760108*y + 0.0415560*z),
+		encoded(0.0556434*x - 0.2040259*y + 1.0572252*z)
+}
+
+// linearTable and encodedTable tabulate the sRGB gamma, the samples being
+// whole numbers almost always and the encoding being interpolated.
+var linearTable, encodedTable = func() (linear [256]float64, encoded [encodedSteps + 1]float32) {
+	for i := range linear {
+		linear[i] = linearExact(float64(i) / 255)
+	}
+	for i := range encoded {
+		encoded[i] = encodedExact(float64(i) / encodedSteps)
+	}
+	return linear, encoded
+}()
+
+const encodedSteps = 4096
+
+// linear removes the sRGB gamma of an 8-bit sample, giving 0-1.
+func linear(value float32) float64 {
+	if i := int(value); float32(i) == value && i >= 0 && i < 256 {
+		return linearTable[i]
+	}
+	return linearExact(float64(value) / 255)
+}
+
+func linearExact(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// encoded applies the sRGB gamma to v (0-1), giving 0-255.
+func encoded(v float64) float32 {
+	if v < 0 || v >= 1 {
+		return encodedExact(v)
+	}
+	position := v * encodedSteps
+	i := int(position)
+	weight := float32(position - float64(i))
+	return encodedTable[i]*(1-weight) + encodedTable[i+1]*weight
+}
+
+func encodedExact(v float64) float32 {
+	if v <= 0.0031308 {
+		return float32(v * 12.92 * 255)
+	}
+	return float32((1.055*math.Pow(v, 1/2.4) - 0.055) * 255)
+}
+
+func labF(t float64) float64 {
+	if t > 216.0/24389 {
+		return math.Cbrt(t)
+	}
+	return (24389.0/27*t + 16) / 116
+}
+
+func labInverse(f float64) float64 {
+	if f*f*f > 216.0/24389 {
+		return f * f * f
+	}
+	return (116*f - 16) * 27 / 24389
+}
+
+func newPlanes(like *processing.Plane) (a, b, c *processing.Plane) {
+	return processing.NewPlane(like.Width, like.Height), processing.NewPlane(like.Width, like.Height), processing.NewPlane(like.Width, like.Height)
+}
+
+// ToHSV converts to hue, saturation and value. The hue, 0-360 degrees, is
+// scaled to 0-255 like the other channels.
+func ToHSV(r, g, b *processing.Plane) (h, s, v *processing.Plane) {
+	h, s, v = newPlanes(r)
+	for i := range h.Pix {
+		max, min := extremes(r.Pix[i], g.Pix[i], b.Pix[i])
+		h.Pix[i] = hue(r.Pix[i], g.Pix[i], b.Pix[i], max, max-min)
+		if max > 0 {
+			s.Pix[i] = (max - min) / max * 255
+		}
+		v.Pix[i] = max
+	}
+	return h, s, v
+}
+
+func FromHSV(h, s, v *processing.Plane) (r, g, b *processing.Plane) {
+	r, g, b = newPlanes(h)
+	for i := range h.Pix {
+		chroma := v.Pix[i] * s.Pix[i] / 255
+		r.Pix[i], g.Pix[i], b.Pix[i] = fromHue(h.Pix[i], chroma, v.Pix[i]-chroma)
+	}
+	return r, g, b
+}
+
+// ToHSL converts to hue, saturation and lightness, scaled as in ToHSV.
+func ToHSL(r, g, b *processing.Plane) (h, s, l *processing.Plane) {
+	h, s, l = newPlanes(r)
+	for i := range h.Pix {
+		max, min := extremes(r.Pix[i], g.Pix[i], b.Pix[i])
+		h.Pix[i] = hue(r.Pix[i], g.Pix[i], b.Pix[i], max, max-min)
+		l.Pix[i] = (max + min) / 2
+		if spread := 255 - float32(math.Abs(float64(max+min-255))); spread > 0 {
+			s.Pix[i] = (max - min) / spread * 255
+		}
+	}
+	return h, s, l
+}
+
+func FromHSL(h, s, l *processing.Plane) (r, g, b *processing.Plane) {
+	r, g, b = newPlanes(h)
+	for i := range h.Pix {
+		chroma := (255 - float32(math.Abs(float64(2*l.Pix[i]-255)))) * s.Pix[i] / 255
+		r.Pix[i], g.Pix[i], b.Pix[i] = fromHue(h.Pix[i], chroma, l.Pix[i]-chroma/2)
+	}
+	return r, g, b
+}
+
+func extremes(r, g, b float32) (max, min float32) {
+	max, min = r, r
+	for _, value := range []float32{g, b} {
+		if value > max {
+			max = value
+		}
+		if value < min {
+			min = value
+		}
+	}
+	return max, min
+}
+
+// hue returns the hue, scaled to 0-255, of a colour whose largest channel is
+// max and whose chroma is max minus the smallest one.
+func hue(r, g, b, max, chroma float32) float32 {
+	if chroma == 0 {
+		return 0 // Grey
+	}
+	var sector float32 // Of 60 degrees
+	switch max {
+	case r:
+		sector = (g - b) / chroma
+		if sector < 0 {
+			sector += 6
+		}
+	case g:
+		sector = (b-r)/chroma + 2
+	default:
+		sector = (r-g)/chroma + 4
+	}
+	return sector * 255 / 6
+}
+
+// fromHue returns the colour with the hue h (0-255) and the given chroma
+// whose smallest channel is min.
+func fromHue(h, chroma, min float32) (r, g, b float32) {
+	sector := math.Mod(float64(h)*6/255, 6)
+	if sector < 0 {
+		sector += 6
+	}
+	middle := chroma * float32(1-math.Abs(math.Mod(sector, 2)-1))
+	switch int(sector) {
+	case 0:
+		r, g = chroma, middle
+	case 1:
+		r, g = middle, chroma
+	case 2:
+		g, b = chroma, middle
+	case 3:
+		g, b = middle, chroma
+	case 4:
+		r, b = middle, chroma
+	default:
+		r, b = chroma, middle
+	}
+	return r + min, g + min, b + min
+}
+
+// ToXYZ converts from sRGB to CIE XYZ, every channel scaled so that the D65
+// white is 255.
+func ToXYZ(r, g, b *processing.Plane) (x, y, z *processing.Plane) {
+	x, y, z = newPlanes(r)
+	processing.ParallelRows(r.Height, func(row int) {
+		for i := row * r.Width; i < (row+1)*r.Width; i++ {
+			X, Y, Z := toXYZ(r.Pix[i], g.Pix[i], b.Pix[i])
+			x.Pix[i], y.Pix[i], z.Pix[i] = float32(X/whiteX*255), float32(Y/whiteY*255), float32(Z/whiteZ*255)
+		}
+	})
+	return x, y, z
+}
+
+func FromXYZ(x, y, z *processing.Plane) (r, g, b *processing.Plane) {
+	r, g, b = newPlanes(x)
+	processing.ParallelRows(x.Height, func(row int) {
+		for i := row * x.Width; i < (row+1)*x.Width; i++ {
+			r.Pix[i], g.Pix[i], b.Pix[i] = fromXYZ(float64(x.Pix[i])*whiteX/255, float64(y.Pix[i])*whiteY/255, float64(z.Pix[i])*whiteZ/255)
+		}
+	})
+	return r, g, b
+}
+
+// ToCMYK converts to the cyan, magenta, yellow and black inks of
+// image/color, without any colour profile.
+func ToCMYK(r, g, b *processing.Plane) (c, m, y, k *processing.Plane) {
+	c, m, y = newPlanes(r)
+	k = processing.NewPlane(r.Width, r.Height)
+	for i := range c.Pix {
+		max, _ := extremes(r.Pix[i], g.Pix[i], b.Pix[i])
+		k.Pix[i] = 255 - max
+		if max > 0 {
+			c.Pix[i] = (max - r.Pix[i]) / max * 255
+			m.Pix[i] = (max - g.Pix[i]) / max * 255
+			y.Pix[i] = (max - b.Pix[i]) / max * 255
+		}
+	}
+	return c, m, y, k
+}
+
+func FromCMYK(c, m, y, k *processing.Plane) (r, g, b *processing.Plane) {
+	r, g, b = newPlanes(c)
+	for i := range c.Pix {
+		white := (255 - k.Pix[i]) / 255
+		r.Pix[i] = (255 - c.Pix[i]) * white
+		g.Pix[i] = (255 - m.Pix[i]) * white
+		b.Pix[i] = (255 - y.Pix[i]) * white
+	}
+	return r, g, b
+}
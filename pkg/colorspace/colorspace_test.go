@@ -0,0 +1,129 @@
+package colorspace
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// colours returns an opaque image with greys, the primaries and the
+// secondaries and a spread of other colours.
+func colours() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	for i := 0; i < 256; i++ {
+		c := color.NRGBA{uint8(i * 37), uint8(i * 91), uint8(i * 13), 255}
+		if i < 8 {
+			c = color.NRGBA{uint8(255 * (i & 1)), uint8(255 * (i >> 1 & 1)), uint8(255 * (i >> 2 & 1)), 255}
+		} else if i < 16 {
+			level := uint8(i * 16)
+			c = color.NRGBA{level, level, level, 255}
+		}
+		img.SetNRGBA(i%16, i/16, c)
+	}
+	return img
+}
+
+func TestRoundTrip(t *testing.T) {
+	img := colours()
+	for _, name := range Spaces() {
+		space, _ := ParseSpace(name)
+		channels, alpha := Split(img, space)
+		if len(channels) != len(space.Channels()) {
+			t.Errorf("%v: %v channels, want %v", space, len(channels), len(space.Channels()))
+			continue
+		}
+		result, err := Merge(space, channels, alpha)
+		if err != nil {
+			t.Errorf("%v: %v", space, err)
+			continue
+		}
+		for y := 0; y < 16; y++ {
+			for x := 0; x < 16; x++ {
+				want := img.NRGBAAt(x, y)
+				got := color.NRGBAModel.Convert(result.At(x, y)).(color.NRGBA)
+				if far(got.R, want.R) || far(got.G, want.G) || far(got.B, want.B) || got.A != want.A {
+					t.Errorf("%v: %v becomes %v", space, want, got)
+				}
+			}
+		}
+	}
+}
+
+// far says if two samples differ by more than the rounding.
+func far(a, b uint8) bool {
+	return math.Abs(float64(a)-float64(b)) > 1
+}
+
+func TestKnownColours(t *testing.T) {
+	tests := []struct {
+		space  Space
+		colour color.NRGBA
+		want   []float32
+	}{
+		{HSV, color.NRGBA{255, 0, 0, 255}, []float32{0, 255, 255}},
+		{HSV, color.NRGBA{0, 255, 0, 255}, []float32{85, 255, 255}}, // 120 degrees
+		{HSL, color.NRGBA{0, 0, 255, 255}, []float32{170, 255, 127.5}},
+		{HSV, color.NRGBA{128, 128, 128, 255}, []float32{0, 0, 128}},
+		{YCbCr, color.NRGBA{255, 255, 255, 255}, []float32{255, 128, 128}},
+		{Lab, color.NRGBA{255, 255, 255, 255}, []float32{255, 128, 128}},
+		{Lab, color.NRGBA{0, 0, 0, 255}, []float32{0, 128, 128}},
+		{XYZ, color.NRGBA{255, 255, 255, 255}, []float32{255, 255, 255}},
+		{CMYK, color.NRGBA{255, 0, 0, 255}, []float32{0, 255, 255, 0}},
+		{CMYK, color.NRGBA{0, 0, 0, 255}, []float32{0, 0, 0, 255}},
+	}
+	for _, test := range tests {
+		img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+		img.SetNRGBA(0, 0, test.colour)
+		channels, _ := Split(img, test.space)
+		for i, want := range test.want {
+			if got := channels[i].At(0, 0); math.Abs(float64(got-want)) > 0.6 {
+				t.Errorf("%v of %v: channel %v is %v, want %v", test.space, test.colour, test.space.Channels()[i], got, want)
+			}
+		}
+	}
+}
+
+func TestOnChannel(t *testing.T) {
+	img := colours()
+	hsv, _ := ParseSpace("HSV")
+	value, err := hsv.ParseChannel("V")
+	if err != nil {
+		t.Fatal(err)
+	}
+	same, err := OnChannel(img, hsv, value, func(channel image.Image) (image.Image, error) {
+		return channel, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			want, got := img.NRGBAAt(x, y), color.NRGBAModel.Convert(same.At(x, y)).(color.NRGBA)
+			if far(got.R, want.R) || far(got.G, want.G) || far(got.B, want.B) {
+				t.Fatalf("leaving the value as it is changes %v into %v", want, got)
+			}
+		}
+	}
+	_, err = OnChannel(img, hsv, value, func(channel image.Image) (image.Image, error) {
+		return image.NewGray(image.Rect(0, 0, 2, 2)), nil
+	})
+	if err == nil {
+		t.Error("no error when the operation changes the size")
+	}
+}
+
+func TestMergeErrors(t *testing.T) {
+	channels, alpha := Split(colours(), HSV)
+	if _, err := Merge(CMYK, channels, alpha); err == nil {
+		t.Error("no error merging three channels as cmyk")
+	}
+	if _, err := Merge(HSV, channels, processing.NewPlane(3, 3)); err == nil {
+		t.Error("no error merging an alpha of another size")
+	}
+	if _, err := (RGB).ParseChannel("h"); err == nil {
+		t.Error("no error parsing a channel of another space")
+	}
+}
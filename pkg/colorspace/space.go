@@ -0,0 +1,161 @@
+package colorspace
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// Space is a colour space an image can be split into.
+type Space int
+
+const (
+	RGB Space = iota
+	HSV
+	HSL
+	YCbCr
+	Lab
+	XYZ
+	CMYK
+)
+
+var spaceNames = []string{"rgb", "hsv", "hsl", "ycbcr", "lab", "xyz", "cmyk"}
+
+var channelNames = [][]string{
+	{"r", "g", "b"},
+	{"h", "s", "v"},
+	{"h", "s", "l"},
+	{"y", "cb", "cr"},
+	{"l", "a", "b"},
+	{"x", "y", "z"},
+	{"c", "m", "y", "k"},
+}
+
+// Spaces returns the names of the colour spaces.
+func Spaces() []string {
+	return append([]string(nil), spaceNames...)
+}
+
+func ParseSpace(name string) (Space, error) {
+	for i, spaceName := range spaceNames {
+		if strings.EqualFold(name, spaceName) {
+			return Space(i), nil
+		}
+	}
+	return 0, fmt.Errorf("the colour space must be one of %v", strings.Join(spaceNames, ", "))
+}
+
+func (space Space) String() string {
+	return spaceNames[space]
+}
+
+// Channels returns the names of the channels of space in order.
+func (space Space) Channels() []string {
+	return append([]string(nil), channelNames[space]...)
+}
+
+// ParseChannel returns the index of the channel of space with the given name.
+func (space Space) ParseChannel(name string) (int, error) {
+	for i, channelName := range channelNames[space] {
+		if strings.EqualFold(name, channelName) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("the channel of %v must be one of %v", space, strings.Join(channelNames[space], ", "))
+}
+
+// Split converts img to space and returns its channels, scaled to 0-255, and
+// its alpha.
+func Split(img image.Image, space Space) (channels []*processing.Plane, alpha *processing.Plane) {
+	planes := processing.Planes(img)
+	r, g, b := planes[0], planes[1], planes[2]
+	switch space {
+	case RGB:
+		channels = []*processing.Plane{r, g, b}
+	case HSV:
+		channels = three(ToHSV(r, g, b))
+	case HSL:
+		channels = three(ToHSL(r, g, b))
+	case YCbCr:
+		channels = three(ToYCbCr(r, g, b))
+	case Lab:
+		channels = three(ToLab(r, g, b))
+	case XYZ:
+		channels = three(ToXYZ(r, g, b))
+	case CMYK:
+		c, m, y, k := ToCMYK(r, g, b)
+		channels = []*processing.Plane{c, m, y, k}
+	}
+	return channels, planes[3]
+}
+
+// Merge joins the channels of space back into an image. A nil alpha makes
+// it opaque.
+func Merge(space Space, channels []*processing.Plane, alpha *processing.Plane) (image.Image, error) {
+	return MergeDepth(space, channels, alpha, processing.Depth8)
+}
+
+// MergeDepth is Merge giving an image with the depth d.
+func MergeDepth(space Space, channels []*processing.Plane, alpha *processing.Plane, d processing.Depth) (image.Image, error) {
+	if len(channels) != len(channelNames[space]) {
+		return nil, fmt.Errorf("%v needs %v channels, not %v", space, len(channelNames[space]), len(channels))
+	}
+	w, h := channels[0].Width, channels[0].Height
+	for _, channel := range append(channels, alpha) {
+		if channel != nil && (channel.Width != w || channel.Height != h) {
+			return nil, fmt.Errorf("the channels must have the same size")
+		}
+	}
+	if alpha == nil {
+		alpha = processing.NewPlane(w, h)
+		for i := range alpha.Pix {
+			alpha.Pix[i] = 255
+		}
+	}
+	var r, g, b *processing.Plane
+	switch space {
+	case RGB:
+		r, g, b = channels[0], channels[1], channels[2]
+	case HSV:
+		r, g, b = FromHSV(channels[0], channels[1], channels[2])
+	case HSL:
+		r, g, b = FromHSL(channels[0], channels[1], channels[2])
+	case YCbCr:
+		r, g, b = FromYCbCr(channels[0], channels[1], channels[2])
+	case Lab:
+		r, g, b = FromLab(channels[0], channels[1], channels[2])
+	case XYZ:
+		r, g, b = FromXYZ(channels[0], channels[1], channels[2])
+	case CMYK:
+		r, g, b = FromCMYK(channels[0], channels[1], channels[2], channels[3])
+	}
+	return processing.FromPlanesDepth([4]*processing.Plane{r, g, b, alpha}, d), nil
+}
+
+// Channel returns one channel of img in space as a grey image.
+func Channel(img image.Image, space Space, channel int) image.Image {
+	channels, _ := Split(img, space)
+	return processing.GreyImageDepth(channels[channel], processing.DepthOf(img))
+}
+
+// OnChannel applies op to one channel of img in space, given to op as a grey
+// image, and puts the grey levels of its result back in the channel.
+func OnChannel(img image.Image, space Space, channel int, op func(image.Image) (image.Image, error)) (image.Image, error) {
+	channels, alpha := Split(img, space)
+	depth := processing.DepthOf(img)
+	result, err := op(processing.GreyImageDepth(channels[channel], depth))
+	if err != nil {
+		return nil, err
+	}
+	if result.Bounds().Size() != img.Bounds().Size() {
+		return nil, fmt.Errorf("the operation changed the size of the channel")
+	}
+	channels[channel] = processing.LuminancePlane(result, processing.Average) // Grey, so any weights give the same
+	return MergeDepth(space, channels, alpha, depth)
+}
+
+func three(a, b, c *processing.Plane) []*processing.Plane {
+	return []*processing.Plane{a, b, c}
+}
@@ -0,0 +1,200 @@
+// Package convolution filters images with arbitrary kernels. Kernels are
+// applied as a correlation, like most image tools do, so they are not
+// flipped; separable ones are applied as two 1D passes.
+package convolution
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// Border says which values the pixels outside of the image take.
+type Border int
+
+const (
+	Zero      Border = iota // 0
+	Replicate               // aaa|abcd|ddd
+	Reflect                 // cb|abcd|cb, the edge is not repeated
+	Wrap                    // cd|abcd|ab
+)
+
+var borderNames = []string{"zero", "replicate", "reflect", "wrap"}
+
+// Borders returns the names of the border modes.
+func Borders() []string {
+	return append([]string(nil), borderNames...)
+}
+
+func ParseBorder(name string) (Border, error) {
+	for i, borderName := range borderNames {
+		if strings.EqualFold(name, borderName) {
+			return Border(i), nil
+		}
+	}
+	return 0, fmt.Errorf("the border must be one of %v", strings.Join(borderNames, ", "))
+}
+
+func (border Border) String() string {
+	return borderNames[border]
+}
+
+// index maps i to a position inside [0, n), false if it is outside of the
+// image and the border is Zero.
+func (border Border) index(i, n int) (int, bool) {
+	if i >= 0 && i < n {
+		return i, true
+	}
+	switch border {
+	case Replicate:
+		if i < 0 {
+			return 0, true
+		}
+		return n - 1, true
+	case Reflect:
+		if n == 1 {
+			return 0, true
+		}
+		period := 2 * (n - 1)
+		i %= period
+		if i < 0 {
+			i += period
+		}
+		if i >= n {
+			i = period - i
+		}
+		return i, true
+	case Wrap:
+		i %= n
+		if i < 0 {
+			i += n
+		}
+		return i, true
+	}
+	return 0, false
+}
+
+// Convolve filters the red, green and blue channels of img with k, keeping
+// the alpha channel.
+func Convolve(img image.Image, k Kernel, border Border) image.Image {
+	planes := processing.Planes(img)
+	for i := 0; i < 3; i++ {
+		planes[i] = ConvolvePlane(planes[i], k, border)
+	}
+	return processing.FromPlanesLike(planes, img)
+}
+
+// ConvolvePlane filters a single plane with k.
+func ConvolvePlane(p *processing.Plane, k Kernel, border Border) *processing.Plane {
+	var result *processing.Plane
+	if column, row, ok := k.Separate(); ok {
+		result = pass(pass(p, row, true, border), column, false, border)
+	} else {
+		result = convolve2D(p, k, border)
+	}
+	if k.Offset != 0 {
+		offset := float32(k.Offset)
+		for i := range result.Pix {
+			result.Pix[i] += offset
+		}
+	}
+	return result
+}
+
+// pass filters p with a 1D kernel along its rows or its columns. Both go
+// row by row so the memory is read in order.
+func pass(p *processing.Plane, weights []float64, horizontal bool, border Border) *processing.Plane {
+	result := processing.NewPlane(p.Width, p.Height)
+	radius := len(weights) / 2
+	w := make([]float32, len(weights))
+	for i, weight := range weights {
+		w[i] = float32(weight)
+	}
+	processing.ParallelRows(p.Height, func(y int) {
+		out := result.Pix[y*p.Width : (y+1)*p.Width]
+		if !horizontal {
+			for j, weight := range w {
+				sy, ok := border.index(y+j-radius, p.Height)
+				if !ok || weight == 0 {
+					continue
+				}
+				in := p.Pix[sy*p.Width : (sy+1)*p.Width]
+				for x, value := range in {
+					out[x] += weight * value
+				}
+			}
+			return
+		}
+		in := p.Pix[y*p.Width : (y+1)*p.Width]
+		for x := range out {
+			var sum float32
+			if x >= radius && x < p.Width-radius { // Inside, no border to care about
+				for j, value := range in[x-radius : x-radius+len(w)] {
+					sum += w[j] * value
+				}
+			} else {
+				for j, weight := range w {
+					if sx, ok := border.index(x+j-radius, p.Width); ok {
+						sum += weight * in[sx]
+					}
+				}
+			}
+			out[x] = sum
+		}
+	})
+	return result
+}
+
+func convolve2D(p *processing.Plane, k Kernel, border Border) *processing.Plane {
+	result := processing.NewPlane(p.Width, p.Height)
+	rx, ry := k.Width/2, k.Height/2
+	processing.ParallelRows(p.Height, func(y int) {
+		out := result.Pix[y*p.Width : (y+1)*p.Width]
+		for j := 0; j < k.Height; j++ {
+			sy, ok := border.index(y+j-ry, p.Height)
+			if !ok {
+				continue
+			}
+			in := p.Pix[sy*p.Width : (sy+1)*p.Width]
+			for i := 0; i < k.Width; i++ {
+				weight := float32(k.At(i, j))
+				if weight == 0 {
+					continue
+				}
+				shift := i - rx
+				from, to := 0, p.Width // Where x+shift is inside
+				if shift < 0 {
+					from = -shift
+				} else {
+					to = p.Width - shift
+				}
+				if from > to {
+					from = to
+				}
+				for x := from; x < to; x++ {
+					out[x] += weight * in[x+shift]
+				}
+				for _, x := range borderColumns(from, to, p.Width) {
+					if sx, ok := border.index(x+shift, p.Width); ok {
+						out[x] += weight * in[sx]
+					}
+				}
+			}
+		}
+	})
+	return result
+}
+
+// borderColumns returns the columns outside of [from, to).
+func borderColumns(from, to, width int) []int {
+	columns := make([]int, 0, from+width-to)
+	for x := 0; x < from; x++ {
+		columns = append(columns, x)
+	}
+	for x := to; x < width; x++ {
+		columns = append(columns, x)
+	}
+	return columns
+}
@@ -0,0 +1,145 @@
+package convolution
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// row returns a plane one pixel high with values.
+func row(values ...float32) *processing.Plane {
+	p := processing.NewPlane(len(values), 1)
+	copy(p.Pix, values)
+	return p
+}
+
+// ramp returns a plane whose pixels are different from their neighbours.
+func ramp(width, height int) *processing.Plane {
+	p := processing.NewPlane(width, height)
+	for i := range p.Pix {
+		p.Pix[i] = float32(i*i%37) * 7
+	}
+	return p
+}
+
+func TestBorders(t *testing.T) {
+	previous, _ := New([][]float64{{1, 0, 0}}) // Takes the pixel on the left
+	next, _ := New([][]float64{{0, 0, 1}})
+	tests := []struct {
+		border         Border
+		previous, next []float32
+	}{
+		{Zero, []float32{0, 1, 2, 3, 4}, []float32{2, 3, 4, 5, 0}},
+		{Replicate, []float32{1, 1, 2, 3, 4}, []float32{2, 3, 4, 5, 5}},
+		{Reflect, []float32{2, 1, 2, 3, 4}, []float32{2, 3, 4, 5, 4}},
+		{Wrap, []float32{5, 1, 2, 3, 4}, []float32{2, 3, 4, 5, 1}},
+	}
+	for _, test := range tests {
+		for _, k := range []struct {
+			kernel Kernel
+			want   []float32
+		}{{previous, test.previous}, {next, test.next}} {
+			got := ConvolvePlane(row(1, 2, 3, 4, 5), k.kernel, test.border)
+			for i, want := range k.want {
+				if got.Pix[i] != want {
+					t.Errorf("%v border, kernel %v: got %v, want %v", test.border, k.kernel, got.Pix, k.want)
+					break
+				}
+			}
+		}
+	}
+}
+
+// TestSeparable checks that the two 1D passes give what the kernel gives
+// applied in 2D.
+func TestSeparable(t *testing.T) {
+	gaussian, _ := Gaussian(1)
+	box, _ := Box(5)
+	for _, k := range []Kernel{gaussian, box} {
+		if _, _, ok := k.Separate(); !ok {
+			t.Errorf("kernel %v isn't separated", k)
+			continue
+		}
+		for _, border := range []Border{Zero, Replicate, Reflect, Wrap} {
+			p := ramp(9, 7)
+			separated, direct := ConvolvePlane(p, k, border), convolve2D(p, k, border)
+			for i := range direct.Pix {
+				if math.Abs(float64(separated.Pix[i]-direct.Pix[i])) > 1e-3 {
+					t.Errorf("%vx%v kernel, %v border: pixel %v is %v in two passes and %v in 2D",
+						k.Width, k.Height, border, i, separated.Pix[i], direct.Pix[i])
+					break
+				}
+			}
+		}
+	}
+	for _, k := range []Kernel{Sharpen(), Laplacian(), Emboss()} {
+		if _, _, ok := k.Separate(); ok {
+			t.Errorf("kernel %v is separated", k)
+		}
+	}
+}
+
+func TestFlat(t *testing.T) {
+	box, _ := Box(3)
+	gaussian, _ := Gaussian(2)
+	tests := []struct {
+		name   string
+		kernel Kernel
+		want   float32 // Of a plane of 100s
+	}{
+		{"box", box, 100},
+		{"gaussian", gaussian, 100},
+		{"sharpen", Sharpen(), 100},
+		{"laplacian", Laplacian(), 128}, // The offset
+	}
+	for _, test := range tests {
+		p := processing.NewPlane(6, 5)
+		for i := range p.Pix {
+			p.Pix[i] = 100
+		}
+		for i, value := range ConvolvePlane(p, test.kernel, Replicate).Pix {
+			if math.Abs(float64(value-test.want)) > 1e-3 {
+				t.Errorf("%v: pixel %v is %v, want %v", test.name, i, value, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestConvolve(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 3))
+	for i := range img.Pix {
+		img.Pix[i] = 60
+	}
+	img.SetNRGBA(1, 1, color.NRGBA{R: 150, G: 60, B: 60, A: 60})
+	box, _ := Box(3)
+	result := Convolve(img, box, Replicate)
+	corner := color.NRGBAModel.Convert(result.At(0, 0)).(color.NRGBA)
+	if corner.A != 60 {
+		t.Errorf("the alpha channel is %v, want 60", corner.A)
+	}
+	if corner.R != 70 || corner.G != 60 {
+		t.Errorf("the corner is %v, %v, want 70, 60", corner.R, corner.G)
+	}
+}
+
+func TestParse(t *testing.T) {
+	k, err := Parse("1,2,1; 2 4 2\n1,2,1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.Width != 3 || k.Height != 3 || k.Sum() != 16 {
+		t.Errorf("got %v", k)
+	}
+	if again, err := Parse(k.String()); err != nil || again.String() != k.String() {
+		t.Errorf("String doesn't parse back: %v, %v", again, err)
+	}
+	for _, text := range []string{"", "1,2", "1,a,1", "1,2,3;4,5,6", "1,2,3;4,5"} {
+		if _, err := Parse(text); err == nil {
+			t.Errorf("%q: no error", text)
+		}
+	}
+}
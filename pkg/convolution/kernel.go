@@ -0,0 +1,131 @@
+package convolution
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Kernel is a Width x Height matrix of weights centred on the pixel being
+// filtered. Offset is added to every result, 128 shows both the negative and
+// the positive responses of kernels whose weights sum 0.
+type Kernel struct {
+	Width, Height int
+	Values        []float64 // Row major
+	Offset        float64
+}
+
+// New returns the kernel with the given rows, which must all be as long and
+// have an odd length, like the number of rows.
+func New(rows [][]float64) (Kernel, error) {
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return Kernel{}, fmt.Errorf("the kernel is empty")
+	}
+	k := Kernel{Width: len(rows[0]), Height: len(rows)}
+	if k.Width%2 == 0 || k.Height%2 == 0 {
+		return Kernel{}, fmt.Errorf("the kernel must have an odd number of rows and columns, it is %vx%v", k.Width, k.Height)
+	}
+	for i, row := range rows {
+		if len(row) != k.Width {
+			return Kernel{}, fmt.Errorf("row %v of the kernel has %v values instead of %v", i+1, len(row), k.Width)
+		}
+		k.Values = append(k.Values, row...)
+	}
+	return k, nil
+}
+
+// Parse reads a kernel written by rows separated by ";" or new lines with
+// the values separated by "," or spaces, e.g. "1,2,1; 2,4,2; 1,2,1".
+func Parse(text string) (Kernel, error) {
+	var rows [][]float64
+	lines := strings.FieldsFunc(text, func(r rune) bool { return r == ';' || r == '\n' })
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+		row := make([]float64, len(fields))
+		for i, field := range fields {
+			value, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return Kernel{}, fmt.Errorf("%q is not a number", field)
+			}
+			row[i] = value
+		}
+		rows = append(rows, row)
+	}
+	return New(rows)
+}
+
+func (k Kernel) At(x, y int) float64 {
+	return k.Values[y*k.Width+x]
+}
+
+func (k Kernel) Sum() (sum float64) {
+	for _, value := range k.Values {
+		sum += value
+	}
+	return sum
+}
+
+// Scale returns the kernel with every weight multiplied by factor.
+func (k Kernel) Scale(factor float64) Kernel {
+	scaled := k
+	scaled.Values = make([]float64, len(k.Values))
+	for i, value := range k.Values {
+		scaled.Values[i] = value * factor
+	}
+	return scaled
+}
+
+// Normalize divides the weights by their sum so the brightness is kept.
+// Kernels whose weights sum 0 are returned as they are.
+func (k Kernel) Normalize() Kernel {
+	sum := k.Sum()
+	if math.Abs(sum) < 1e-12 {
+		return k
+	}
+	return k.Scale(1 / sum)
+}
+
+// Separate returns the column and the row vectors whose product is the
+// kernel, if it is separable, so it can be applied in two 1D passes.
+func (k Kernel) Separate() (column, row []float64, ok bool) {
+	pivot, max := 0, 0.0
+	for i, value := range k.Values {
+		if math.Abs(value) > max {
+			pivot, max = i, math.Abs(value)
+		}
+	}
+	if max == 0 {
+		return nil, nil, false
+	}
+	px, py := pivot%k.Width, pivot/k.Width
+	row = append([]float64(nil), k.Values[py*k.Width:(py+1)*k.Width]...)
+	column = make([]float64, k.Height)
+	for y := range column {
+		column[y] = k.At(px, y) / k.At(px, py)
+	}
+	tolerance := max * 1e-9
+	for y := 0; y < k.Height; y++ {
+		for x := 0; x < k.Width; x++ {
+			if math.Abs(k.At(x, y)-column[y]*row[x]) > tolerance {
+				return nil, nil, false
+			}
+		}
+	}
+	return column, row, true
+}
+
+func (k Kernel) String() string {
+	rows := make([]string, k.Height)
+	for y := range rows {
+		values := make([]string, k.Width)
+		for x := range values {
+			values[x] = strconv.FormatFloat(k.At(x, y), 'g', -1, 64)
+		}
+		rows[y] = strings.Join(values, ",")
+	}
+	return strings.Join(rows, ";")
+}
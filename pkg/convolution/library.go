@@ -0,0 +1,70 @@
+package convolution
+
+import (
+	"fmt"
+	"math"
+)
+
+// Box averages a size x size neighbourhood, size being odd.
+func Box(size int) (Kernel, error) {
+	if size < 1 || size%2 == 0 {
+		return Kernel{}, fmt.Errorf("the size of the box must be a positive odd number")
+	}
+	k := Kernel{Width: size, Height: size, Values: make([]float64, size*size)}
+	for i := range k.Values {
+		k.Values[i] = 1 / float64(size*size)
+	}
+	return k, nil
+}
+
+// Gaussian blurs with the given standard deviation, the kernel reaching 3
+// sigmas from its centre.
+func Gaussian(sigma float64) (Kernel, error) {
+	if sigma <= 0 || sigma > 50 {
+		return Kernel{}, fmt.Errorf("sigma must be in the range (0, 50]")
+	}
+	radius := int(math.Ceil(3 * sigma))
+	size := 2*radius + 1
+	weights := make([]float64, size)
+	for i := range weights {
+		d := float64(i - radius)
+		weights[i] = math.Exp(-d * d / (2 * sigma * sigma))
+	}
+	k := Kernel{Width: size, Height: size, Values: make([]float64, size*size)}
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			k.Values[y*size+x] = weights[x] * weights[y]
+		}
+	}
+	return k.Normalize(), nil
+}
+
+func Sharpen() Kernel {
+	k, _ := New([][]float64{
+		{0, -1, 0},
+		{-1, 5, -1},
+		{0, -1, 0},
+	})
+	return k
+}
+
+func Emboss() Kernel {
+	k, _ := New([][]float64{
+		{-2, -1, 0},
+		{-1, 1, 1},
+		{0, 1, 2},
+	})
+	return k
+}
+
+// Laplacian responds to the changes of the second derivative. Its result is
+// shifted by 128 so flat areas are grey.
+func Laplacian() Kernel {
+	k, _ := New([][]float64{
+		{0, 1, 0},
+		{1, -4, 1},
+		{0, 1, 0},
+	})
+	k.Offset = 128
+	return k
+}
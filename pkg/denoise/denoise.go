@@ -0,0 +1,66 @@
+// Package denoise removes noise from images with non-linear filters that
+// keep the edges.
+package denoise
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/vision-go/vision-go/pkg/colorspace"
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// Mode says which channels are filtered.
+type Mode int
+
+const (
+	RGB       Mode = iota // Every colour channel
+	Luminance             // Only the Y of YCbCr, so the colours don't shift
+)
+
+var modeNames = []string{"rgb", "luminance"}
+
+// Modes returns the names of the modes.
+func Modes() []string {
+	return append([]string(nil), modeNames...)
+}
+
+func ParseMode(name string) (Mode, error) {
+	for i, modeName := range modeNames {
+		if strings.EqualFold(name, modeName) {
+			return Mode(i), nil
+		}
+	}
+	return 0, fmt.Errorf("the mode must be one of %v", strings.Join(modeNames, ", "))
+}
+
+func (mode Mode) String() string {
+	return modeNames[mode]
+}
+
+// apply runs filter on the channels of img chosen by mode.
+func apply(img image.Image, mode Mode, filter func(*processing.Plane) *processing.Plane) image.Image {
+	planes := processing.Planes(img)
+	if mode == RGB {
+		for i := 0; i < 3; i++ {
+			planes[i] = filter(planes[i])
+		}
+		return processing.FromPlanesLike(planes, img)
+	}
+	y, cb, cr := colorspace.ToYCbCr(planes[0], planes[1], planes[2])
+	y = filter(y)
+	planes[0], planes[1], planes[2] = colorspace.FromYCbCr(y, cb, cr)
+	return processing.FromPlanesLike(planes, img)
+}
+
+// clampIndex keeps i inside [0, n), repeating the border pixels.
+func clampIndex(i, n int) int {
+	if i < 0 {
+		return 0
+	}
+	if i >= n {
+		return n - 1
+	}
+	return i
+}
@@ -0,0 +1,203 @@
+package denoise
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// ramp returns a plane of whole grey levels that change from pixel to pixel.
+func ramp(width, height int) *processing.Plane {
+	p := processing.NewPlane(width, height)
+	for i := range p.Pix {
+		p.Pix[i] = float32(i * i * 31 % 256)
+	}
+	return p
+}
+
+// slowMedian sorts the window of every pixel.
+func slowMedian(p *processing.Plane, radius int) *processing.Plane {
+	result := processing.NewPlane(p.Width, p.Height)
+	for y := 0; y < p.Height; y++ {
+		for x := 0; x < p.Width; x++ {
+			var window []float64
+			for dy := -radius; dy <= radius; dy++ {
+				for dx := -radius; dx <= radius; dx++ {
+					window = append(window, float64(p.At(clampIndex(x+dx, p.Width), clampIndex(y+dy, p.Height))))
+				}
+			}
+			sort.Float64s(window)
+			result.Set(x, y, float32(window[len(window)/2]))
+		}
+	}
+	return result
+}
+
+func TestMedian(t *testing.T) {
+	for _, radius := range []int{1, 2, 3} {
+		p := ramp(11, 9)
+		got, want := median(p, radius), slowMedian(p, radius)
+		for i := range want.Pix {
+			if got.Pix[i] != want.Pix[i] {
+				t.Errorf("radius %v: pixel %v is %v, want %v", radius, i, got.Pix[i], want.Pix[i])
+				break
+			}
+		}
+	}
+}
+
+func TestSortedMedian(t *testing.T) {
+	for _, radius := range []int{1, 2, 3} {
+		p := ramp(11, 9)
+		for i := range p.Pix {
+			p.Pix[i] /= 7 // Not whole levels
+		}
+		got, want := sortedMedian(p, radius), slowMedian(p, radius)
+		for i := range want.Pix {
+			if got.Pix[i] != want.Pix[i] {
+				t.Errorf("radius %v: pixel %v is %v, want %v", radius, i, got.Pix[i], want.Pix[i])
+				break
+			}
+		}
+	}
+}
+
+// TestMedian16 checks that the median of a 16-bit ramp, which is the ramp,
+// keeps the levels that are closer than an 8-bit one.
+func TestMedian16(t *testing.T) {
+	img := image.NewGray16(image.Rect(0, 0, 10, 3))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 10; x++ {
+			img.SetGray16(x, y, color.Gray16{Y: uint16(1012 + x)})
+		}
+	}
+	for _, mode := range []Mode{RGB, Luminance} {
+		result, err := Median(img, 3, mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := result.(*image.Gray16); !ok {
+			t.Errorf("%v: the result is a %T", mode, result)
+		}
+		for x := 0; x < 10; x++ {
+			if got := color.Gray16Model.Convert(result.At(x, 1)).(color.Gray16).Y; got != uint16(1012+x) {
+				t.Errorf("%v: level %v becomes %v", mode, 1012+x, got)
+			}
+		}
+	}
+}
+
+// noisy returns a grey image with a step from 50 to 200 at column 8 and
+// noise of up to 8 levels added, and the image without the noise.
+func noisy() (img, clean *image.Gray) {
+	img, clean = image.NewGray(image.Rect(0, 0, 16, 9)), image.NewGray(image.Rect(0, 0, 16, 9))
+	for y := 0; y < 9; y++ {
+		for x := 0; x < 16; x++ {
+			level := 50
+			if x >= 8 {
+				level = 200
+			}
+			clean.SetGray(x, y, color.Gray{Y: uint8(level)})
+			img.SetGray(x, y, color.Gray{Y: uint8(level + ((x*7+y*13)%5-2)*4)})
+		}
+	}
+	return img, clean
+}
+
+// difference returns the mean absolute difference of the grey levels of a
+// and b.
+func difference(a, b image.Image) float64 {
+	var sum float64
+	bounds := a.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			ya := color.GrayModel.Convert(a.At(x, y)).(color.Gray).Y
+			yb := color.GrayModel.Convert(b.At(x, y)).(color.Gray).Y
+			sum += math.Abs(float64(ya) - float64(yb))
+		}
+	}
+	return sum / float64(bounds.Dx()*bounds.Dy())
+}
+
+// TestFilters checks that the filters take the noise out of the flat areas
+// and keep the edges.
+func TestFilters(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter func(image.Image) (image.Image, error)
+	}{
+		{"median", func(img image.Image) (image.Image, error) {
+			return Median(img, 3, RGB)
+		}},
+		{"bilateral", func(img image.Image) (image.Image, error) {
+			return Bilateral(img, 2, 20, RGB)
+		}},
+		{"non-local means", func(img image.Image) (image.Image, error) {
+			return NonLocalMeans(img, 10, 3, 7, RGB)
+		}},
+	}
+	img, clean := noisy()
+	before := difference(img, clean)
+	for _, test := range tests {
+		result, err := test.filter(img)
+		if err != nil {
+			t.Errorf("%v: %v", test.name, err)
+			continue
+		}
+		if after := difference(result, clean); after > before/2 {
+			t.Errorf("%v: the noise goes from %.2f to %.2f", test.name, before, after)
+		}
+		for y := 0; y < 9; y++ {
+			left := color.GrayModel.Convert(result.At(7, y)).(color.Gray).Y
+			right := color.GrayModel.Convert(result.At(8, y)).(color.Gray).Y
+			if left > 60 || right < 190 {
+				t.Errorf("%v: the step at row %v is %v to %v", test.name, y, left, right)
+			}
+		}
+	}
+}
+
+func TestModes(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 6, 6))
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 6; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: 200, G: 100, B: 30, A: 255})
+		}
+	}
+	for _, mode := range []Mode{RGB, Luminance} {
+		result, err := Median(img, 3, mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := color.NRGBAModel.Convert(result.At(2, 3)).(color.NRGBA); got != (color.NRGBA{200, 100, 30, 255}) {
+			t.Errorf("%v: a flat colour becomes %v", mode, got)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"even median", ValidateMedian(4)},
+		{"small median", ValidateMedian(1)},
+		{"no spatial sigma", ValidateBilateral(0, 20)},
+		{"range sigma", ValidateBilateral(2, 300)},
+		{"no strength", ValidateNonLocalMeans(0, 3, 7)},
+		{"even patch", ValidateNonLocalMeans(10, 4, 7)},
+		{"small search", ValidateNonLocalMeans(10, 3, 1)},
+	}
+	for _, test := range tests {
+		if test.err == nil {
+			t.Errorf("%v: no error", test.name)
+		}
+	}
+	if err := ValidateNonLocalMeans(10, 3, 7); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,113 @@
+package denoise
+
+import (
+	"fmt"
+	"image"
+	"sort"
+
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// ValidateMedian checks the parameters of Median.
+func ValidateMedian(size int) error {
+	if size < 3 || size > 31 || size%2 == 0 {
+		return fmt.Errorf("the window of the median must be an odd number from 3 to 31")
+	}
+	return nil
+}
+
+// Median replaces every sample by the median of the size x size window
+// around it, size being odd.
+func Median(img image.Image, size int, mode Mode) (image.Image, error) {
+	if err := ValidateMedian(size); err != nil {
+		return nil, err
+	}
+	filter := func(p *processing.Plane) *processing.Plane {
+		return median(p, size/2)
+	}
+	if processing.DepthOf(img) != processing.Depth8 {
+		filter = func(p *processing.Plane) *processing.Plane {
+			return sortedMedian(p, size/2)
+		}
+	}
+	return apply(img, mode, filter), nil
+}
+
+// median slides a histogram of the window along every row (Huang's
+// algorithm), moving the median from the one of the previous pixel, so the
+// cost per pixel grows with the radius, not its square.
+func median(p *processing.Plane, radius int) *processing.Plane {
+	w, h := p.Width, p.Height
+	result := processing.NewPlane(w, h)
+	half := ((2*radius+1)*(2*radius+1))/2 + 1 // Samples up to the median
+	processing.ParallelRows(h, func(y int) {
+		var hist [256]int
+		level, below := 0, 0 // Median and how many samples are under it
+		column := func(x, delta int) {
+			x = clampIndex(x, w)
+			for dy := -radius; dy <= radius; dy++ {
+				value := processing.Clamp(p.At(x, clampIndex(y+dy, h)))
+				hist[value] += delta
+				if int(value) < level {
+					below += delta
+				}
+			}
+		}
+		for dx := -radius; dx <= radius; dx++ {
+			column(dx, 1)
+		}
+		for x := 0; x < w; x++ {
+			if x > 0 {
+				column(x-radius-1, -1)
+				column(x+radius, 1)
+			}
+			for below+hist[level] < half {
+				below += hist[level]
+				level++
+			}
+			for below >= half {
+				level--
+				below -= hist[level]
+			}
+			result.Set(x, y, float32(level))
+		}
+	})
+	return result
+}
+
+// sortedMedian keeps the window sorted as it slides along every row, for the
+// samples of 16-bit and float images, which the histogram of median would
+// round to whole levels.
+func sortedMedian(p *processing.Plane, radius int) *processing.Plane {
+	w, h := p.Width, p.Height
+	result := processing.NewPlane(w, h)
+	size := (2*radius + 1) * (2*radius + 1)
+	processing.ParallelRows(h, func(y int) {
+		window := make([]float32, 0, size)
+		column := func(x int, add bool) {
+			x = clampIndex(x, w)
+			for dy := -radius; dy <= radius; dy++ {
+				value := p.At(x, clampIndex(y+dy, h))
+				i := sort.Search(len(window), func(i int) bool { return window[i] >= value })
+				if add {
+					window = append(window, 0)
+					copy(window[i+1:], window[i:])
+					window[i] = value
+				} else {
+					window = append(window[:i], window[i+1:]...)
+				}
+			}
+		}
+		for dx := -radius; dx <= radius; dx++ {
+			column(dx, true)
+		}
+		for x := 0; x < w; x++ {
+			if x > 0 {
+				column(x-radius-1, false)
+				column(x+radius, true)
+			}
+			result.Set(x, y, window[size/2])
+		}
+	})
+	return result
+}
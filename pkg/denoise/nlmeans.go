@@ -0,0 +1,118 @@
+package denoise
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// ValidateNonLocalMeans checks the parameters of NonLocalMeans.
+func ValidateNonLocalMeans(h float64, patch, search int) error {
+	if h <= 0 || h > 255 {
+		return fmt.Errorf("h must be in the range (0, 255]")
+	}
+	if patch < 1 || patch > 15 || patch%2 == 0 {
+		return fmt.Errorf("the patch must be an odd number from 1 to 15")
+	}
+	if search < 3 || search > 41 || search%2 == 0 {
+		return fmt.Errorf("the search window must be an odd number from 3 to 41")
+	}
+	return nil
+}
+
+// NonLocalMeans averages every sample with the ones of its search x search
+// window whose patch x patch neighbourhoods look alike, h being how much
+// they may differ (in grey levels). The distances of the patches are box
+// sums of the squared difference with every offset, so the patch size does
+// not change the cost; the search window does.
+func NonLocalMeans(img image.Image, h float64, patch, search int, mode Mode) (image.Image, error) {
+	if err := ValidateNonLocalMeans(h, patch, search); err != nil {
+		return nil, err
+	}
+	return apply(img, mode, func(p *processing.Plane) *processing.Plane {
+		return nonLocalMeans(p, float32(h), patch/2, search/2)
+	}), nil
+}
+
+// expTable holds exp(-x) for x in [0, expLimit) in expSteps steps, the
+// weights of farther patches being 0.
+const (
+	expLimit = 16
+	expSteps = 4096
+)
+
+var expTable = func() (table [expSteps]float32) {
+	for i := range table {
+		table[i] = float32(math.Exp(-float64(i) * expLimit / expSteps))
+	}
+	return table
+}()
+
+func nonLocalMeans(p *processing.Plane, h float32, patchRadius, searchRadius int) *processing.Plane {
+	w, ht := p.Width, p.Height
+	sum, weights := processing.NewPlane(w, ht), processing.NewPlane(w, ht)
+	distance, rows, patches := processing.NewPlane(w, ht), processing.NewPlane(w, ht), processing.NewPlane(w, ht)
+	area := float32((2*patchRadius + 1) * (2*patchRadius + 1))
+	scale := expSteps / (expLimit * h * h * area) // From a sum of squares to an index of expTable
+	shifted := make([]float32, w*ht)
+	for dy := -searchRadius; dy <= searchRadius; dy++ {
+		for dx := -searchRadius; dx <= searchRadius; dx++ {
+			processing.ParallelRows(ht, func(y int) {
+				in := p.Pix[clampIndex(y+dy, ht)*w:]
+				out := shifted[y*w : (y+1)*w]
+				for x := range out {
+					out[x] = in[clampIndex(x+dx, w)]
+				}
+				for x, value := range p.Pix[y*w : (y+1)*w] {
+					d := value - out[x]
+					distance.Pix[y*w+x] = d * d
+				}
+			})
+			boxSum(distance, rows, patches, patchRadius)
+			processing.ParallelRows(ht, func(y int) {
+				for i := y * w; i < (y+1)*w; i++ {
+					index := int(patches.Pix[i] * scale)
+					if index >= expSteps {
+						continue
+					}
+					weight := expTable[index]
+					sum.Pix[i] += weight * shifted[i]
+					weights.Pix[i] += weight
+				}
+			})
+		}
+	}
+	for i := range sum.Pix {
+		sum.Pix[i] /= weights.Pix[i] // At least the weight 1 of the offset 0
+	}
+	return sum
+}
+
+// boxSum writes in result the sum of the (2*radius+1)^2 window around every
+// sample of p, with running sums along the rows and then along the columns.
+func boxSum(p, rows, result *processing.Plane, radius int) {
+	w, h := p.Width, p.Height
+	processing.ParallelRows(h, func(y int) {
+		in := p.Pix[y*w : (y+1)*w]
+		var running float32
+		for x := -radius; x <= radius; x++ {
+			running += in[clampIndex(x, w)]
+		}
+		for x := 0; x < w; x++ {
+			rows.Pix[y*w+x] = running
+			running += in[clampIndex(x+radius+1, w)] - in[clampIndex(x-radius, w)]
+		}
+	})
+	processing.ParallelRows(h, func(y int) {
+		out := result.Pix[y*w : (y+1)*w]
+		copy(out, rows.Pix[clampIndex(y-radius, h)*w:])
+		for dy := -radius + 1; dy <= radius; dy++ {
+			row := rows.Pix[clampIndex(y+dy, h)*w:]
+			for x := range out {
+				out[x] += row[x]
+			}
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package edges
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/vision-go/vision-go/pkg/convolution"
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// ValidateCanny checks the parameters of Canny.
+func ValidateCanny(sigma, low, high float64) error {
+	if sigma < 0 || sigma > 50 {
+		return fmt.Errorf("sigma must be in the range [0, 50]")
+	}
+	if low < 0 || high > 255 || low > high {
+		return fmt.Errorf("the thresholds must be in the range [0, 255] with the low one not above the high one")
+	}
+	return nil
+}
+
+// Canny returns the edges of img in white over black. The grey level, with
+// the weights l, is smoothed with a gaussian of the given sigma (none if 0), the Sobel
+// gradient is thinned to its local maxima and the pixels whose magnitude is
+// over high are edges, with those over low connected to them.
+func Canny(img image.Image, sigma, low, high float64, l processing.Luminance) (image.Image, error) {
+	if err := ValidateCanny(sigma, low, high); err != nil {
+		return nil, err
+	}
+	grey := processing.LuminancePlane(img, l)
+	if sigma > 0 {
+		k, _ := convolution.Gaussian(sigma)
+		grey = convolution.ConvolvePlane(grey, k, convolution.Replicate)
+	}
+	gx, gy := gradient(grey, Sobel)
+	m := magnitude(gx, gy)
+	thin := suppressNonMaxima(m, gx, gy)
+	return processing.GreyImage(hysteresis(thin, float32(low), float32(high))), nil
+}
+
+// suppressNonMaxima keeps the pixels whose magnitude is not smaller than the
+// one of their two neighbours along the gradient.
+func suppressNonMaxima(m, gx, gy *processing.Plane) *processing.Plane {
+	w, h := m.Width, m.Height
+	thin := processing.NewPlane(w, h)
+	processing.ParallelRows(h, func(y int) {
+		for x := 0; x < w; x++ {
+			value := m.At(x, y)
+			if value == 0 {
+				continue
+			}
+			// Direction rounded to 0, 45, 90 or 135 degrees
+			angle := math.Atan2(float64(gy.At(x, y)), float64(gx.At(x, y))) * 180 / math.Pi
+			if angle < 0 {
+				angle += 180
+			}
+			dx, dy := 1, 0
+			switch {
+			case angle >= 22.5 && angle < 67.5:
+				dx, dy = 1, 1
+			case angle >= 67.5 && angle < 112.5:
+				dx, dy = 0, 1
+			case angle >= 112.5 && angle < 157.5:
+				dx, dy = -1, 1
+			}
+			if value >= neighbour(m, x+dx, y+dy) && value >= neighbour(m, x-dx, y-dy) {
+				thin.Set(x, y, value)
+			}
+		}
+	})
+	return thin
+}
+
+func neighbour(p *processing.Plane, x, y int) float32 {
+	if x < 0 || y < 0 || x >= p.Width || y >= p.Height {
+		return 0
+	}
+	return p.At(x, y)
+}
+
+// hysteresis marks with 255 the pixels from high up and, following them,
+// the ones over low connected to them.
+func hysteresis(m *processing.Plane, low, high float32) *processing.Plane {
+	w, h := m.Width, m.Height
+	edges := processing.NewPlane(w, h)
+	var stack []image.Point
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if m.At(x, y) >= high && m.At(x, y) > 0 && edges.At(x, y) == 0 {
+				edges.Set(x, y, 255)
+				stack = append(stack, image.Pt(x, y))
+			}
+			for len(stack) > 0 {
+				p := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						nx, ny := p.X+dx, p.Y+dy
+						if nx < 0 || ny < 0 || nx >= w || ny >= h || edges.At(nx, ny) != 0 {
+							continue
+						}
+						if m.At(nx, ny) > low {
+							edges.Set(nx, ny, 255)
+							stack = append(stack, image.Pt(nx, ny))
+						}
+					}
+				}
+			}
+		}
+	}
+	return edges
+}
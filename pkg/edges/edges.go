@@ -0,0 +1,112 @@
+// Package edges finds the edges of images from the gradient of their grey
+// level.
+package edges
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strings"
+
+	"github.com/vision-go/vision-go/pkg/convolution"
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// Operator is the pair of kernels that estimate the horizontal and the
+// vertical derivatives.
+type Operator int
+
+const (
+	Sobel Operator = iota
+	Prewitt
+	Scharr
+)
+
+var operatorNames = []string{"sobel", "prewitt", "scharr"}
+
+// Operators returns the names of the operators.
+func Operators() []string {
+	return append([]string(nil), operatorNames...)
+}
+
+func ParseOperator(name string) (Operator, error) {
+	for i, operatorName := range operatorNames {
+		if strings.EqualFold(name, operatorName) {
+			return Operator(i), nil
+		}
+	}
+	return 0, fmt.Errorf("the operator must be one of %v", strings.Join(operatorNames, ", "))
+}
+
+func (op Operator) String() string {
+	return operatorNames[op]
+}
+
+// kernel returns the horizontal derivative kernel, the vertical one being
+// its transpose, and the sum of its positive weights.
+func (op Operator) kernel() (convolution.Kernel, float32) {
+	side := map[Operator]float64{Sobel: 2, Prewitt: 1, Scharr: 10}[op]
+	corner := map[Operator]float64{Sobel: 1, Prewitt: 1, Scharr: 3}[op]
+	k, _ := convolution.New([][]float64{
+		{-corner, 0, corner},
+		{-side, 0, side},
+		{-corner, 0, corner},
+	})
+	return k, float32(side + 2*corner)
+}
+
+// Gradient returns the horizontal and vertical derivatives of the grey level
+// of img with the weights l, scaled so a step from 0 to 255 gives 255.
+func Gradient(img image.Image, op Operator, l processing.Luminance) (gx, gy *processing.Plane) {
+	return gradient(processing.LuminancePlane(img, l), op)
+}
+
+func gradient(grey *processing.Plane, op Operator) (gx, gy *processing.Plane) {
+	k, scale := op.kernel()
+	transposed := k
+	transposed.Values = make([]float64, len(k.Values))
+	for y := 0; y < k.Height; y++ {
+		for x := 0; x < k.Width; x++ {
+			transposed.Values[x*k.Width+y] = k.At(x, y)
+		}
+	}
+	gx = convolution.ConvolvePlane(grey, k, convolution.Replicate)
+	gy = convolution.ConvolvePlane(grey, transposed, convolution.Replicate)
+	for i := range gx.Pix {
+		gx.Pix[i] /= scale
+		gy.Pix[i] /= scale
+	}
+	return gx, gy
+}
+
+// Magnitude returns the strength of the gradient of every pixel.
+func Magnitude(img image.Image, op Operator, l processing.Luminance) image.Image {
+	gx, gy := Gradient(img, op, l)
+	return processing.GreyImageDepth(magnitude(gx, gy), processing.DepthOf(img))
+}
+
+func magnitude(gx, gy *processing.Plane) *processing.Plane {
+	m := processing.NewPlane(gx.Width, gx.Height)
+	for i := range m.Pix {
+		m.Pix[i] = float32(math.Hypot(float64(gx.Pix[i]), float64(gy.Pix[i])))
+	}
+	return m
+}
+
+// Direction returns the angle of the gradient of every pixel, from 0 to 255
+// for [0, 360) degrees counterclockwise from the x axis. Flat pixels are 0.
+func Direction(img image.Image, op Operator, l processing.Luminance) image.Image {
+	gx, gy := Gradient(img, op, l)
+	d := processing.NewPlane(gx.Width, gx.Height)
+	for i := range d.Pix {
+		angle := math.Atan2(-float64(gy.Pix[i]), float64(gx.Pix[i])) // y grows downwards
+		if angle < 0 {
+			angle += 2 * math.Pi
+		}
+		d.Pix[i] = float32(angle / (2 * math.Pi) * 256)
+		if d.Pix[i] > 255 {
+			d.Pix[i] = 255
+		}
+	}
+	return processing.GreyImage(d)
+}
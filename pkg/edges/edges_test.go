@@ -0,0 +1,142 @@
+package edges
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// step returns an 8x8 grey image that goes from 0 to 255 between the
+// columns 3 and 4, or between the rows 3 and 4 if vertical.
+func step(vertical bool) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if (!vertical && x >= 4) || (vertical && y >= 4) {
+				img.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+	return img
+}
+
+// square returns a black image with a white square from 8 to 16.
+func square() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 24, 24))
+	for y := 8; y < 16; y++ {
+		for x := 8; x < 16; x++ {
+			img.SetGray(x, y, color.Gray{Y: 255})
+		}
+	}
+	return img
+}
+
+func TestGradient(t *testing.T) {
+	for _, op := range []Operator{Sobel, Prewitt, Scharr} {
+		for _, vertical := range []bool{false, true} {
+			gx, gy := Gradient(step(vertical), op, processing.PAL)
+			along, across := gx, gy // The derivative across the step and the one along it
+			if vertical {
+				along, across = gy, gx
+			}
+			for y := 0; y < 8; y++ {
+				for x := 0; x < 8; x++ {
+					i := x
+					if vertical {
+						i = y
+					}
+					want := float32(0)
+					if i == 3 || i == 4 {
+						want = 255
+					}
+					if math.Abs(float64(along.At(x, y)-want)) > 0.01 || math.Abs(float64(across.At(x, y))) > 0.01 {
+						t.Errorf("%v, vertical %v: the derivatives at %v,%v are %v and %v, want %v and 0",
+							op, vertical, x, y, along.At(x, y), across.At(x, y), want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestDirection(t *testing.T) {
+	inverted := step(false)
+	for i := range inverted.Pix {
+		inverted.Pix[i] = 255 - inverted.Pix[i]
+	}
+	tests := []struct {
+		name string
+		img  image.Image
+		want uint8
+	}{
+		{"brighter to the right", step(false), 0},
+		{"brighter to the left", inverted, 128},
+		{"brighter downwards", step(true), 192}, // 270 degrees, y grows downwards
+	}
+	for _, test := range tests {
+		direction := Direction(test.img, Sobel, processing.PAL).(*image.Gray)
+		if got := direction.GrayAt(4, 4).Y; got != test.want {
+			t.Errorf("%v: direction %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestCanny(t *testing.T) {
+	result, err := Canny(square(), 1, 20, 50, processing.PAL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edge := func(x, y int) bool {
+		r, _, _, _ := result.At(x, y).RGBA()
+		return r != 0
+	}
+	for y := 0; y < 24; y++ {
+		for x := 0; x < 24; x++ {
+			if !edge(x, y) {
+				continue
+			}
+			// The edges are one pixel away from the border of the square at most
+			inside := x >= 9 && x < 15 && y >= 9 && y < 15
+			outside := x < 7 || x >= 17 || y < 7 || y >= 17
+			if inside || outside {
+				t.Errorf("edge at %v,%v", x, y)
+			}
+		}
+	}
+	for i := 10; i < 14; i++ { // Away from the corners, which are thinned
+		if !(edge(7, i) || edge(8, i)) || !(edge(15, i) || edge(16, i)) || !(edge(i, 7) || edge(i, 8)) || !(edge(i, 15) || edge(i, 16)) {
+			t.Errorf("the sides of the square have no edge at %v", i)
+		}
+	}
+	flat, _ := Canny(image.NewGray(image.Rect(0, 0, 8, 8)), 1, 20, 50, processing.PAL)
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if r, _, _, _ := flat.At(x, y).RGBA(); r != 0 {
+				t.Fatalf("edge at %v,%v of a flat image", x, y)
+			}
+		}
+	}
+}
+
+func TestValidateCanny(t *testing.T) {
+	tests := []struct {
+		sigma, low, high float64
+		valid            bool
+	}{
+		{1.4, 20, 50, true},
+		{0, 0, 255, true},
+		{-1, 20, 50, false},
+		{60, 20, 50, false},
+		{1, 50, 20, false},
+		{1, -1, 20, false},
+		{1, 20, 300, false},
+	}
+	for _, test := range tests {
+		if err := ValidateCanny(test.sigma, test.low, test.high); (err == nil) != test.valid {
+			t.Errorf("sigma %v, thresholds %v and %v: error %v", test.sigma, test.low, test.high, err)
+		}
+	}
+}
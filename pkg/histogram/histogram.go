@@ -19,3 +19,43 @@ type HistogramNormalized [256]float64
 func (a HistogramNormalized) At(x int) float64 {
 	return (a[x])
 }
+
+// Bins is a histogram with any number of bins spread over the levels
+// [0, 255], finer than Histogram for the samples of 16-bit and float images.
+type Bins []int
+
+func (hist Bins) At(x int) int {
+	return hist[x]
+}
+
+// Level returns the level of the bin x.
+func (hist Bins) Level(x int) float64 {
+	return float64(x) * 255 / float64(len(hist)-1)
+}
+
+func (hist Bins) XY(x int) (a, b float64) {
+	return hist.Level(x), float64(hist.At(x))
+}
+
+func (hist Bins) Len() int {
+	return len(hist)
+}
+
+// Accumulative counts in every bin the samples of the bins before it, like
+// the accumulative histograms of 256 levels.
+func (hist Bins) Accumulative() Bins {
+	accumulative := make(Bins, len(hist))
+	for i := 1; i < len(hist); i++ {
+		accumulative[i] = accumulative[i-1] + hist[i-1]
+	}
+	return accumulative
+}
+
+// Normalized divides the counts by size.
+func (hist Bins) Normalized(size int) []float64 {
+	normalized := make([]float64, len(hist))
+	for i, count := range hist {
+		normalized[i] = float64(count) / float64(size)
+	}
+	return normalized
+}
@@ -0,0 +1,127 @@
+package histogram
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Distribution is the shape of a target of the histogram specification.
+type Distribution int
+
+const (
+	Uniform Distribution = iota
+	Gaussian
+	Exponential
+	Curve // Drawn by the user through points
+)
+
+var distributionNames = []string{"uniform", "gaussian", "exponential", "curve"}
+
+// Distributions returns the names of the distributions.
+func Distributions() []string {
+	return append([]string(nil), distributionNames...)
+}
+
+func ParseDistribution(name string) (Distribution, error) {
+	for i, distributionName := range distributionNames {
+		if strings.EqualFold(name, distributionName) {
+			return Distribution(i), nil
+		}
+	}
+	return 0, fmt.Errorf("the distribution must be one of %v", strings.Join(distributionNames, ", "))
+}
+
+func (d Distribution) String() string {
+	return distributionNames[d]
+}
+
+// Target returns the normalized histogram of the distribution d. The
+// gaussian takes mean and sigma, the exponential mean and the curve points,
+// linearly interpolated between them, whose Y is the relative frequency of
+// the level X. Two points with the same X make a step, the first giving the
+// frequency of X.
+func Target(d Distribution, mean, sigma float64, points []*Point) (target HistogramNormalized, err error) {
+	switch d {
+	case Uniform:
+		for i := range target {
+			target[i] = 1
+		}
+	case Gaussian:
+		if mean < 0 || mean > 255 || sigma <= 0 || sigma > 255 {
+			return target, fmt.Errorf("the mean must be in the range [0, 255] and sigma in (0, 255]")
+		}
+		for i := range target {
+			target[i] = math.Exp(-(float64(i) - mean) * (float64(i) - mean) / (2 * sigma * sigma))
+		}
+	case Exponential:
+		if mean <= 0 || mean > 255 {
+			return target, fmt.Errorf("the mean must be in the range (0, 255]")
+		}
+		for i := range target {
+			target[i] = math.Exp(-float64(i) / mean)
+		}
+	case Curve:
+		if target, err = curve(points); err != nil {
+			return target, err
+		}
+	}
+	return target.normalize()
+}
+
+func curve(points []*Point) (target HistogramNormalized, err error) {
+	if len(points) < 2 {
+		return target, fmt.Errorf("the curve needs at least two points")
+	}
+	sorted := make([]Point, len(points))
+	for i, point := range points {
+		if err := point.Validate(); err != nil {
+			return target, err
+		}
+		sorted[i] = *point
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].X < sorted[j].X }) // Equal X in the order given
+	next := 0
+	for level := range target {
+		for next < len(sorted)-1 && sorted[next+1].X < level {
+			next++
+		}
+		p, q := sorted[next], sorted[len(sorted)-1]
+		if next+1 < len(sorted) {
+			q = sorted[next+1]
+		}
+		switch {
+		case level <= p.X || p.X == q.X: // Before the first point or between equal ones
+			target[level] = float64(p.Y)
+		case level >= q.X:
+			target[level] = float64(q.Y)
+		default:
+			target[level] = float64(p.Y) + float64(q.Y-p.Y)*float64(level-p.X)/float64(q.X-p.X)
+		}
+	}
+	return target, nil
+}
+
+func (target HistogramNormalized) normalize() (HistogramNormalized, error) {
+	var sum float64
+	for _, value := range target {
+		sum += value
+	}
+	if sum == 0 {
+		return target, fmt.Errorf("the target distribution is empty")
+	}
+	for i := range target {
+		target[i] /= sum
+	}
+	return target, nil
+}
+
+// Accumulative returns, for every level, the frequency of the levels below
+// it, like the accumulative histograms of the images.
+func (hist HistogramNormalized) Accumulative() (accumulative HistogramNormalized) {
+	for i := 1; i < len(hist); i++ {
+		accumulative[i] = accumulative[i-1] + hist[i-1]
+	}
+	return accumulative
+}
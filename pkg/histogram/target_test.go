@@ -0,0 +1,100 @@
+package histogram
+
+import (
+	"math"
+	"testing"
+)
+
+func points(xy ...int) (points []*Point) {
+	for i := 0; i < len(xy); i += 2 {
+		points = append(points, &Point{X: xy[i], Y: xy[i+1]})
+	}
+	return points
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestTarget(t *testing.T) {
+	tests := []struct {
+		name        string
+		d           Distribution
+		mean, sigma float64
+		points      []*Point
+		check       func(target HistogramNormalized) bool
+	}{
+		{"uniform", Uniform, 0, 0, nil, func(target HistogramNormalized) bool {
+			return near(target[0], 1.0/256) && near(target[255], 1.0/256)
+		}},
+		{"gaussian", Gaussian, 128, 10, nil, func(target HistogramNormalized) bool {
+			return near(target[118], target[138]) && near(target[138]/target[128], math.Exp(-0.5)) && target[128] > target[127]
+		}},
+		{"exponential", Exponential, 50, 0, nil, func(target HistogramNormalized) bool {
+			return near(target[50]/target[0], math.Exp(-1)) && near(target[150]/target[100], math.Exp(-1))
+		}},
+		{"ramp", Curve, 0, 0, points(0, 0, 255, 255), func(target HistogramNormalized) bool {
+			return target[0] == 0 && near(target[100]/target[50], 2) && near(target[255]/target[51], 5)
+		}},
+		{"flat ends", Curve, 0, 0, points(150, 100, 50, 100), func(target HistogramNormalized) bool {
+			return near(target[0], 1.0/256) && near(target[255], 1.0/256)
+		}},
+		{"interpolation", Curve, 0, 0, points(0, 10, 200, 10, 100, 50), func(target HistogramNormalized) bool {
+			return near(target[50]/target[0], 3) && near(target[150]/target[0], 3) && near(target[255], target[200])
+		}},
+		{"step", Curve, 0, 0, points(0, 10, 100, 10, 100, 50, 255, 50), func(target HistogramNormalized) bool {
+			return near(target[100], target[99]) && near(target[101]/target[100], 5) && near(target[255], target[101])
+		}},
+		{"step given in another order", Curve, 0, 0, points(255, 50, 100, 10, 0, 10, 100, 50), func(target HistogramNormalized) bool {
+			return near(target[100], target[99]) && near(target[101]/target[100], 5)
+		}},
+	}
+	for _, test := range tests {
+		target, err := Target(test.d, test.mean, test.sigma, test.points)
+		if err != nil {
+			t.Errorf("%v: %v", test.name, err)
+			continue
+		}
+		var sum float64
+		for _, value := range target {
+			sum += value
+		}
+		if !near(sum, 1) {
+			t.Errorf("%v: the frequencies add up to %v", test.name, sum)
+		}
+		if !test.check(target) {
+			t.Errorf("%v: wrong shape %v", test.name, target)
+		}
+	}
+}
+
+func TestTargetErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		d           Distribution
+		mean, sigma float64
+		points      []*Point
+	}{
+		{"no sigma", Gaussian, 128, 0, nil},
+		{"gaussian mean", Gaussian, 300, 10, nil},
+		{"exponential mean", Exponential, 0, 0, nil},
+		{"one point", Curve, 0, 0, points(10, 10)},
+		{"point out of range", Curve, 0, 0, points(0, 10, 300, 10)},
+		{"empty curve", Curve, 0, 0, points(0, 0, 255, 0)},
+	}
+	for _, test := range tests {
+		if _, err := Target(test.d, test.mean, test.sigma, test.points); err == nil {
+			t.Errorf("%v: no error", test.name)
+		}
+	}
+}
+
+func TestAccumulative(t *testing.T) {
+	target, _ := Target(Uniform, 0, 0, nil)
+	accumulative := target.Accumulative()
+	for _, level := range []int{0, 1, 128, 255} {
+		if want := float64(level) / 256; !near(accumulative[level], want) {
+			t.Errorf("level %v: %v, want %v", level, accumulative[level], want)
+		}
+	}
+}
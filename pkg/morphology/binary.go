@@ -0,0 +1,81 @@
+package morphology
+
+import (
+	"image"
+
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// The binary operations take as foreground the pixels whose grey level, with
+// the weights l, is at least 128 and return white foreground over black.
+
+// HitOrMiss marks the pixels where the foreground cells of e are over
+// foreground and its background cells over background.
+func HitOrMiss(img image.Image, e Element, l processing.Luminance) image.Image {
+	a := binarize(img, l)
+	hits := minimum(a, e.runs(Foreground))
+	if misses := e.runs(Background); len(misses) > 0 {
+		hits = intersect(hits, minimum(complement(a), misses))
+	}
+	return processing.GreyImage(hits)
+}
+
+// Skeleton returns the morphological skeleton of the foreground (Lantuéjoul):
+// the union over the successive erosions by e of what their opening by e
+// removes, from which the foreground can be rebuilt.
+func Skeleton(img image.Image, e Element, l processing.Luminance) image.Image {
+	current := binarize(img, l)
+	skeleton := processing.NewPlane(current.Width, current.Height)
+	for {
+		eroded := erode(current, e)
+		opened := dilate(eroded, e)
+		changed, empty := false, true
+		for i, value := range current.Pix {
+			if value > opened.Pix[i] {
+				skeleton.Pix[i] = 255
+			}
+			if eroded.Pix[i] != value {
+				changed = true
+			}
+			if eroded.Pix[i] != 0 {
+				empty = false
+			}
+		}
+		if empty || !changed { // An element of just the centre never erodes
+			break
+		}
+		current = eroded
+	}
+	return processing.GreyImage(skeleton)
+}
+
+func binarize(img image.Image, l processing.Luminance) *processing.Plane {
+	grey := processing.LuminancePlane(img, l)
+	for i, value := range grey.Pix {
+		if value >= 128 {
+			grey.Pix[i] = 255
+		} else {
+			grey.Pix[i] = 0
+		}
+	}
+	return grey
+}
+
+func complement(p *processing.Plane) *processing.Plane {
+	result := processing.NewPlane(p.Width, p.Height)
+	for i, value := range p.Pix {
+		result.Pix[i] = 255 - value
+	}
+	return result
+}
+
+func intersect(a, b *processing.Plane) *processing.Plane {
+	result := processing.NewPlane(a.Width, a.Height)
+	for i := range result.Pix {
+		result.Pix[i] = a.Pix[i]
+		if b.Pix[i] < result.Pix[i] {
+			result.Pix[i] = b.Pix[i]
+		}
+	}
+	return result
+}
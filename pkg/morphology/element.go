@@ -0,0 +1,175 @@
+// Package morphology implements the operations of mathematical morphology
+// with flat structuring elements. Grey images take the minimum and the
+// maximum of the neighbourhood, so binary images are just a particular case.
+package morphology
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Values of the cells of a structuring element.
+const (
+	DontCare   = -1 // Only for hit-or-miss
+	Background = 0  // Not part of the element, must be background in hit-or-miss
+	Foreground = 1
+)
+
+// Shape is a predefined structuring element.
+type Shape int
+
+const (
+	Square Shape = iota
+	Cross
+	Disk
+)
+
+var shapeNames = []string{"square", "cross", "disk"}
+
+// Shapes returns the names of the shapes.
+func Shapes() []string {
+	return append([]string(nil), shapeNames...)
+}
+
+func ParseShape(name string) (Shape, error) {
+	for i, shapeName := range shapeNames {
+		if strings.EqualFold(name, shapeName) {
+			return Shape(i), nil
+		}
+	}
+	return 0, fmt.Errorf("the shape must be one of %v", strings.Join(shapeNames, ", "))
+}
+
+func (shape Shape) String() string {
+	return shapeNames[shape]
+}
+
+// Element is a Width x Height structuring element centred on its middle
+// cell.
+type Element struct {
+	Width, Height int
+	Values        []int // Row major, Foreground, Background or DontCare
+}
+
+// New returns the size x size element of the given shape, size being odd.
+func New(shape Shape, size int) (Element, error) {
+	if size < 1 || size > 101 || size%2 == 0 {
+		return Element{}, fmt.Errorf("the size of the structuring element must be an odd number from 1 to 101")
+	}
+	e := Element{Width: size, Height: size, Values: make([]int, size*size)}
+	radius := size / 2
+	for y := -radius; y <= radius; y++ {
+		for x := -radius; x <= radius; x++ {
+			inside := true
+			switch shape {
+			case Cross:
+				inside = x == 0 || y == 0
+			case Disk:
+				inside = x*x+y*y <= radius*radius+radius // Rounder than the strict circle for small sizes
+			}
+			if inside {
+				e.Values[(y+radius)*size+x+radius] = Foreground
+			}
+		}
+	}
+	return e, nil
+}
+
+// Parse reads an element written by rows separated by ";" or new lines with
+// the cells separated by "," or spaces: 1 is foreground, 0 background and x
+// (or -1) don't care, e.g. "0,1,0; 1,1,1; 0,1,0".
+func Parse(text string) (Element, error) {
+	var e Element
+	lines := strings.FieldsFunc(text, func(r rune) bool { return r == ';' || r == '\n' })
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+		if e.Height == 0 {
+			e.Width = len(fields)
+		} else if len(fields) != e.Width {
+			return Element{}, fmt.Errorf("row %v of the structuring element has %v cells instead of %v", e.Height+1, len(fields), e.Width)
+		}
+		for _, field := range fields {
+			switch strings.ToLower(field) {
+			case "1":
+				e.Values = append(e.Values, Foreground)
+			case "0":
+				e.Values = append(e.Values, Background)
+			case "x", "-1":
+				e.Values = append(e.Values, DontCare)
+			default:
+				return Element{}, fmt.Errorf("%q is not 1, 0 or x", field)
+			}
+		}
+		e.Height++
+	}
+	if e.Height == 0 || e.Width == 0 {
+		return Element{}, fmt.Errorf("the structuring element is empty")
+	}
+	if e.Width%2 == 0 || e.Height%2 == 0 {
+		return Element{}, fmt.Errorf("the structuring element must have an odd number of rows and columns, it is %vx%v", e.Width, e.Height)
+	}
+	if len(e.runs(Foreground)) == 0 {
+		return Element{}, fmt.Errorf("the structuring element has no foreground cell")
+	}
+	return e, nil
+}
+
+func (e Element) At(x, y int) int {
+	return e.Values[y*e.Width+x]
+}
+
+// String writes e in the format of Parse.
+func (e Element) String() string {
+	rows := make([]string, e.Height)
+	for y := range rows {
+		cells := make([]string, e.Width)
+		for x := range cells {
+			switch e.At(x, y) {
+			case Foreground:
+				cells[x] = "1"
+			case Background:
+				cells[x] = "0"
+			default:
+				cells[x] = "x"
+			}
+		}
+		rows[y] = strings.Join(cells, ",")
+	}
+	return strings.Join(rows, ";")
+}
+
+// reflect returns e rotated 180 degrees, used by the dilation.
+func (e Element) reflect() Element {
+	reflected := Element{Width: e.Width, Height: e.Height, Values: make([]int, len(e.Values))}
+	for i, value := range e.Values {
+		reflected.Values[len(e.Values)-1-i] = value
+	}
+	return reflected
+}
+
+// run is a horizontal segment of cells of an element, as offsets from its
+// centre.
+type run struct {
+	dy, from, to int
+}
+
+// runs returns the horizontal segments of the cells with the given value.
+func (e Element) runs(value int) []run {
+	var runs []run
+	for y := 0; y < e.Height; y++ {
+		for x := 0; x < e.Width; x++ {
+			if e.At(x, y) != value {
+				continue
+			}
+			start := x
+			for x+1 < e.Width && e.At(x+1, y) == value {
+				x++
+			}
+			runs = append(runs, run{dy: y - e.Height/2, from: start - e.Width/2, to: x - e.Width/2})
+		}
+	}
+	return runs
+}
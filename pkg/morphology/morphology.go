@@ -0,0 +1,179 @@
+package morphology
+
+import (
+	"image"
+
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// Erode replaces every sample of the colour channels by the minimum of the
+// ones under the foreground cells of e, shrinking the bright areas.
+func Erode(img image.Image, e Element) image.Image {
+	return perChannel(img, func(p *processing.Plane) *processing.Plane {
+		return erode(p, e)
+	})
+}
+
+// Dilate replaces every sample of the colour channels by the maximum of the
+// ones under the reflected foreground cells of e, growing the bright areas.
+func Dilate(img image.Image, e Element) image.Image {
+	return perChannel(img, func(p *processing.Plane) *processing.Plane {
+		return dilate(p, e)
+	})
+}
+
+// Open is an erosion followed by a dilation, removing the bright details
+// smaller than e.
+func Open(img image.Image, e Element) image.Image {
+	return perChannel(img, func(p *processing.Plane) *processing.Plane {
+		return dilate(erode(p, e), e)
+	})
+}
+
+// Close is a dilation followed by an erosion, filling the dark details
+// smaller than e.
+func Close(img image.Image, e Element) image.Image {
+	return perChannel(img, func(p *processing.Plane) *processing.Plane {
+		return erode(dilate(p, e), e)
+	})
+}
+
+// Gradient is the dilation minus the erosion, the outlines of the areas.
+func Gradient(img image.Image, e Element) image.Image {
+	return perChannel(img, func(p *processing.Plane) *processing.Plane {
+		return subtract(dilate(p, e), erode(p, e))
+	})
+}
+
+// TopHat is the image minus its opening, the bright details smaller than e.
+func TopHat(img image.Image, e Element) image.Image {
+	return perChannel(img, func(p *processing.Plane) *processing.Plane {
+		return subtract(p, dilate(erode(p, e), e))
+	})
+}
+
+// BlackHat is the closing minus the image, the dark details smaller than e.
+func BlackHat(img image.Image, e Element) image.Image {
+	return perChannel(img, func(p *processing.Plane) *processing.Plane {
+		return subtract(erode(dilate(p, e), e), p)
+	})
+}
+
+// perChannel runs op on the red, green and blue planes of img.
+func perChannel(img image.Image, op func(*processing.Plane) *processing.Plane) image.Image {
+	planes := processing.Planes(img)
+	for i := 0; i < 3; i++ {
+		planes[i] = op(planes[i])
+	}
+	return processing.FromPlanesLike(planes, img)
+}
+
+func subtract(a, b *processing.Plane) *processing.Plane {
+	result := processing.NewPlane(a.Width, a.Height)
+	for i := range result.Pix {
+		result.Pix[i] = a.Pix[i] - b.Pix[i]
+	}
+	return result
+}
+
+func erode(p *processing.Plane, e Element) *processing.Plane {
+	return minimum(p, e.runs(Foreground))
+}
+
+// dilate is the complement of the erosion of the complement by the
+// reflected element.
+func dilate(p *processing.Plane, e Element) *processing.Plane {
+	return complement(minimum(complement(p), e.reflect().runs(Foreground)))
+}
+
+// minimum returns the minimum of the samples of p under runs. The minimum of
+// every different horizontal segment is computed once for the whole plane
+// with the van Herk/Gil-Werman algorithm, which costs the same whatever the
+// length, and then the segments of every row are joined. The samples outside
+// of p are ignored.
+func minimum(p *processing.Plane, runs []run) *processing.Plane {
+	type segment struct{ from, to int }
+	horizontal := make(map[segment]*processing.Plane)
+	for _, r := range runs {
+		s := segment{r.from, r.to}
+		if horizontal[s] == nil {
+			horizontal[s] = minimumRows(p, s.from, s.to)
+		}
+	}
+	w, h := p.Width, p.Height
+	result := processing.NewPlane(w, h)
+	processing.ParallelRows(h, func(y int) {
+		out := result.Pix[y*w : (y+1)*w]
+		for x := range out {
+			out[x] = 255
+		}
+		for _, r := range runs {
+			if y+r.dy < 0 || y+r.dy >= h {
+				continue
+			}
+			in := horizontal[segment{r.from, r.to}].Pix[(y+r.dy)*w : (y+r.dy+1)*w]
+			for x, value := range in {
+				if value < out[x] {
+					out[x] = value
+				}
+			}
+		}
+	})
+	return result
+}
+
+// minimumRows returns the minimum of the samples from x+from to x+to of every
+// row, with blocks of the length of the window whose prefix and suffix
+// minimums give any window as the minimum of two values.
+func minimumRows(p *processing.Plane, from, to int) *processing.Plane {
+	w, h := p.Width, p.Height
+	length := to - from + 1
+	result := processing.NewPlane(w, h)
+	if length == 1 {
+		processing.ParallelRows(h, func(y int) {
+			for x := 0; x < w; x++ {
+				result.Pix[y*w+x] = 255
+				if x+from >= 0 && x+from < w {
+					result.Pix[y*w+x] = p.Pix[y*w+x+from]
+				}
+			}
+		})
+		return result
+	}
+	processing.ParallelRows(h, func(y int) {
+		row := p.Pix[y*w : (y+1)*w]
+		n := w + length - 1
+		prefix, suffix := make([]float32, n), make([]float32, n)
+		for j := range prefix {
+			prefix[j] = 255
+			if x := j + from; x >= 0 && x < w {
+				prefix[j] = row[x]
+			}
+			suffix[j] = prefix[j]
+		}
+		for start := 0; start < n; start += length {
+			end := start + length
+			if end > n {
+				end = n
+			}
+			for j := start + 1; j < end; j++ {
+				if prefix[j-1] < prefix[j] {
+					prefix[j] = prefix[j-1]
+				}
+			}
+			for j := end - 2; j >= start; j-- {
+				if suffix[j+1] < suffix[j] {
+					suffix[j] = suffix[j+1]
+				}
+			}
+		}
+		out := result.Pix[y*w : (y+1)*w]
+		for x := range out {
+			out[x] = suffix[x]
+			if prefix[x+length-1] < out[x] {
+				out[x] = prefix[x+length-1]
+			}
+		}
+	})
+	return result
+}
@@ -0,0 +1,178 @@
+package morphology
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// ramp returns a plane of grey levels that change from pixel to pixel.
+func ramp(width, height int) *processing.Plane {
+	p := processing.NewPlane(width, height)
+	for i := range p.Pix {
+		p.Pix[i] = float32(i * i * 31 % 256)
+	}
+	return p
+}
+
+func elements(t *testing.T) map[string]Element {
+	result := make(map[string]Element)
+	for _, shape := range []Shape{Square, Cross, Disk} {
+		for _, size := range []int{1, 3, 5} {
+			e, err := New(shape, size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result[e.String()] = e
+		}
+	}
+	e, err := Parse("1,1,0; 0,1,0; 0,0,0") // Not symmetric
+	if err != nil {
+		t.Fatal(err)
+	}
+	result[e.String()] = e
+	return result
+}
+
+// slowMinimum takes the minimum of the samples of p under the foreground
+// cells of e, the ones outside of p being ignored.
+func slowMinimum(p *processing.Plane, e Element) *processing.Plane {
+	result := processing.NewPlane(p.Width, p.Height)
+	for y := 0; y < p.Height; y++ {
+		for x := 0; x < p.Width; x++ {
+			min := float32(255)
+			for j := 0; j < e.Height; j++ {
+				for i := 0; i < e.Width; i++ {
+					sx, sy := x+i-e.Width/2, y+j-e.Height/2
+					if e.At(i, j) != Foreground || sx < 0 || sy < 0 || sx >= p.Width || sy >= p.Height {
+						continue
+					}
+					if value := p.At(sx, sy); value < min {
+						min = value
+					}
+				}
+			}
+			result.Set(x, y, min)
+		}
+	}
+	return result
+}
+
+func equal(a, b *processing.Plane) bool {
+	for i := range a.Pix {
+		if a.Pix[i] != b.Pix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// lessOrEqual says if every sample of a is not above the one of b.
+func lessOrEqual(a, b *processing.Plane) bool {
+	for i := range a.Pix {
+		if a.Pix[i] > b.Pix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestErodeAndDilate(t *testing.T) {
+	p := ramp(13, 11)
+	for name, e := range elements(t) {
+		if !equal(erode(p, e), slowMinimum(p, e)) {
+			t.Errorf("%v: the erosion isn't the minimum of the neighbourhood", name)
+		}
+		// The dilation is the maximum under the reflected element
+		if !equal(dilate(p, e), complement(slowMinimum(complement(p), e.reflect()))) {
+			t.Errorf("%v: the dilation isn't the maximum of the neighbourhood", name)
+		}
+	}
+}
+
+// TestIdentities checks the properties of the operations that every element
+// with its centre has.
+func TestIdentities(t *testing.T) {
+	p := ramp(13, 11)
+	for name, e := range elements(t) {
+		eroded, dilated := erode(p, e), dilate(p, e)
+		opened, closed := dilate(eroded, e), erode(dilated, e)
+		if !lessOrEqual(eroded, p) || !lessOrEqual(p, dilated) {
+			t.Errorf("%v: the erosion or the dilation are on the wrong side of the image", name)
+		}
+		if !lessOrEqual(opened, p) || !lessOrEqual(p, closed) {
+			t.Errorf("%v: the opening or the closing are on the wrong side of the image", name)
+		}
+		if !equal(dilate(erode(opened, e), e), opened) {
+			t.Errorf("%v: the opening isn't idempotent", name)
+		}
+		if !equal(erode(dilate(closed, e), e), closed) {
+			t.Errorf("%v: the closing isn't idempotent", name)
+		}
+		if e.Width == 1 && !equal(eroded, p) {
+			t.Errorf("%v: the erosion by the centre changes the image", name)
+		}
+	}
+}
+
+// dot returns a black image with white pixels at points.
+func dot(width, height int, points ...image.Point) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for _, point := range points {
+		img.SetGray(point.X, point.Y, color.Gray{Y: 255})
+	}
+	return img
+}
+
+func TestHitOrMiss(t *testing.T) {
+	img := dot(7, 7, image.Pt(1, 1), image.Pt(4, 4), image.Pt(5, 4)) // An isolated pixel and a pair
+	isolated, _ := Parse("0,0,0; 0,1,0; 0,0,0")
+	result := HitOrMiss(img, isolated, processing.PAL)
+	for y := 0; y < 7; y++ {
+		for x := 0; x < 7; x++ {
+			r, _, _, _ := result.At(x, y).RGBA()
+			if hit := r != 0; hit != (x == 1 && y == 1) {
+				t.Errorf("pixel %v,%v hit %v", x, y, hit)
+			}
+		}
+	}
+}
+
+func TestSkeleton(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 11, 7))
+	for y := 2; y < 5; y++ {
+		for x := 1; x < 10; x++ {
+			img.SetGray(x, y, color.Gray{Y: 255})
+		}
+	}
+	square, _ := New(Square, 3)
+	result := Skeleton(img, square, processing.PAL)
+	for y := 0; y < 7; y++ {
+		for x := 0; x < 11; x++ {
+			r, _, _, _ := result.At(x, y).RGBA()
+			if r != 0 && y != 3 {
+				t.Errorf("the skeleton of a bar 3 pixels thick has pixel %v,%v out of its middle row", x, y)
+			}
+		}
+	}
+	if r, _, _, _ := result.At(5, 3).RGBA(); r == 0 {
+		t.Error("the skeleton misses the middle of the bar")
+	}
+}
+
+func TestParse(t *testing.T) {
+	e, err := Parse("x,1,x\n1 1 1;x,1,x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.String() != "x,1,x;1,1,1;x,1,x" {
+		t.Errorf("got %v", e)
+	}
+	for _, text := range []string{"", "1,1", "1,2,1", "1,1,1;1,1", "0,0,0;0,0,0;0,0,0"} {
+		if _, err := Parse(text); err == nil {
+			t.Errorf("%q: no error", text)
+		}
+	}
+}
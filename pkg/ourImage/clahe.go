@@ -0,0 +1,15 @@
+package ourimage
+
+import (
+	"github.com/vision-go/vision-go/pkg/clahe"
+	"github.com/vision-go/vision-go/pkg/pipeline"
+)
+
+func (originalImg *OurImage) CLAHE(columns, rows int, clipLimit float64, mode clahe.Mode) (*OurImage, error) {
+	NewImage, err := clahe.Equalize(originalImg.input(), columns, rows, clipLimit, mode)
+	if err != nil {
+		return nil, err
+	}
+	return originalImg.newFromInput(NewImage, pipeline.CLAHESuffix,
+		step("clahe", map[string]interface{}{"columns": columns, "rows": rows, "clip": clipLimit, "mode": mode.String()})), nil
+}
@@ -0,0 +1,84 @@
+package ourimage
+
+import (
+	"image"
+	"strings"
+
+	"github.com/vision-go/vision-go/pkg/colorspace"
+	"github.com/vision-go/vision-go/pkg/pipeline"
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// Split returns a grey image for every channel of space, named after it.
+func (originalImg *OurImage) Split(space colorspace.Space) []*OurImage {
+	channels, _ := colorspace.Split(originalImg.canvasImage.Image, space)
+	names := space.Channels()
+	images := make([]*OurImage, len(channels))
+	for i, channel := range channels {
+		images[i] = originalImg.newFromImage(processing.GreyImageDepth(channel, processing.DepthOf(originalImg.canvasImage.Image)), strings.ToUpper(names[i]),
+			step("channel", map[string]interface{}{"space": space.String(), "channel": names[i]}))
+	}
+	return images
+}
+
+// Merge joins the grey levels of originalImg and others, in that order, as
+// the channels of space.
+func (originalImg *OurImage) Merge(space colorspace.Space, others ...*OurImage) (*OurImage, error) {
+	channels := []*processing.Plane{processing.LuminancePlane(originalImg.canvasImage.Image, originalImg.luminance)}
+	sources := make([]string, len(others))
+	var why []string
+	for i, other := range others {
+		channels = append(channels, processing.LuminancePlane(other.canvasImage.Image, originalImg.luminance))
+		sources[i] = other.provenance.Source
+		if reason := unsaved(other); reason != "" {
+			why = append(why, reason)
+		}
+	}
+	NewImage, err := colorspace.MergeDepth(space, channels, nil, processing.DepthOf(originalImg.canvasImage.Image))
+	if err != nil {
+		return nil, err
+	}
+	s := step("merge", map[string]interface{}{"space": space.String(), "channels": strings.Join(sources, ",")})
+	s.Unreplayable = strings.Join(why, ", ")
+	return originalImg.newFromImage(NewImage, pipeline.MergeSuffix, s), nil
+}
+
+// OnChannel applies op to one channel of space. op gets the channel as a
+// grey image with the same selection, and the steps it records are given
+// the channel.
+func (originalImg *OurImage) OnChannel(space colorspace.Space, channel int, op func(*OurImage) *OurImage) (*OurImage, error) {
+	grey := originalImg.channelImage(space, channel)
+	result := op(grey)
+	NewImage, err := colorspace.OnChannel(originalImg.canvasImage.Image, space, channel, func(image.Image) (image.Image, error) {
+		return result.canvasImage.Image, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	name := space.Channels()[channel]
+	var steps []pipeline.Step
+	for _, record := range result.provenance.Operations[len(grey.provenance.Operations):] {
+		params := map[string]interface{}{"space": space.String(), "channel": name}
+		for key, value := range record.Params {
+			params[key] = value
+		}
+		s := record.Step
+		s.Params = params
+		steps = append(steps, s)
+	}
+	img := originalImg.newFromImage(NewImage, result.action+" "+strings.ToUpper(name), steps...)
+	img.selection = originalImg.selection
+	return img, nil
+}
+
+// ChannelStatistics are the statistics of the selected pixels of one channel
+// of space.
+func (originalImg *OurImage) ChannelStatistics(space colorspace.Space, channel int) processing.Statistics {
+	return originalImg.channelImage(space, channel).SelectionStatistics()
+}
+
+func (originalImg *OurImage) channelImage(space colorspace.Space, channel int) *OurImage {
+	grey := originalImg.newFromImage(colorspace.Channel(originalImg.canvasImage.Image, space, channel), "")
+	grey.selection = originalImg.selection
+	return grey
+}
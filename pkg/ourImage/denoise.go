@@ -0,0 +1,32 @@
+package ourimage
+
+import (
+	"github.com/vision-go/vision-go/pkg/denoise"
+	"github.com/vision-go/vision-go/pkg/pipeline"
+)
+
+func (originalImg *OurImage) Median(size int, mode denoise.Mode) (*OurImage, error) {
+	NewImage, err := denoise.Median(originalImg.input(), size, mode)
+	if err != nil {
+		return nil, err
+	}
+	return originalImg.newFromInput(NewImage, pipeline.MedianSuffix, step("median", map[string]interface{}{"size": size, "mode": mode.String()})), nil
+}
+
+func (originalImg *OurImage) Bilateral(sigmaSpace, sigmaRange float64, mode denoise.Mode) (*OurImage, error) {
+	NewImage, err := denoise.Bilateral(originalImg.input(), sigmaSpace, sigmaRange, mode)
+	if err != nil {
+		return nil, err
+	}
+	return originalImg.newFromInput(NewImage, pipeline.BilateralSuffix,
+		step("bilateral", map[string]interface{}{"sigma-space": sigmaSpace, "sigma-range": sigmaRange, "mode": mode.String()})), nil
+}
+
+func (originalImg *OurImage) NonLocalMeans(h float64, patch, search int, mode denoise.Mode) (*OurImage, error) {
+	NewImage, err := denoise.NonLocalMeans(originalImg.input(), h, patch, search, mode)
+	if err != nil {
+		return nil, err
+	}
+	return originalImg.newFromInput(NewImage, pipeline.NLMeansSuffix,
+		step("nlmeans", map[string]interface{}{"strength": h, "patch": patch, "search": search, "mode": mode.String()})), nil
+}
@@ -0,0 +1,35 @@
+package ourimage
+
+import (
+	"image"
+
+	"github.com/vision-go/vision-go/pkg/edges"
+	"github.com/vision-go/vision-go/pkg/pipeline"
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+func (originalImg *OurImage) GradientMagnitude(op edges.Operator) *OurImage {
+	l := originalImg.luminance
+	return originalImg.newFromInput(edges.Magnitude(originalImg.input(), op, l), pipeline.GradientSuffix,
+		step("gradient", map[string]interface{}{"operator": op.String(), "output": "magnitude", "luminance": l.String()}))
+}
+
+func (originalImg *OurImage) GradientDirection(op edges.Operator) *OurImage {
+	l := originalImg.luminance
+	return originalImg.newFromInput(edges.Direction(originalImg.input(), op, l), pipeline.GradientSuffix,
+		step("gradient", map[string]interface{}{"operator": op.String(), "output": "direction", "luminance": l.String()}))
+}
+
+func (originalImg *OurImage) Canny(sigma, low, high float64) (*OurImage, error) {
+	l := originalImg.luminance
+	NewImage, err := CannyPreview(originalImg.input(), sigma, low, high, l)
+	if err != nil {
+		return nil, err
+	}
+	return originalImg.newFromInput(NewImage, pipeline.CannySuffix,
+		step("canny", map[string]interface{}{"sigma": sigma, "low": low, "high": high, "luminance": l.String()})), nil
+}
+
+func CannyPreview(img image.Image, sigma, low, high float64, l processing.Luminance) (image.Image, error) {
+	return edges.Canny(img, sigma, low, high, l)
+}
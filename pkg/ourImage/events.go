@@ -1,8 +1,6 @@
 package ourimage
 
 import (
-	"image"
-	"math"
 	"strconv"
 
 	"fyne.io/fyne/v2"
@@ -17,20 +15,46 @@ const (
 )
 
 func (ourimage *OurImage) MouseIn(mouse *desktop.MouseEvent) {
-	if ourimage.statusBar != nil {
-		r, g, b, a := ourimage.canvasImage.Image.At(int(mouse.Position.X), int(mouse.Position.Y)).RGBA()
-		ourimage.statusBar.SetText("x=" + strconv.Itoa(int(math.Round(float64(mouse.Position.X)))) + ", y=" + strconv.Itoa(int(math.Round(float64(mouse.Position.Y)))) +
-			", R: " + strconv.Itoa(int(r>>8)) + " || G: " + strconv.Itoa(int(g>>8)) + " || B: " + strconv.Itoa(int(b>>8)) + " || A: " + strconv.Itoa(int(a>>8)))
-	}
+	ourimage.showPixel(mouse.Position)
 }
 
 // MouseMoved is a hook that is called if the mouse pointer moved over the element.
 func (ourimage *OurImage) MouseMoved(mouse *desktop.MouseEvent) {
-	if ourimage.statusBar != nil {
-		r, g, b, a := ourimage.canvasImage.Image.At(int(mouse.Position.X), int(mouse.Position.Y)).RGBA()
-		ourimage.statusBar.SetText("x=" + strconv.Itoa(int(math.Round(float64(mouse.Position.X)))) + ", y=" + strconv.Itoa(int(math.Round(float64(mouse.Position.Y)))) +
-			", R: " + strconv.Itoa(int(r>>8)) + " || G: " + strconv.Itoa(int(g>>8)) + " || B: " + strconv.Itoa(int(b>>8)) + " || A: " + strconv.Itoa(int(a>>8)))
+	if mouse.Button == desktop.MouseButtonTertiary && ourimage.panning {
+		ourimage.pan(mouse.AbsolutePosition)
+		return
+	}
+	if mouse.Button == desktop.MouseButtonPrimary && ourimage.drag != dragNone {
+		ourimage.dragTo(mouse.Position)
+	}
+	ourimage.showPixel(mouse.Position)
+}
+
+// showPixel writes in the status bar the colour of the image pixel under
+// pos, whatever the zoom is.
+func (ourimage *OurImage) showPixel(pos fyne.Position) {
+	if ourimage.statusBar == nil {
+		return
+	}
+	point := ourimage.toImage(pos)
+	if !point.In(ourimage.canvasImage.Image.Bounds()) {
+		ourimage.statusBar.SetText("")
+		return
 	}
+	r, g, b, a := ourimage.canvasImage.Image.At(point.X, point.Y).RGBA()
+	ourimage.statusBar.SetText("x=" + strconv.Itoa(point.X) + ", y=" + strconv.Itoa(point.Y) +
+		", R: " + strconv.Itoa(int(r>>8)) + " || G: " + strconv.Itoa(int(g>>8)) + " || B: " + strconv.Itoa(int(b>>8)) + " || A: " + strconv.Itoa(int(a>>8)) +
+		" || Zoom: " + formatZoom(ourimage.zoom))
+}
+
+// pan drags the view with the middle button.
+func (ourimage *OurImage) pan(mouse fyne.Position) {
+	if ourimage.scroll == nil {
+		return
+	}
+	delta := mouse.Subtract(ourimage.panStart)
+	ourimage.panStart = mouse
+	ourimage.scrollTo(ourimage.scroll.Offset.Subtract(delta))
 }
 
 // MouseOut is a hook that is called if the mouse pointer leaves the element.
@@ -42,7 +66,8 @@ func (ourimage *OurImage) MouseOut() {
 
 // desktop.Mouseable
 func (ourimage *OurImage) MouseDown(mouseEvent *desktop.MouseEvent) {
-	if mouseEvent.Button == desktop.MouseButtonSecondary {
+	switch mouseEvent.Button {
+	case desktop.MouseButtonSecondary:
 		popUp := widget.NewPopUpMenu(
 			fyne.NewMenu("PopUp",
 				fyne.NewMenuItem("Close tabs to right",
@@ -60,21 +85,38 @@ func (ourimage *OurImage) MouseDown(mouseEvent *desktop.MouseEvent) {
 						ourimage.closeTabsCallback(OtherTabs)
 					},
 				),
+				fyne.NewMenuItemSeparator(),
+				fyne.NewMenuItem("Crop to selection",
+					func() {
+						if cropped, err := ourimage.Crop(); err == nil {
+							ourimage.ROIcallback(cropped)
+						}
+					},
+				),
+				fyne.NewMenuItem("Clear selection", ourimage.ClearSelection),
 			),
 			ourimage.mainWindow.Canvas(),
 		)
 		popUp.ShowAtPosition(mouseEvent.AbsolutePosition)
+	case desktop.MouseButtonTertiary:
+		ourimage.panning = true
+		ourimage.panStart = mouseEvent.AbsolutePosition
+	case desktop.MouseButtonPrimary:
+		ourimage.startDrag(mouseEvent.Position)
 	}
-	ourimage.rectangle.Min = image.Pt(int(math.Round(float64(mouseEvent.Position.X))), int(math.Round(float64(mouseEvent.Position.Y))))
 }
 
 func (ourimage *OurImage) MouseUp(mouseEvent *desktop.MouseEvent) {
-	ourimage.rectangle.Max = image.Pt(int(math.Round(float64(mouseEvent.Position.X))), int(math.Round(float64(mouseEvent.Position.Y))))
-	ourimage.rectangle = ourimage.rectangle.Canon()
-	if ourimage.rectangle.Dx() > 10 && ourimage.rectangle.Dy() > 10 {
-		ourimage.ROIcallback(ourimage.ROI(ourimage.rectangle))
+	if mouseEvent.Button == desktop.MouseButtonTertiary {
+		ourimage.panning = false
+		return
 	}
+	if mouseEvent.Button != desktop.MouseButtonPrimary {
+		return
+	}
+	ourimage.endDrag(mouseEvent.Position)
 }
+
 func (ourimage *OurImage) Cursor() desktop.Cursor {
 	return desktop.CrosshairCursor
 }
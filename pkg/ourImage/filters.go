@@ -0,0 +1,44 @@
+package ourimage
+
+import (
+	"github.com/vision-go/vision-go/pkg/convolution"
+	"github.com/vision-go/vision-go/pkg/pipeline"
+)
+
+func (originalImg *OurImage) Convolve(k convolution.Kernel, border convolution.Border) *OurImage {
+	return originalImg.newFromInput(convolution.Convolve(originalImg.input(), k, border), pipeline.ConvolveSuffix,
+		step("convolve", map[string]interface{}{"kernel": k.String(), "divisor": 1, "offset": k.Offset, "border": border.String()}))
+}
+
+func (originalImg *OurImage) BoxBlur(size int, border convolution.Border) (*OurImage, error) {
+	k, err := convolution.Box(size)
+	if err != nil {
+		return nil, err
+	}
+	return originalImg.newFromInput(convolution.Convolve(originalImg.input(), k, border), pipeline.BoxBlurSuffix,
+		step("box-blur", map[string]interface{}{"size": size, "border": border.String()})), nil
+}
+
+func (originalImg *OurImage) GaussianBlur(sigma float64, border convolution.Border) (*OurImage, error) {
+	k, err := convolution.Gaussian(sigma)
+	if err != nil {
+		return nil, err
+	}
+	return originalImg.newFromInput(convolution.Convolve(originalImg.input(), k, border), pipeline.GaussianBlurSuffix,
+		step("gaussian-blur", map[string]interface{}{"sigma": sigma, "border": border.String()})), nil
+}
+
+func (originalImg *OurImage) Sharpen(border convolution.Border) *OurImage {
+	return originalImg.newFromInput(convolution.Convolve(originalImg.input(), convolution.Sharpen(), border), pipeline.SharpenSuffix,
+		step("sharpen", map[string]interface{}{"border": border.String()}))
+}
+
+func (originalImg *OurImage) Emboss(border convolution.Border) *OurImage {
+	return originalImg.newFromInput(convolution.Convolve(originalImg.input(), convolution.Emboss(), border), pipeline.EmbossSuffix,
+		step("emboss", map[string]interface{}{"border": border.String()}))
+}
+
+func (originalImg *OurImage) Laplacian(border convolution.Border) *OurImage {
+	return originalImg.newFromInput(convolution.Convolve(originalImg.input(), convolution.Laplacian(), border), pipeline.LaplacianSuffix,
+		step("laplacian", map[string]interface{}{"border": border.String()}))
+}
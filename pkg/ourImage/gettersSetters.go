@@ -4,6 +4,9 @@ import (
 	"image"
 
 	"fyne.io/fyne/v2/canvas"
+
+	"github.com/vision-go/vision-go/pkg/pipeline"
+	"github.com/vision-go/vision-go/pkg/processing"
 )
 
 func (img *OurImage) Name() string {
@@ -19,26 +22,52 @@ func (img *OurImage) Dimensions() image.Point {
 }
 
 func (img *OurImage) Brightness() float64 {
-	return img.brightness
+	return img.statistics.Brightness
 }
 
 func (img *OurImage) Contrast() float64 {
-	return img.contrast
+	return img.statistics.Contrast
+}
+
+// ChannelBrightnessAndContrast returns the brightness and the contrast of the
+// red, green and blue channels.
+func (img *OurImage) ChannelBrightnessAndContrast() (brightness, contrast [3]float64) {
+	return img.statistics.ChannelBrightness, img.statistics.ChannelContrast
+}
+
+// Luminance are the weights of the grey levels of the statistics.
+func (img *OurImage) Luminance() processing.Luminance {
+	return img.luminance
+}
+
+// Depth returns the precision of the samples of the image.
+func (img *OurImage) Depth() processing.Depth {
+	return processing.DepthOf(img.canvasImage.Image)
 }
 
 func (img *OurImage) EntropyAndNumberOfColors() (float64, int) {
-	return img.entropy, img.numberOfColors
+	return img.statistics.Entropy, img.statistics.NumberOfColors
 }
 
 func (img *OurImage) CanvasImage() *canvas.Image {
 	return img.canvasImage
 }
 
+// Image returns the pixels shown by the widget.
+func (img *OurImage) Image() image.Image {
+	return img.canvasImage.Image
+}
+
+// Provenance returns the source file and the operations applied to it.
+func (img *OurImage) Provenance() pipeline.Provenance {
+	return img.provenance
+}
+
 func (img *OurImage) MinAndMaxColor() (int, int) {
-	return img.minColor, img.maxColor
+	return img.statistics.MinColor, img.statistics.MaxColor
 }
 
 // [4,8] -> 4, 5, 6, 7, 8 (5)
 func (img *OurImage) Range() int {
-	return img.maxColor - img.minColor + 1
+	return img.statistics.Range()
 }
@@ -0,0 +1,139 @@
+package ourimage
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/vision-go/vision-go/pkg/pipeline"
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// DefaultHistoryLimit is how many snapshots a tab keeps when editing in
+// place unless SetHistoryLimit says otherwise.
+const DefaultHistoryLimit = 20
+
+// state is a snapshot of everything an operation changes.
+type state struct {
+	image      image.Image
+	name       string
+	action     string
+	statistics processing.Statistics
+	luminance  processing.Luminance
+	provenance pipeline.Provenance
+}
+
+// history keeps the snapshots of an image edited in place. states[current]
+// is the one shown; the ones after it can be redone.
+type history struct {
+	states  []state
+	current int
+	limit   int
+}
+
+func (img *OurImage) currentState() state {
+	return state{image: img.canvasImage.Image, name: img.name, action: img.action, statistics: img.statistics,
+		luminance: img.luminance, provenance: img.provenance}
+}
+
+func (img *OurImage) setState(s state) {
+	img.name = s.name
+	img.action = s.action
+	img.statistics = s.statistics
+	img.luminance = s.luminance
+	img.provenance = s.provenance
+	img.Histograms = s.statistics.Histograms
+	img.canvasImage.Image = s.image
+	img.updateSize()
+	if !img.selection.rect.In(s.image.Bounds()) {
+		img.selection = selection{}
+	}
+	img.selectionChanged() // Same selection, other pixels
+}
+
+// Commit replaces the content of img with result, keeping the previous one
+// in the history so it can be undone. Anything that could be redone is lost.
+func (img *OurImage) Commit(result *OurImage) {
+	if img.history == nil {
+		img.history = &history{states: []state{img.currentState()}, limit: DefaultHistoryLimit}
+	}
+	h := img.history
+	h.states = append(h.states[:h.current+1], result.currentState())
+	h.current++
+	h.trim()
+	img.setState(h.states[h.current])
+}
+
+// trim drops the oldest snapshots over the limit.
+func (h *history) trim() {
+	if h.limit <= 0 || len(h.states) <= h.limit {
+		return
+	}
+	excess := len(h.states) - h.limit
+	if excess > h.current {
+		excess = h.current // Never drop the shown one
+	}
+	h.states = append([]state(nil), h.states[excess:]...)
+	h.current -= excess
+}
+
+// SetHistoryLimit changes how many snapshots are retained, at least 2.
+func (img *OurImage) SetHistoryLimit(limit int) error {
+	if limit < 2 {
+		return fmt.Errorf("the history must keep at least 2 snapshots")
+	}
+	if img.history == nil {
+		img.history = &history{states: []state{img.currentState()}}
+	}
+	img.history.limit = limit
+	img.history.trim()
+	return nil
+}
+
+func (img *OurImage) CanUndo() bool {
+	return img.history != nil && img.history.current > 0
+}
+
+func (img *OurImage) CanRedo() bool {
+	return img.history != nil && img.history.current < len(img.history.states)-1
+}
+
+func (img *OurImage) Undo() error {
+	if !img.CanUndo() {
+		return fmt.Errorf("nothing to undo")
+	}
+	return img.GoToHistory(img.history.current - 1)
+}
+
+func (img *OurImage) Redo() error {
+	if !img.CanRedo() {
+		return fmt.Errorf("nothing to redo")
+	}
+	return img.GoToHistory(img.history.current + 1)
+}
+
+// GoToHistory shows the snapshot at index of History.
+func (img *OurImage) GoToHistory(index int) error {
+	if img.history == nil || index < 0 || index >= len(img.history.states) {
+		return fmt.Errorf("there is no step %v in the history", index)
+	}
+	img.history.current = index
+	img.setState(img.history.states[index])
+	return nil
+}
+
+// History returns the operation of every snapshot retained, oldest first,
+// and the index of the one shown.
+func (img *OurImage) History() ([]string, int) {
+	states, current := []state{img.currentState()}, 0
+	if img.history != nil {
+		states, current = img.history.states, img.history.current
+	}
+	steps := make([]string, len(states))
+	for i, s := range states {
+		steps[i] = s.action
+		if steps[i] == "" {
+			steps[i] = "Original"
+		}
+	}
+	return steps, current
+}
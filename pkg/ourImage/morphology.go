@@ -0,0 +1,57 @@
+package ourimage
+
+import (
+	"image"
+
+	"github.com/vision-go/vision-go/pkg/morphology"
+	"github.com/vision-go/vision-go/pkg/pipeline"
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+func (originalImg *OurImage) Erode(e morphology.Element) *OurImage {
+	return originalImg.morphology(morphology.Erode, e, pipeline.ErodeSuffix, "erode")
+}
+
+func (originalImg *OurImage) Dilate(e morphology.Element) *OurImage {
+	return originalImg.morphology(morphology.Dilate, e, pipeline.DilateSuffix, "dilate")
+}
+
+func (originalImg *OurImage) Open(e morphology.Element) *OurImage {
+	return originalImg.morphology(morphology.Open, e, pipeline.OpenSuffix, "open")
+}
+
+func (originalImg *OurImage) Close(e morphology.Element) *OurImage {
+	return originalImg.morphology(morphology.Close, e, pipeline.CloseSuffix, "close")
+}
+
+func (originalImg *OurImage) MorphologicalGradient(e morphology.Element) *OurImage {
+	return originalImg.morphology(morphology.Gradient, e, pipeline.MorphGradientSuffix, "morph-gradient")
+}
+
+func (originalImg *OurImage) TopHat(e morphology.Element) *OurImage {
+	return originalImg.morphology(morphology.TopHat, e, pipeline.TopHatSuffix, "top-hat")
+}
+
+func (originalImg *OurImage) BlackHat(e morphology.Element) *OurImage {
+	return originalImg.morphology(morphology.BlackHat, e, pipeline.BlackHatSuffix, "black-hat")
+}
+
+func (originalImg *OurImage) HitOrMiss(e morphology.Element) *OurImage {
+	return originalImg.binaryMorphology(morphology.HitOrMiss, e, pipeline.HitOrMissSuffix, "hit-or-miss")
+}
+
+func (originalImg *OurImage) Skeleton(e morphology.Element) *OurImage {
+	return originalImg.binaryMorphology(morphology.Skeleton, e, pipeline.SkeletonSuffix, "skeleton")
+}
+
+// morphology records the element cell by cell, like the kernels of Convolve.
+func (originalImg *OurImage) morphology(op func(image.Image, morphology.Element) image.Image, e morphology.Element, suffix, operation string) *OurImage {
+	return originalImg.newFromInput(op(originalImg.input(), e), suffix, step(operation, map[string]interface{}{"element": e.String()}))
+}
+
+// binaryMorphology also records the weights of the grey levels binarized.
+func (originalImg *OurImage) binaryMorphology(op func(image.Image, morphology.Element, processing.Luminance) image.Image, e morphology.Element, suffix, operation string) *OurImage {
+	l := originalImg.luminance
+	return originalImg.newFromInput(op(originalImg.input(), e, l), suffix,
+		step(operation, map[string]interface{}{"element": e.String(), "luminance": l.String()}))
+}
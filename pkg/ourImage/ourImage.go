@@ -1,218 +1,156 @@
 package ourimage
 
 import (
-	"fmt"
 	"image"
-	"image/jpeg"
-	"image/png"
-	"math"
+	"io"
 	"os"
-	"strings"
-
-	"golang.org/x/image/tiff"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
 
-	histogram "github.com/vision-go/vision-go/pkg/histogram"
+	"github.com/vision-go/vision-go/pkg/pipeline"
+	"github.com/vision-go/vision-go/pkg/processing"
 )
 
+// OurImage is the widget shown in every tab. The image processing itself
+// lives in the processing package, OurImage only wraps its results.
 type OurImage struct {
 	widget.BaseWidget
-	name               string
-	canvasImage        *canvas.Image
-	format             string
-	brightness         float64
-	contrast           float64
-	entropy            float64
-	numberOfColors     int
-	minColor, maxColor int
-	size               int
-	statusBar          *widget.Label
-	mainWindow         fyne.Window
-	rectangle          image.Rectangle
+	name          string
+	canvasImage   *canvas.Image
+	format        string
+	statistics    processing.Statistics
+	luminance     processing.Luminance // Weights of the grey levels of statistics
+	statusBar     *widget.Label
+	mainWindow    fyne.Window
+	selection     selection
+	tool          int
+	wandTolerance int
+	building      bool // A polygon is being clicked
+	drag          int  // What the primary button is dragging
+	dragStart     image.Point
+	dragFrom      image.Rectangle
+	action        string   // Last operation applied, empty for opened files
+	history       *history // Only used when editing in place
+	provenance    pipeline.Provenance
+	scroll        *container.Scroll
+	zoom          float32
+	panning       bool
+	panStart      fyne.Position
 
 	ROIcallback       func(*OurImage)
 	closeTabsCallback func(int)
+	selectionCallback func(*OurImage)
 
-	HistogramR histogram.Histogram
-	HistogramG histogram.Histogram
-	HistogramB histogram.Histogram
-	Histogram  histogram.Histogram
-
-	HistogramAccumulativeR histogram.Histogram
-	HistogramAccumulativeG histogram.Histogram
-	HistogramAccumulativeB histogram.Histogram
-	HistogramAccumulative  histogram.Histogram
+	processing.Histograms
+}
 
-	HistogramNormalizedR histogram.HistogramNormalized
-	HistogramNormalizedG histogram.HistogramNormalized
-	HistogramNormalizedB histogram.HistogramNormalized
-	HistogramNormalized  histogram.HistogramNormalized
+func NewFromPath(path, name string, statusBar *widget.Label, w fyne.Window, ROIcallback func(*OurImage), closeTabsCallback func(int)) (*OurImage, error) {
+	return newFromFile(path, name, statusBar, w, ROIcallback, closeTabsCallback, func(r io.ReadSeeker) (image.Image, string, error) {
+		return processing.Decode(r, path)
+	})
 }
 
-func (ourimage *OurImage) CreateRenderer() fyne.WidgetRenderer {
-	return widget.NewSimpleRenderer(ourimage.canvasImage)
+// NewFromRaw opens the raw file path, whose pixels are described by o.
+func NewFromRaw(path, name string, o processing.RawOptions, statusBar *widget.Label, w fyne.Window, ROIcallback func(*OurImage), closeTabsCallback func(int)) (*OurImage, error) {
+	return newFromFile(path, name, statusBar, w, ROIcallback, closeTabsCallback, func(r io.ReadSeeker) (image.Image, string, error) {
+		img, err := processing.DecodeRaw(r, o)
+		return img, processing.RawDescription(o), err
+	})
 }
 
-func NewFromPath(path, name string, statusBar *widget.Label, w fyne.Window, ROIcallback func(*OurImage), closeTabsCallback func(int)) (*OurImage, error) {
+func newFromFile(path, name string, statusBar *widget.Label, w fyne.Window, ROIcallback func(*OurImage), closeTabsCallback func(int),
+	decode func(io.ReadSeeker) (image.Image, string, error)) (*OurImage, error) {
 	img := &OurImage{}
 	img.name = name
 	img.statusBar = statusBar
 	img.mainWindow = w
 	img.ROIcallback = ROIcallback
 	img.closeTabsCallback = closeTabsCallback
+	img.provenance = pipeline.Provenance{Source: path}
+	img.luminance = processing.PAL
+	img.wandTolerance = DefaultWandTolerance
 	img.ExtendBaseWidget(img)
 	f, err := os.Open(path)
 	if err != nil {
 		return img, err
 	}
 	defer f.Close()
-	inputImg, format, err := image.Decode(f)
+	inputImg, format, err := decode(f)
 	img.format = format
-	if err == image.ErrFormat {
-		fmt.Println("No debería")
-		img.format = "tfe(no format)"
-		pixels := make([]byte, 320*200) // TODO dynamic size?
-		f.Seek(0, 0)
-		_, err = f.Read(pixels)
-		if err != nil {
-			return img, err
-		}
-		grayImg := image.NewGray(image.Rect(0, 0, 320, 200))
-		grayImg.Pix = pixels
-		inputImg = grayImg
-	} else if err != nil {
+	if err != nil {
 		return img, err
 	}
-	img.canvasImage = canvas.NewImageFromImage(inputImg)
-	img.canvasImage.FillMode = canvas.ImageFillOriginal
-
-	img.size = img.canvasImage.Image.Bounds().Dx() * img.canvasImage.Image.Bounds().Dy()
-	makeHistogram(img)
-	img.minColor, img.maxColor = img.calculateMinAndMaxColor()
-	img.brightness = img.calculateBrightness()
-	img.contrast = img.calculateContrast(img.brightness)
-	img.entropy, img.numberOfColors = img.calculateEntropyAndNumberOfColors()
+	img.setImage(inputImg)
 	return img, nil
 }
 
-func (ourImage *OurImage) newFromImage(newImage image.Image, actionForName string) *OurImage {
+// newFromImage wraps the result of an operation, steps being what the
+// operation records in the provenance of the new image.
+func (ourImage *OurImage) newFromImage(newImage image.Image, actionForName string, steps ...pipeline.Step) *OurImage {
 	img := &OurImage{}
 	img.name = ourImage.addOperationToName(actionForName)
+	img.action = actionForName
+	img.provenance = ourImage.provenance.With(steps...)
 	img.statusBar = ourImage.statusBar
 	img.mainWindow = ourImage.mainWindow
 	img.ROIcallback = ourImage.ROIcallback
 	img.closeTabsCallback = ourImage.closeTabsCallback
+	img.selectionCallback = ourImage.selectionCallback
+	img.tool = ourImage.tool
+	img.wandTolerance = ourImage.wandTolerance
+	img.luminance = ourImage.luminance
 	img.ExtendBaseWidget(img)
-	img.canvasImage = canvas.NewImageFromImage(newImage)
-	img.canvasImage.FillMode = canvas.ImageFillOriginal
-	img.size = img.canvasImage.Image.Bounds().Dx() * img.canvasImage.Image.Bounds().Dy()
-	makeHistogram(img)
-	img.minColor, img.maxColor = img.calculateMinAndMaxColor()
-	img.brightness = img.calculateBrightness()
-	img.contrast = img.calculateContrast(img.brightness)
-	img.entropy, img.numberOfColors = img.calculateEntropyAndNumberOfColors()
+	img.setImage(newImage)
 	return img
 }
 
-func makeHistogram(image *OurImage) {
-	for i := 0; i < image.canvasImage.Image.Bounds().Dx(); i++ {
-		for j := 0; j < image.canvasImage.Image.Bounds().Dy(); j++ {
-			r, g, b, a := image.canvasImage.Image.At(i, j).RGBA()
-			if a != 0 {
-				r, g, b = r>>8, g>>8, b>>8
-				image.HistogramR[r] = image.HistogramR.At(int(r)) + 1
-				image.HistogramG[g] = image.HistogramG.At(int(g)) + 1
-				image.HistogramB[b] = image.HistogramG.At(int(b)) + 1
-
-				grey := 0.222*float64(r) + 0.707*float64(g) + 0.071*float64(b) // PAL
-				image.Histogram[int(math.Round(grey))] = image.Histogram.At(int(math.Round(grey))) + 1
-			}
-		}
-	}
-	for index := range image.Histogram {
-		for i := 0; i < index; i++ {
-			image.HistogramAccumulativeR[index] += image.HistogramR.At(i)
-			image.HistogramAccumulativeG[index] += image.HistogramG.At(i)
-			image.HistogramAccumulativeB[index] += image.HistogramB.At(i)
-			image.HistogramAccumulative[index] += image.Histogram.At(i)
-		}
-	}
-	for i := 0; i < 256; i++ {
-		image.HistogramNormalized[i] = float64(image.Histogram[i]) / float64(image.size)
-		image.HistogramNormalizedR[i] = float64(image.HistogramR[i]) / float64(image.size)
-		image.HistogramNormalizedG[i] = float64(image.HistogramG[i]) / float64(image.size)
-		image.HistogramNormalizedB[i] = float64(image.HistogramB[i]) / float64(image.size)
-	}
+func (img *OurImage) setImage(newImage image.Image) {
+	img.canvasImage = canvas.NewImageFromImage(newImage)
+	img.canvasImage.FillMode = canvas.ImageFillStretch // The size comes from the zoom
+	img.updateSize()
+	img.UpdateStatistics()
 }
 
-func (img *OurImage) addOperationToName(actionForName string) string {
-	if actionForName == "" {
-		return img.name
-	}
-	actionForName = "(" + actionForName + ")"
-	pointIndex := strings.LastIndex(img.name, ".")
-	if pointIndex == -1 {
-		return img.name + actionForName
-	}
-	return img.name[:pointIndex] + actionForName + img.name[pointIndex:]
+// UpdateStatistics computes the statistics again with the weights of the
+// grey levels of img.
+func (img *OurImage) UpdateStatistics() {
+	img.statistics = processing.NewStatistics(img.canvasImage.Image, img.luminance)
+	img.Histograms = img.statistics.Histograms
 }
 
-func (img *OurImage) Save(file *os.File, format string) error {
-	if format == "png" {
-		return png.Encode(file, img.canvasImage.Image)
-	} else if format == "jpeg" || format == "jpg" {
-		return jpeg.Encode(file, img.canvasImage.Image, nil)
-	} else if format == "tif" || format == "tiff" {
-		return tiff.Encode(file, img.canvasImage.Image, nil)
+// SetLuminance changes the weights of the grey levels of img: its grey
+// histogram, its brightness and contrast and the operations applied to it
+// from now on.
+func (img *OurImage) SetLuminance(l processing.Luminance) {
+	img.luminance = l
+	img.UpdateStatistics()
+	if img.history != nil {
+		img.history.states[img.history.current] = img.currentState()
 	}
-	return fmt.Errorf("incorrrect format")
 }
 
-func (ourimage *OurImage) calculateBrightness() (value float64) {
-	for color, count := range ourimage.Histogram {
-		value += float64(color * count)
-	}
-	return value / float64(ourimage.size)
+func (img *OurImage) addOperationToName(actionForName string) string {
+	return pipeline.AddOperationToName(img.name, actionForName)
 }
 
-func (ourimage *OurImage) calculateContrast(brightness float64) (value float64) {
-	for color, count := range ourimage.Histogram {
-		value += float64(count) * (float64(color) - brightness) * (float64(color) - brightness)
-	}
-	return math.Sqrt(value / float64(ourimage.size))
+func (img *OurImage) Save(file *os.File, format string, options processing.SaveOptions) error {
+	return processing.EncodeOptions(file, img.canvasImage.Image, format, img.saveOptions(options))
 }
 
-func (ourimage *OurImage) calculateMinAndMaxColor() (min, max int) {
-	for color, count := range ourimage.Histogram {
-		if count != 0 {
-			min = color
-			break
-		}
-	}
-	for max = ourimage.Histogram.Len() - 1; max >= 0; max-- {
-		if ourimage.Histogram.At(max) != 0 {
-			break
-		}
-	}
-	return
+// EstimateSize returns the bytes Save would write with the same format and
+// options.
+func (img *OurImage) EstimateSize(format string, options processing.SaveOptions) (int64, error) {
+	return processing.EstimateSize(img.canvasImage.Image, format, img.saveOptions(options))
 }
 
-func (ourimage *OurImage) calculateEntropyAndNumberOfColors() (float64, int) {
-	var sum float64
-	var numberOfColors int
-	for _, count := range ourimage.Histogram {
-		if count != 0 {
-			numberOfColors++
-		}
-	}
-	for _, count := range ourimage.Histogram {
-		if count != 0 {
-			probability := 1 / float64(numberOfColors)
-			sum += probability * math.Log2(probability)
-		}
+// saveOptions saves colour images as pgm with the weights of img unless
+// options have others.
+func (img *OurImage) saveOptions(options processing.SaveOptions) processing.SaveOptions {
+	if options.Luminance == (processing.Luminance{}) {
+		options.Luminance = img.luminance
 	}
-	return sum * -1, numberOfColors
+	return options
 }
@@ -0,0 +1,503 @@
+package ourimage
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+
+	"github.com/vision-go/vision-go/pkg/pipeline"
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// handleSize is the side, in screen units, of the squares drawn on the
+// corners of the selection to resize it.
+const handleSize = 8
+
+// Tools to select with the primary button.
+const (
+	RectangleTool = iota
+	EllipseTool
+	PolygonTool  // Click the vertices, then the first one again to close it
+	FreehandTool // Drag around the region
+	WandTool     // Click a pixel to select the similar ones connected to it
+)
+
+// DefaultWandTolerance is the tolerance of the wand unless SetWandTolerance
+// says otherwise.
+const DefaultWandTolerance = 32
+
+// What a drag with the primary button is doing.
+const (
+	dragNone = iota
+	dragNew
+	dragCorner
+	dragMove
+	dragFreehand
+)
+
+var (
+	selectionColor = color.NRGBA{R: 0, G: 170, B: 255, A: 255}
+	selectionFill  = color.NRGBA{R: 0, G: 170, B: 255, A: 40}
+)
+
+// selection is the selected part of an image. It is a rectangle unless mask
+// is set, shape and vertices describing how the mask was made.
+type selection struct {
+	rect      image.Rectangle // Bounding box, empty when nothing is selected
+	mask      *image.Alpha
+	shape     string
+	vertices  []image.Point // Of a polygon, or the seed of a wand
+	tolerance int
+}
+
+// SetTool chooses what the primary button selects with.
+func (img *OurImage) SetTool(tool int) {
+	img.tool = tool
+	img.building = false
+	img.Refresh()
+}
+
+// SetSelectionCallback sets the function called every time the selection
+// changes, even while it is being dragged.
+func (img *OurImage) SetSelectionCallback(callback func(*OurImage)) {
+	img.selectionCallback = callback
+}
+
+// selectionChanged refreshes the selection and tells the callback.
+func (img *OurImage) selectionChanged() {
+	img.Refresh()
+	if img.selectionCallback != nil {
+		img.selectionCallback(img)
+	}
+}
+
+// SetWandTolerance sets how much the colour of the pixels selected by the
+// wand may differ from the one clicked, from 0 to 255.
+func (img *OurImage) SetWandTolerance(tolerance int) {
+	img.wandTolerance = tolerance
+}
+
+// Selection returns the bounding box of the active selection in image
+// coordinates, empty if there is none. While there is one, operations only
+// change the pixels inside it.
+func (img *OurImage) Selection() image.Rectangle {
+	return img.selection.rect
+}
+
+// SelectionMask returns the mask of the active selection, nil when it is a
+// rectangle.
+func (img *OurImage) SelectionMask() *image.Alpha {
+	return img.selection.mask
+}
+
+// SetSelection selects rect, which must be inside the image. An elliptical
+// selection stays an ellipse inscribed in rect.
+func (img *OurImage) SetSelection(rect image.Rectangle) error {
+	rect = rect.Canon()
+	if rect.Empty() {
+		return fmt.Errorf("the selection must have a positive width and height")
+	}
+	if !rect.In(img.canvasImage.Image.Bounds()) {
+		return fmt.Errorf("the selection %v is outside of the image %v", rect, img.canvasImage.Image.Bounds())
+	}
+	shape := pipeline.RectangleShape
+	if img.selection.shape == pipeline.EllipseShape {
+		shape = pipeline.EllipseShape
+	}
+	img.selection = selection{rect: rect, shape: shape}
+	img.updateMask()
+	img.selectionChanged()
+	return nil
+}
+
+func (img *OurImage) SelectAll() {
+	img.selection = selection{rect: img.canvasImage.Image.Bounds(), shape: pipeline.RectangleShape}
+	img.selectionChanged()
+}
+
+func (img *OurImage) ClearSelection() {
+	img.selection = selection{}
+	img.building = false
+	img.selectionChanged()
+}
+
+// Crop returns the selected part of the image, transparent outside of the
+// selection when it is not a rectangle.
+func (img *OurImage) Crop() (*OurImage, error) {
+	if img.selection.rect.Empty() {
+		return nil, fmt.Errorf("there is no selection")
+	}
+	if img.selection.mask == nil {
+		return img.ROI(img.selection.rect), nil
+	}
+	return img.newFromImage(processing.CropMasked(img.canvasImage.Image, img.selection.mask), pipeline.CropSuffix,
+		pipeline.Step{Operation: "crop", Region: img.region()}), nil
+}
+
+// region describes the selection in the provenance.
+func (img *OurImage) region() *pipeline.Region {
+	region := pipeline.NewRegion(img.selection.rect)
+	if img.selection.shape != pipeline.RectangleShape {
+		region.Shape = img.selection.shape
+	}
+	if len(img.selection.vertices) != 0 {
+		region.SetVertices(img.selection.vertices)
+	}
+	if img.selection.shape == pipeline.WandShape {
+		region.Tolerance = img.selection.tolerance
+	}
+	return region
+}
+
+// updateMask makes the mask of the shape of the selection again after its
+// rectangle or its vertices changed.
+func (img *OurImage) updateMask() {
+	s := &img.selection
+	switch s.shape {
+	case pipeline.EllipseShape:
+		s.mask = processing.EllipseMask(s.rect)
+	case pipeline.PolygonShape:
+		s.mask = processing.PolygonMask(s.vertices, img.canvasImage.Image.Bounds())
+		s.rect = s.mask.Bounds()
+	case pipeline.WandShape:
+		s.mask = processing.MagicWand(img.canvasImage.Image, s.vertices[0], s.tolerance)
+		s.rect = s.mask.Bounds()
+	default:
+		s.mask = nil
+	}
+	if s.rect.Empty() {
+		*s = selection{}
+	}
+}
+
+// startDrag decides, from where the primary button was pressed, whether the
+// drag resizes the selection from a corner, moves it or makes a new one.
+func (img *OurImage) startDrag(pos fyne.Position) {
+	point := img.toImage(pos)
+	if img.tool == PolygonTool && img.building {
+		img.addVertex(pos, point)
+		return
+	}
+	img.dragStart = point
+	img.dragFrom = img.selection.rect
+	s := img.selection
+	if !s.rect.Empty() {
+		if s.shape == pipeline.RectangleShape || s.shape == pipeline.EllipseShape {
+			r := s.rect
+			corners := [4][2]image.Point{
+				{r.Min, r.Max},
+				{image.Pt(r.Max.X, r.Min.Y), image.Pt(r.Min.X, r.Max.Y)},
+				{image.Pt(r.Min.X, r.Max.Y), image.Pt(r.Max.X, r.Min.Y)},
+				{r.Max, r.Min},
+			}
+			for _, corner := range corners {
+				onScreen := img.toScreen(corner[0])
+				if abs32(onScreen.X-pos.X) <= handleSize && abs32(onScreen.Y-pos.Y) <= handleSize {
+					img.drag = dragCorner
+					img.dragStart = corner[1] // The opposite corner stays
+					return
+				}
+			}
+		}
+		inside := point.In(s.rect) && (s.mask == nil || s.mask.AlphaAt(point.X, point.Y).A != 0)
+		if inside && s.shape != pipeline.WandShape { // The wand selects again where clicked
+			img.drag = dragMove
+			return
+		}
+	}
+	img.selection = selection{}
+	switch img.tool {
+	case RectangleTool:
+		img.drag = dragNew
+		img.selection.shape = pipeline.RectangleShape
+	case EllipseTool:
+		img.drag = dragNew
+		img.selection.shape = pipeline.EllipseShape
+	case FreehandTool:
+		img.drag = dragFreehand
+		img.selection.shape = pipeline.PolygonShape
+		img.selection.vertices = []image.Point{point}
+	case PolygonTool:
+		img.building = true
+		img.selection.shape = pipeline.PolygonShape
+		img.selection.vertices = []image.Point{point}
+	case WandTool:
+		if point.In(img.canvasImage.Image.Bounds()) {
+			img.selection = selection{shape: pipeline.WandShape, vertices: []image.Point{point}, tolerance: img.wandTolerance}
+			img.updateMask()
+		}
+	}
+	img.selectionChanged()
+}
+
+// addVertex adds a vertex to the polygon being built, closing it when the
+// first one is clicked again.
+func (img *OurImage) addVertex(pos fyne.Position, point image.Point) {
+	vertices := img.selection.vertices
+	first := img.toScreen(vertices[0])
+	if len(vertices) >= 3 && abs32(first.X-pos.X) <= handleSize && abs32(first.Y-pos.Y) <= handleSize {
+		img.building = false
+		img.updateMask()
+	} else {
+		img.selection.vertices = append(vertices, point)
+	}
+	img.selectionChanged()
+}
+
+// dragTo updates the selection while the primary button is held.
+func (img *OurImage) dragTo(pos fyne.Position) {
+	bounds := img.canvasImage.Image.Bounds()
+	point := img.toImage(pos)
+	switch img.drag {
+	case dragNew, dragCorner:
+		img.selection.rect = image.Rectangle{Min: img.dragStart, Max: point}.Canon().Intersect(bounds)
+	case dragMove:
+		moved := img.dragFrom.Add(point.Sub(img.dragStart))
+		if moved.Min.X < bounds.Min.X {
+			moved = moved.Add(image.Pt(bounds.Min.X-moved.Min.X, 0))
+		}
+		if moved.Min.Y < bounds.Min.Y {
+			moved = moved.Add(image.Pt(0, bounds.Min.Y-moved.Min.Y))
+		}
+		if moved.Max.X > bounds.Max.X {
+			moved = moved.Sub(image.Pt(moved.Max.X-bounds.Max.X, 0))
+		}
+		if moved.Max.Y > bounds.Max.Y {
+			moved = moved.Sub(image.Pt(0, moved.Max.Y-bounds.Max.Y))
+		}
+		img.selection.rect = moved
+	case dragFreehand:
+		vertices := img.selection.vertices
+		if vertices[len(vertices)-1] != point {
+			img.selection.vertices = append(vertices, point)
+		}
+	default:
+		return
+	}
+	img.selectionChanged()
+}
+
+// endDrag finishes the drag. A click without dragging clears the selection.
+func (img *OurImage) endDrag(pos fyne.Position) {
+	if img.drag == dragNone {
+		return
+	}
+	img.dragTo(pos)
+	s := &img.selection
+	switch img.drag {
+	case dragNew:
+		if s.rect.Dx() < 2 || s.rect.Dy() < 2 {
+			*s = selection{}
+		}
+	case dragMove:
+		delta := s.rect.Min.Sub(img.dragFrom.Min)
+		vertices := make([]image.Point, len(s.vertices)) // Shared with the images derived from this one
+		for i, vertex := range s.vertices {
+			vertices[i] = vertex.Add(delta)
+		}
+		s.vertices = vertices
+	case dragFreehand:
+		if len(s.vertices) < 3 {
+			*s = selection{}
+		}
+	}
+	img.drag = dragNone
+	if s.shape != "" {
+		img.updateMask()
+	}
+	img.selectionChanged()
+}
+
+// toScreen converts image coordinates into a position of the widget.
+func (img *OurImage) toScreen(point image.Point) fyne.Position {
+	return fyne.NewPos(float32(point.X)*img.zoom, float32(point.Y)*img.zoom)
+}
+
+func abs32(x float32) float32 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// input is what operations are applied to: the bounding box of the
+// selection if there is one, the whole image otherwise.
+func (img *OurImage) input() image.Image {
+	if img.selection.rect.Empty() {
+		return img.canvasImage.Image
+	}
+	return processing.ROI(img.canvasImage.Image, img.selection.rect)
+}
+
+// inputMask is the mask of the selection in the coordinates of input, nil
+// when the whole input is selected.
+func (img *OurImage) inputMask() *image.Alpha {
+	return processing.ROIMask(img.selection.mask, img.selection.rect)
+}
+
+// SelectionStatistics are the statistics of the selected pixels, of the
+// whole image when nothing is selected.
+func (img *OurImage) SelectionStatistics() processing.Statistics {
+	switch {
+	case img.selection.rect.Empty():
+		return img.statistics
+	case img.selection.mask != nil:
+		return processing.NewMaskedStatistics(img.canvasImage.Image, img.selection.mask, img.luminance)
+	}
+	return processing.NewStatistics(img.input(), img.luminance)
+}
+
+// inputOf is the part of other that operations between two images compare
+// with input.
+func (img *OurImage) inputOf(other *OurImage) image.Image {
+	if img.selection.rect.Empty() || !img.selection.rect.In(other.canvasImage.Image.Bounds()) {
+		return other.canvasImage.Image
+	}
+	return processing.ROI(other.canvasImage.Image, img.selection.rect)
+}
+
+// newFromInput wraps the result of an operation applied to input. With a
+// selection the result is pasted back into the selected pixels and the
+// steps are restricted to its region; if the operation changed the size of
+// the selection the result is the transformed bounding box alone.
+func (ourImage *OurImage) newFromInput(result image.Image, actionForName string, steps ...pipeline.Step) *OurImage {
+	s := ourImage.selection
+	if s.rect.Empty() {
+		return ourImage.newFromImage(result, actionForName, steps...)
+	}
+	region := ourImage.region()
+	for i := range steps {
+		steps[i].Region = region
+	}
+	if result.Bounds().Size() != s.rect.Size() {
+		return ourImage.newFromImage(result, actionForName, steps...)
+	}
+	img := ourImage.newFromImage(processing.Paste(ourImage.canvasImage.Image, result, s.rect.Min, s.mask), actionForName, steps...)
+	img.selection = s
+	return img
+}
+
+// ourImageRenderer draws the image with the selection on top of it.
+type ourImageRenderer struct {
+	img       *OurImage
+	selection *canvas.Rectangle
+	handles   [4]*canvas.Rectangle
+	mask      *canvas.Image
+	shownMask *image.Alpha // The one mask shows
+	lines     []fyne.CanvasObject
+	objects   []fyne.CanvasObject
+}
+
+func (ourimage *OurImage) CreateRenderer() fyne.WidgetRenderer {
+	r := &ourImageRenderer{img: ourimage}
+	r.selection = canvas.NewRectangle(selectionFill)
+	r.selection.StrokeColor = selectionColor
+	r.selection.StrokeWidth = 1
+	r.mask = canvas.NewImageFromImage(image.NewNRGBA(image.Rectangle{}))
+	r.mask.FillMode = canvas.ImageFillStretch
+	r.mask.ScaleMode = canvas.ImageScalePixels
+	r.objects = []fyne.CanvasObject{ourimage.canvasImage, r.mask, r.selection}
+	for i := range r.handles {
+		r.handles[i] = canvas.NewRectangle(selectionColor)
+		r.handles[i].Resize(fyne.NewSize(handleSize, handleSize))
+		r.objects = append(r.objects, r.handles[i])
+	}
+	r.Refresh()
+	return r
+}
+
+func (r *ourImageRenderer) Layout(size fyne.Size) {
+	r.img.canvasImage.Resize(size)
+	s := r.img.selection
+	r.layoutLines()
+	for _, handle := range r.handles {
+		handle.Hide()
+	}
+	if s.rect.Empty() {
+		r.selection.Hide()
+		r.mask.Hide()
+		return
+	}
+	min, max := r.img.toScreen(s.rect.Min), r.img.toScreen(s.rect.Max)
+	r.selection.Move(min)
+	r.selection.Resize(fyne.NewSize(max.X-min.X, max.Y-min.Y))
+	r.selection.Show()
+	if s.mask == nil || r.img.drag == dragCorner {
+		r.selection.FillColor = selectionFill
+		r.mask.Hide()
+	} else { // The mask is tinted, the rectangle is just its bounding box
+		r.selection.FillColor = color.Transparent
+		r.mask.Move(min)
+		r.mask.Resize(r.selection.Size())
+		r.mask.Show()
+	}
+	if s.shape == pipeline.RectangleShape || s.shape == pipeline.EllipseShape {
+		corners := []fyne.Position{min, fyne.NewPos(max.X, min.Y), fyne.NewPos(min.X, max.Y), max}
+		for i, corner := range corners {
+			r.handles[i].Move(corner.Subtract(fyne.NewPos(handleSize/2, handleSize/2)))
+			r.handles[i].Show()
+		}
+	}
+}
+
+// layoutLines draws the polygon or the freehand line being made.
+func (r *ourImageRenderer) layoutLines() {
+	r.lines = r.lines[:0]
+	if !r.img.building && r.img.drag != dragFreehand {
+		return
+	}
+	vertices := r.img.selection.vertices
+	for i := 1; i < len(vertices); i++ {
+		line := canvas.NewLine(selectionColor)
+		line.Position1 = r.img.toScreen(vertices[i-1]).Add(fyne.NewPos(r.img.zoom/2, r.img.zoom/2))
+		line.Position2 = r.img.toScreen(vertices[i]).Add(fyne.NewPos(r.img.zoom/2, r.img.zoom/2))
+		r.lines = append(r.lines, line)
+	}
+	if len(vertices) > 0 && r.img.building { // Where clicking closes the polygon
+		first := canvas.NewRectangle(selectionColor)
+		first.Move(r.img.toScreen(vertices[0]).Subtract(fyne.NewPos(handleSize/2, handleSize/2)))
+		first.Resize(fyne.NewSize(handleSize, handleSize))
+		r.lines = append(r.lines, first)
+	}
+}
+
+// tint turns a mask into the image shown over the selected pixels.
+func tint(mask *image.Alpha) image.Image {
+	b := mask.Bounds()
+	tinted := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			if mask.AlphaAt(x+b.Min.X, y+b.Min.Y).A != 0 {
+				tinted.SetNRGBA(x, y, color.NRGBA{R: selectionFill.R, G: selectionFill.G, B: selectionFill.B, A: 90})
+			}
+		}
+	}
+	return tinted
+}
+
+func (r *ourImageRenderer) MinSize() fyne.Size {
+	return r.img.canvasImage.MinSize()
+}
+
+func (r *ourImageRenderer) Refresh() {
+	if mask := r.img.selection.mask; mask != nil && mask != r.shownMask {
+		r.shownMask = mask
+		r.mask.Image = tint(mask)
+	}
+	r.Layout(r.img.Size())
+	for _, object := range r.Objects() {
+		object.Refresh()
+	}
+}
+
+func (r *ourImageRenderer) Objects() []fyne.CanvasObject {
+	if len(r.lines) == 0 {
+		return r.objects
+	}
+	return append(append([]fyne.CanvasObject(nil), r.objects...), r.lines...)
+}
+
+func (r *ourImageRenderer) Destroy() {}
@@ -0,0 +1,38 @@
+package ourimage
+
+import (
+	"github.com/vision-go/vision-go/pkg/pipeline"
+	"github.com/vision-go/vision-go/pkg/threshold"
+)
+
+// Threshold binarizes the image with the threshold chosen by method from
+// the grey histogram of the selection, value for the manual one, which is
+// returned to be shown.
+func (originalImg *OurImage) Threshold(method threshold.Method, value int) (*OurImage, int) {
+	t := threshold.Compute(method, originalImg.SelectionStatistics().Histogram, value)
+	l := originalImg.luminance
+	return originalImg.newFromInput(threshold.Binarize(originalImg.input(), t, l), pipeline.ThresholdSuffix,
+		step("threshold", map[string]interface{}{"method": method.String(), "value": t, "luminance": l.String()})), t
+}
+
+// MultiOtsu splits the grey levels in classes with the thresholds, which are
+// returned, computed from the histogram of the selection.
+func (originalImg *OurImage) MultiOtsu(classes int) (*OurImage, []int, error) {
+	thresholds, err := threshold.MultiOtsu(originalImg.SelectionStatistics().Histogram, classes)
+	if err != nil {
+		return nil, nil, err
+	}
+	l := originalImg.luminance
+	return originalImg.newFromInput(threshold.Levels(originalImg.input(), thresholds, l), pipeline.MultiOtsuSuffix,
+		step("multi-otsu", map[string]interface{}{"classes": classes, "luminance": l.String()})), thresholds, nil
+}
+
+func (originalImg *OurImage) AdaptiveThreshold(local threshold.Local, size int, c float64) (*OurImage, error) {
+	l := originalImg.luminance
+	NewImage, err := threshold.Adaptive(originalImg.input(), local, size, c, l)
+	if err != nil {
+		return nil, err
+	}
+	return originalImg.newFromInput(NewImage, pipeline.AdaptiveThresholdSuffix,
+		step("adaptive-threshold", map[string]interface{}{"local": local.String(), "size": size, "c": c, "luminance": l.String()})), nil
+}
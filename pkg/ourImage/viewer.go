@@ -0,0 +1,137 @@
+package ourimage
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+)
+
+const (
+	minZoom  = 0.05
+	maxZoom  = 32
+	zoomStep = 1.25
+)
+
+// View returns the scroll container that shows the image in its tab.
+func (img *OurImage) View() fyne.CanvasObject {
+	if img.scroll == nil {
+		img.scroll = container.NewScroll(container.New(layout.NewCenterLayout(), img))
+	}
+	return img.scroll
+}
+
+// Zoom returns the screen size of an image pixel, 1 meaning 100%.
+func (img *OurImage) Zoom() float32 {
+	return img.zoom
+}
+
+func (img *OurImage) ZoomIn() {
+	img.zoomAround(img.zoom*zoomStep, img.visibleCenter())
+}
+
+func (img *OurImage) ZoomOut() {
+	img.zoomAround(img.zoom/zoomStep, img.visibleCenter())
+}
+
+// ZoomReset shows the image at 100%.
+func (img *OurImage) ZoomReset() {
+	img.zoomAround(1, img.visibleCenter())
+}
+
+// ZoomFit makes the whole image visible in its tab.
+func (img *OurImage) ZoomFit() {
+	if img.scroll == nil {
+		return
+	}
+	size := img.Dimensions()
+	viewport := img.scroll.Size()
+	zoom := float32(math.Min(float64(viewport.Width)/float64(size.X), float64(viewport.Height)/float64(size.Y)))
+	img.zoomAround(zoom, img.visibleCenter())
+}
+
+// Scrolled zooms around the cursor with the mouse wheel.
+func (img *OurImage) Scrolled(event *fyne.ScrollEvent) {
+	if event.Scrolled.DY > 0 {
+		img.zoomAround(img.zoom*zoomStep, event.Position)
+	} else if event.Scrolled.DY < 0 {
+		img.zoomAround(img.zoom/zoomStep, event.Position)
+	}
+}
+
+// zoomAround changes the zoom keeping the image point under pos (in widget
+// coordinates) at the same place of the screen.
+func (img *OurImage) zoomAround(zoom float32, pos fyne.Position) {
+	if zoom < minZoom {
+		zoom = minZoom
+	} else if zoom > maxZoom {
+		zoom = maxZoom
+	}
+	oldZoom := img.zoom
+	var onScreen fyne.Position
+	if img.scroll != nil {
+		onScreen = img.Position().Add(pos).Subtract(img.scroll.Offset)
+	}
+	img.zoom = zoom
+	img.updateSize()
+	if img.statusBar != nil {
+		img.statusBar.SetText("Zoom: " + formatZoom(zoom))
+	}
+	if img.scroll == nil {
+		return
+	}
+	img.scroll.Refresh() // Lays out the image with its new size
+	newPos := fyne.NewPos(pos.X*zoom/oldZoom, pos.Y*zoom/oldZoom)
+	img.scrollTo(img.Position().Add(newPos).Subtract(onScreen))
+}
+
+// scrollTo moves the scroll container keeping the offset inside the content.
+func (img *OurImage) scrollTo(offset fyne.Position) {
+	content, viewport := img.scroll.Content.Size(), img.scroll.Size()
+	offset.X = float32(math.Max(0, math.Min(float64(offset.X), float64(content.Width-viewport.Width))))
+	offset.Y = float32(math.Max(0, math.Min(float64(offset.Y), float64(content.Height-viewport.Height))))
+	img.scroll.Offset = offset
+	img.scroll.Refresh()
+}
+
+// visibleCenter is the widget point at the centre of the tab.
+func (img *OurImage) visibleCenter() fyne.Position {
+	if img.scroll == nil {
+		size := img.Size()
+		return fyne.NewPos(size.Width/2, size.Height/2)
+	}
+	viewport := img.scroll.Size()
+	return img.scroll.Offset.Add(fyne.NewPos(viewport.Width/2, viewport.Height/2)).Subtract(img.Position())
+}
+
+// updateSize resizes the widget to the image at the current zoom. When
+// magnifying the pixels are shown as squares so they can be inspected.
+func (img *OurImage) updateSize() {
+	if img.zoom == 0 {
+		img.zoom = 1
+	}
+	b := img.canvasImage.Image.Bounds()
+	img.canvasImage.SetMinSize(fyne.NewSize(float32(b.Dx())*img.zoom, float32(b.Dy())*img.zoom))
+	if img.zoom > 1 {
+		img.canvasImage.ScaleMode = canvas.ImageScalePixels
+	} else {
+		img.canvasImage.ScaleMode = canvas.ImageScaleSmooth
+	}
+	if img.scroll != nil { // Not shown yet otherwise
+		img.canvasImage.Refresh()
+		img.Refresh()
+	}
+}
+
+// toImage converts a position of the widget into image coordinates.
+func (img *OurImage) toImage(pos fyne.Position) image.Point {
+	return image.Pt(int(math.Floor(float64(pos.X/img.zoom))), int(math.Floor(float64(pos.Y/img.zoom))))
+}
+
+func formatZoom(zoom float32) string {
+	return fmt.Sprintf("%.0f%%", zoom*100)
+}
@@ -0,0 +1,50 @@
+package pipeline
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/vision-go/vision-go/pkg/clahe"
+)
+
+func init() {
+	register(&Operation{Name: "clahe", Suffix: CLAHESuffix, Usage: "contrast-limited adaptive histogram equalization",
+		Params: []Param{
+			{Name: "columns", Default: "8", Usage: "columns of the grid of tiles [1, 64]"},
+			{Name: "rows", Default: "8", Usage: "rows of the grid of tiles [1, 64]"},
+			{Name: "clip", Default: "2", Usage: "clip limit, times the average count of a level [1, 256]"},
+			{Name: "mode", Default: "lab", Usage: "channels to equalize: " + strings.Join(clahe.Modes(), ", ")},
+		},
+		build: buildCLAHE,
+	})
+}
+
+func buildCLAHE(step Step) (Func, error) {
+	var grid [2]int
+	for i, name := range []string{"columns", "rows"} {
+		value, err := step.Int(name)
+		if err != nil {
+			return nil, err
+		}
+		grid[i] = value
+	}
+	clip, err := step.Float("clip")
+	if err != nil {
+		return nil, err
+	}
+	name, err := step.Text("mode")
+	if err != nil {
+		return nil, err
+	}
+	mode, err := clahe.ParseMode(name)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", step.Operation, err)
+	}
+	if err := clahe.Validate(grid[0], grid[1], clip); err != nil {
+		return nil, fmt.Errorf("%v: %w", step.Operation, err)
+	}
+	return func(img image.Image) (image.Image, error) {
+		return clahe.Equalize(img, grid[0], grid[1], clip, mode)
+	}, nil
+}
@@ -0,0 +1,139 @@
+package pipeline
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/vision-go/vision-go/pkg/colorspace"
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// allChannels is the value of channelParam that applies an operation to the
+// whole image.
+const allChannels = "all"
+
+var (
+	spaceParam   = Param{Name: "space", Default: "rgb", Usage: "colour space of the channel: " + strings.Join(colorspace.Spaces(), ", ")}
+	channelParam = Param{Name: "channel", Default: allChannels, Usage: "channel of the space to change, e.g. r or h, or " + allChannels}
+)
+
+func init() {
+	register(&Operation{Name: "channel", Suffix: ChannelSuffix, Usage: "extract a channel of a colour space as a grey image",
+		Params: []Param{spaceParam, {Name: "channel", Usage: "name of the channel, e.g. r or h"}},
+		build: func(step Step) (Func, error) {
+			space, channel, err := parseChannel(step)
+			if err != nil {
+				return nil, err
+			}
+			return func(img image.Image) (image.Image, error) {
+				return colorspace.Channel(img, space, channel), nil
+			}, nil
+		},
+	})
+	register(&Operation{Name: "merge", Suffix: MergeSuffix, Usage: "merge grey images as the channels of a colour space, the input being the first one",
+		Params: []Param{spaceParam, {Name: "channels", Usage: "paths of the rest of the channels in order, comma separated"}},
+		build:  buildMerge,
+	})
+}
+
+func parseChannel(step Step) (colorspace.Space, int, error) {
+	spaceName, err := step.Text("space")
+	if err != nil {
+		return 0, 0, err
+	}
+	space, err := colorspace.ParseSpace(spaceName)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%v: %w", step.Operation, err)
+	}
+	channelName, err := step.Text("channel")
+	if err != nil {
+		return 0, 0, err
+	}
+	channel, err := space.ParseChannel(channelName)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%v: %w", step.Operation, err)
+	}
+	return space, channel, nil
+}
+
+// onChannel makes a point operation take the space and channel parameters,
+// applying it only to that channel unless it is allChannels.
+func onChannel(build func(Step) (Func, error)) func(Step) (Func, error) {
+	return func(step Step) (Func, error) {
+		f, err := build(step)
+		if err != nil {
+			return nil, err
+		}
+		if name, _ := step.Text("channel"); strings.EqualFold(name, allChannels) {
+			return f, nil
+		}
+		space, channel, err := parseChannel(step)
+		if err != nil {
+			return nil, err
+		}
+		return func(img image.Image) (image.Image, error) {
+			return colorspace.OnChannel(img, space, channel, f)
+		}, nil
+	}
+}
+
+// onChannelMasked is onChannel for the operations computing statistics.
+func onChannelMasked(build func(Step) (maskedFunc, error)) func(Step) (maskedFunc, error) {
+	return func(step Step) (maskedFunc, error) {
+		f, err := build(step)
+		if err != nil {
+			return nil, err
+		}
+		if name, _ := step.Text("channel"); strings.EqualFold(name, allChannels) {
+			return f, nil
+		}
+		space, channel, err := parseChannel(step)
+		if err != nil {
+			return nil, err
+		}
+		return func(img image.Image, mask *image.Alpha) (image.Image, error) {
+			return colorspace.OnChannel(img, space, channel, func(channelImg image.Image) (image.Image, error) {
+				return f(channelImg, mask)
+			})
+		}, nil
+	}
+}
+
+func buildMerge(step Step) (Func, error) {
+	spaceName, err := step.Text("space")
+	if err != nil {
+		return nil, err
+	}
+	space, err := colorspace.ParseSpace(spaceName)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", step.Operation, err)
+	}
+	list, err := step.Text("channels")
+	if err != nil {
+		return nil, err
+	}
+	paths := strings.Split(list, ",")
+	if len(paths)+1 != len(space.Channels()) {
+		return nil, fmt.Errorf("%v: %v needs %v more channels", step.Operation, space, len(space.Channels())-1)
+	}
+	loads := make([]func() (image.Image, error), len(paths))
+	for i, path := range paths {
+		loads[i] = loader(step, strings.TrimSpace(path))
+	}
+	return func(img image.Image) (image.Image, error) {
+		channels := []*processing.Plane{processing.LuminancePlane(img, processing.Average)}
+		for _, load := range loads {
+			other, err := load()
+			if err != nil {
+				return nil, err
+			}
+			channels = append(channels, processing.LuminancePlane(croppedToRegion(step, other), processing.Average)) // Grey channels
+		}
+		result, err := colorspace.MergeDepth(space, channels, nil, processing.DepthOf(img))
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", step.Operation, err)
+		}
+		return result, nil
+	}, nil
+}
@@ -0,0 +1,104 @@
+package pipeline
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/vision-go/vision-go/pkg/denoise"
+)
+
+var modeParam = Param{Name: "mode", Default: "rgb", Usage: "channels to filter: " + strings.Join(denoise.Modes(), ", ")}
+
+func init() {
+	register(&Operation{Name: "median", Suffix: MedianSuffix, Usage: "median filter, removes salt and pepper noise",
+		Params: []Param{{Name: "size", Default: "3", Usage: "odd side of the window [3, 31]"}, modeParam},
+		build: func(step Step) (Func, error) {
+			size, err := step.Int("size")
+			if err != nil {
+				return nil, err
+			}
+			mode, err := parseMode(step)
+			if err != nil {
+				return nil, err
+			}
+			if err := denoise.ValidateMedian(size); err != nil {
+				return nil, fmt.Errorf("%v: %w", step.Operation, err)
+			}
+			return func(img image.Image) (image.Image, error) {
+				return denoise.Median(img, size, mode)
+			}, nil
+		},
+	})
+	register(&Operation{Name: "bilateral", Suffix: BilateralSuffix, Usage: "bilateral filter, smooths keeping the edges",
+		Params: []Param{
+			{Name: "sigma-space", Default: "3", Usage: "spatial standard deviation in pixels (0, 20]"},
+			{Name: "sigma-range", Default: "25", Usage: "standard deviation of the grey levels (0, 255]"},
+			modeParam,
+		},
+		build: func(step Step) (Func, error) {
+			var sigmas [2]float64
+			for i, name := range []string{"sigma-space", "sigma-range"} {
+				value, err := step.Float(name)
+				if err != nil {
+					return nil, err
+				}
+				sigmas[i] = value
+			}
+			mode, err := parseMode(step)
+			if err != nil {
+				return nil, err
+			}
+			if err := denoise.ValidateBilateral(sigmas[0], sigmas[1]); err != nil {
+				return nil, fmt.Errorf("%v: %w", step.Operation, err)
+			}
+			return func(img image.Image) (image.Image, error) {
+				return denoise.Bilateral(img, sigmas[0], sigmas[1], mode)
+			}, nil
+		},
+	})
+	register(&Operation{Name: "nlmeans", Suffix: NLMeansSuffix, Usage: "non-local means, averages the alike patches",
+		Params: []Param{
+			{Name: "strength", Default: "10", Usage: "filtering strength h (0, 255]"},
+			{Name: "patch", Default: "5", Usage: "odd side of the compared patches [1, 15]"},
+			{Name: "search", Default: "11", Usage: "odd side of the search window [3, 41]"},
+			modeParam,
+		},
+		build: func(step Step) (Func, error) {
+			h, err := step.Float("strength")
+			if err != nil {
+				return nil, err
+			}
+			var sizes [2]int
+			for i, name := range []string{"patch", "search"} {
+				value, err := step.Int(name)
+				if err != nil {
+					return nil, err
+				}
+				sizes[i] = value
+			}
+			mode, err := parseMode(step)
+			if err != nil {
+				return nil, err
+			}
+			if err := denoise.ValidateNonLocalMeans(h, sizes[0], sizes[1]); err != nil {
+				return nil, fmt.Errorf("%v: %w", step.Operation, err)
+			}
+			return func(img image.Image) (image.Image, error) {
+				return denoise.NonLocalMeans(img, h, sizes[0], sizes[1], mode)
+			}, nil
+		},
+	})
+}
+
+func parseMode(step Step) (denoise.Mode, error) {
+	name, err := step.Text("mode")
+	if err != nil {
+		return 0, err
+	}
+	mode, err := denoise.ParseMode(name)
+	if err != nil {
+		return 0, fmt.Errorf("%v: %w", step.Operation, err)
+	}
+	return mode, nil
+}
@@ -0,0 +1,30 @@
+package pipeline
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+func init() {
+	register(&Operation{Name: "depth", Suffix: DepthSuffix, Usage: "convert the samples to another precision, 16 or float keep it through the next operations",
+		Params: []Param{{Name: "depth", Usage: "bits per sample: " + strings.Join(processing.Depths(), ", ")}},
+		build:  buildDepth,
+	})
+}
+
+func buildDepth(step Step) (Func, error) {
+	name, err := step.Text("depth")
+	if err != nil {
+		return nil, err
+	}
+	depth, err := processing.ParseDepth(name)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", step.Operation, err)
+	}
+	return func(img image.Image) (image.Image, error) {
+		return processing.ConvertDepth(img, depth), nil
+	}, nil
+}
@@ -0,0 +1,80 @@
+package pipeline
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/vision-go/vision-go/pkg/edges"
+)
+
+func init() {
+	register(&Operation{Name: "gradient", Suffix: GradientSuffix, Usage: "magnitude or direction of the gradient of the grey level",
+		Params: []Param{
+			{Name: "operator", Default: "sobel", Usage: "operator: " + strings.Join(edges.Operators(), ", ")},
+			{Name: "output", Default: "magnitude", Usage: "magnitude or direction"},
+			luminanceParam,
+		},
+		build: buildGradient,
+	})
+	register(&Operation{Name: "canny", Suffix: CannySuffix, Usage: "canny edge detector",
+		Params: []Param{
+			{Name: "sigma", Default: "1.4", Usage: "standard deviation of the smoothing [0, 50], 0 for none"},
+			{Name: "low", Default: "20", Usage: "low hysteresis threshold [0, 255]"},
+			{Name: "high", Default: "50", Usage: "high hysteresis threshold [0, 255]"},
+			luminanceParam,
+		},
+		build: buildCanny,
+	})
+}
+
+func buildGradient(step Step) (Func, error) {
+	name, err := step.Text("operator")
+	if err != nil {
+		return nil, err
+	}
+	op, err := edges.ParseOperator(name)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", step.Operation, err)
+	}
+	output, err := step.Text("output")
+	if err != nil {
+		return nil, err
+	}
+	l, err := step.Luminance()
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(output) {
+	case "magnitude":
+		return func(img image.Image) (image.Image, error) {
+			return edges.Magnitude(img, op, l), nil
+		}, nil
+	case "direction":
+		return func(img image.Image) (image.Image, error) {
+			return edges.Direction(img, op, l), nil
+		}, nil
+	}
+	return nil, fmt.Errorf("%v: the output must be magnitude or direction", step.Operation)
+}
+
+func buildCanny(step Step) (Func, error) {
+	var values [3]float64
+	for i, name := range []string{"sigma", "low", "high"} {
+		value, err := step.Float(name)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	if err := edges.ValidateCanny(values[0], values[1], values[2]); err != nil {
+		return nil, fmt.Errorf("%v: %w", step.Operation, err)
+	}
+	l, err := step.Luminance()
+	if err != nil {
+		return nil, err
+	}
+	return func(img image.Image) (image.Image, error) {
+		return edges.Canny(img, values[0], values[1], values[2], l)
+	}, nil
+}
@@ -0,0 +1,117 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// Save tells where the result of a pipeline file goes.
+type Save struct {
+	Format      string `json:"format" yaml:"format"`
+	Pattern     string `json:"pattern,omitempty" yaml:"pattern,omitempty"`         // See batch.Options
+	Quality     int    `json:"quality,omitempty" yaml:"quality,omitempty"`         // Of jpg files
+	Compression string `json:"compression,omitempty" yaml:"compression,omitempty"` // Of png and tif files
+	Plain       bool   `json:"plain,omitempty" yaml:"plain,omitempty"`             // ASCII pnm files
+}
+
+// Options returns the settings of the encoder.
+func (s Save) Options() (processing.SaveOptions, error) {
+	o := processing.SaveOptions{JPEGQuality: s.Quality, PNMPlain: s.Plain}
+	if s.Compression != "" {
+		if err := o.SetCompression(s.Compression); err != nil {
+			return o, err
+		}
+	}
+	return o, o.Validate()
+}
+
+// Formats are the formats a pipeline is able to save.
+var Formats = []string{"png", "jpg", "jpeg", "tif", "tiff", "bmp", "gif", "pgm", "ppm", "pnm"}
+
+// Load reads a pipeline file. Files ending in .json are read as JSON and
+// everything else as YAML, e.g.
+//
+//	steps:
+//	  - operation: monochrome
+//	  - operation: brightness-contrast
+//	    params: {brightness: 120, contrast: 40}
+//	  - operation: linear
+//	    params:
+//	      points: [{x: 0, y: 0}, {x: 100, y: 200}, {x: 255, y: 255}]
+//	save:
+//	  format: png
+//	  compression: best
+func Load(path string) (Pipeline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Pipeline{}, err
+	}
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		return Parse(data, "json")
+	}
+	return Parse(data, "yaml")
+}
+
+// Parse decodes and validates a pipeline written in json or yaml.
+func Parse(data []byte, format string) (Pipeline, error) {
+	var p Pipeline
+	switch format {
+	case "json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&p); err != nil {
+			return p, fmt.Errorf("pipeline: %w", err)
+		}
+	case "yaml", "yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&p); err != nil {
+			return p, fmt.Errorf("pipeline: %w", err)
+		}
+	default:
+		return p, fmt.Errorf("pipeline: unknown format %v", format)
+	}
+	if p.Save != nil { // The encoders only know the lower case names
+		p.Save.Format = strings.ToLower(p.Save.Format)
+	}
+	return p, p.Validate()
+}
+
+// Validate checks every step with the same rules the UI uses and the save
+// format, without applying anything.
+func (p Pipeline) Validate() error {
+	if _, err := p.Build(); err != nil {
+		return err
+	}
+	if p.Save == nil {
+		return nil
+	}
+	if _, err := p.Save.Options(); err != nil {
+		return fmt.Errorf("save: %w", err)
+	}
+	for _, format := range Formats {
+		if p.Save.Format == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("save: format must be one of %v", strings.Join(Formats, ", "))
+}
+
+// Marshal writes the pipeline in json or yaml, so it can be loaded again.
+func (p Pipeline) Marshal(format string) ([]byte, error) {
+	switch format {
+	case "json":
+		return json.MarshalIndent(p, "", "  ")
+	case "yaml", "yml":
+		return yaml.Marshal(p)
+	}
+	return nil, fmt.Errorf("pipeline: unknown format %v", format)
+}
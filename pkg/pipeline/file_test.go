@@ -0,0 +1,36 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+func TestSaveFormat(t *testing.T) {
+	tests := []struct {
+		data  string
+		want  string
+		valid bool
+	}{
+		{"steps: [{operation: negative}]\nsave: {format: png}", "png", true},
+		{"steps: [{operation: negative}]\nsave: {format: PNG}", "png", true},
+		{"steps: [{operation: negative}]\nsave: {format: Tiff}", "tiff", true},
+		{"steps: [{operation: negative}]\nsave: {format: xyz}", "", false},
+	}
+	for _, test := range tests {
+		p, err := Parse([]byte(test.data), "yaml")
+		if (err == nil) != test.valid {
+			t.Errorf("%q: error %v", test.data, err)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		if p.Save.Format != test.want || !processing.CanEncode(p.Save.Format) {
+			t.Errorf("%q: the format is %q, which the encoders take %v", test.data, p.Save.Format, processing.CanEncode(p.Save.Format))
+		}
+	}
+	if err := (Pipeline{Steps: []Step{{Operation: "negative"}}, Save: &Save{Format: "PNG"}}).Validate(); err == nil {
+		t.Error("a format the encoders don't take is valid")
+	}
+}
@@ -0,0 +1,105 @@
+package pipeline
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/vision-go/vision-go/pkg/convolution"
+)
+
+var borderParam = Param{Name: "border", Default: "replicate", Usage: "border mode: " + strings.Join(convolution.Borders(), ", ")}
+
+func init() {
+	register(&Operation{Name: "convolve", Suffix: ConvolveSuffix, Usage: "filter with a custom kernel",
+		Params: []Param{
+			{Name: "kernel", Usage: "rows separated by ; with the values separated by commas, e.g. 1,2,1;2,4,2;1,2,1"},
+			{Name: "divisor", Default: "auto", Usage: "the weights are divided by it, auto is their sum"},
+			{Name: "offset", Default: "0", Usage: "added to every result"},
+			borderParam,
+		},
+		build: buildConvolve,
+	})
+	register(&Operation{Name: "box-blur", Suffix: BoxBlurSuffix, Usage: "average a square neighbourhood",
+		Params: []Param{{Name: "size", Default: "3", Usage: "odd side of the box"}, borderParam},
+		build: func(step Step) (Func, error) {
+			size, err := step.Int("size")
+			if err != nil {
+				return nil, err
+			}
+			k, err := convolution.Box(size)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %w", step.Operation, err)
+			}
+			return filter(step, k)
+		},
+	})
+	register(&Operation{Name: "gaussian-blur", Suffix: GaussianBlurSuffix, Usage: "gaussian blur",
+		Params: []Param{{Name: "sigma", Default: "1", Usage: "standard deviation in pixels (0, 50]"}, borderParam},
+		build: func(step Step) (Func, error) {
+			sigma, err := step.Float("sigma")
+			if err != nil {
+				return nil, err
+			}
+			k, err := convolution.Gaussian(sigma)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %w", step.Operation, err)
+			}
+			return filter(step, k)
+		},
+	})
+	register(fixedFilter("sharpen", SharpenSuffix, "sharpen the details", convolution.Sharpen()))
+	register(fixedFilter("emboss", EmbossSuffix, "emboss effect", convolution.Emboss()))
+	register(fixedFilter("laplacian", LaplacianSuffix, "laplacian, flat areas become grey", convolution.Laplacian()))
+}
+
+func fixedFilter(name, suffix, usage string, k convolution.Kernel) *Operation {
+	return &Operation{Name: name, Suffix: suffix, Usage: usage,
+		Params: []Param{borderParam},
+		build: func(step Step) (Func, error) {
+			return filter(step, k)
+		},
+	}
+}
+
+// filter convolves with k and the border of step.
+func filter(step Step, k convolution.Kernel) (Func, error) {
+	name, err := step.Text("border")
+	if err != nil {
+		return nil, err
+	}
+	border, err := convolution.ParseBorder(name)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", step.Operation, err)
+	}
+	return func(img image.Image) (image.Image, error) {
+		return convolution.Convolve(img, k, border), nil
+	}, nil
+}
+
+func buildConvolve(step Step) (Func, error) {
+	text, err := step.Text("kernel")
+	if err != nil {
+		return nil, err
+	}
+	k, err := convolution.Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", step.Operation, err)
+	}
+	if divisor, _ := step.Text("divisor"); divisor == "auto" {
+		k = k.Normalize()
+	} else {
+		value, err := step.Float("divisor")
+		if err != nil {
+			return nil, err
+		}
+		if value == 0 {
+			return nil, fmt.Errorf("%v: the divisor can't be 0", step.Operation)
+		}
+		k = k.Scale(1 / value)
+	}
+	if k.Offset, err = step.Float("offset"); err != nil {
+		return nil, err
+	}
+	return filter(step, k)
+}
@@ -0,0 +1,87 @@
+package pipeline
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/vision-go/vision-go/pkg/morphology"
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+func init() {
+	register(morphologyOp("erode", ErodeSuffix, "erosion, shrinks the bright areas", morphology.Erode))
+	register(morphologyOp("dilate", DilateSuffix, "dilation, grows the bright areas", morphology.Dilate))
+	register(morphologyOp("open", OpenSuffix, "opening, removes the bright details smaller than the element", morphology.Open))
+	register(morphologyOp("close", CloseSuffix, "closing, fills the dark details smaller than the element", morphology.Close))
+	register(morphologyOp("morph-gradient", MorphGradientSuffix, "dilation minus erosion", morphology.Gradient))
+	register(morphologyOp("top-hat", TopHatSuffix, "image minus its opening", morphology.TopHat))
+	register(morphologyOp("black-hat", BlackHatSuffix, "closing minus the image", morphology.BlackHat))
+	register(binaryMorphologyOp("hit-or-miss", HitOrMissSuffix, "binary hit-or-miss transform, 1 foreground, 0 background, x don't care", morphology.HitOrMiss))
+	register(binaryMorphologyOp("skeleton", SkeletonSuffix, "binary morphological skeleton", morphology.Skeleton))
+}
+
+func morphologyOp(name, suffix, usage string, op func(image.Image, morphology.Element) image.Image) *Operation {
+	return &Operation{Name: name, Suffix: suffix, Usage: usage,
+		Params: []Param{
+			{Name: "element", Default: "square", Usage: "structuring element: " + strings.Join(morphology.Shapes(), ", ") +
+				" or the rows of a custom one separated by ; with the cells separated by commas, e.g. 0,1,0;1,1,1;0,1,0"},
+			{Name: "size", Default: "3", Usage: "odd side of the square, cross or disk"},
+		},
+		build: func(step Step) (Func, error) {
+			e, err := structuringElement(step)
+			if err != nil {
+				return nil, err
+			}
+			return func(img image.Image) (image.Image, error) {
+				return op(img, e), nil
+			}, nil
+		},
+	}
+}
+
+// binaryMorphologyOp is a morphologyOp whose foreground comes from the grey
+// levels, so it also takes their weights.
+func binaryMorphologyOp(name, suffix, usage string, op func(image.Image, morphology.Element, processing.Luminance) image.Image) *Operation {
+	binary := morphologyOp(name, suffix, usage, nil)
+	binary.Params = append(binary.Params, luminanceParam)
+	binary.build = func(step Step) (Func, error) {
+		e, err := structuringElement(step)
+		if err != nil {
+			return nil, err
+		}
+		l, err := step.Luminance()
+		if err != nil {
+			return nil, err
+		}
+		return func(img image.Image) (image.Image, error) {
+			return op(img, e, l), nil
+		}, nil
+	}
+	return binary
+}
+
+// structuringElement returns the shape of the element parameter with the
+// given size, or the custom element it writes.
+func structuringElement(step Step) (morphology.Element, error) {
+	name, err := step.Text("element")
+	if err != nil {
+		return morphology.Element{}, err
+	}
+	var e morphology.Element
+	if shape, shapeErr := morphology.ParseShape(name); shapeErr == nil {
+		size, sizeErr := step.Int("size")
+		if sizeErr != nil {
+			return morphology.Element{}, sizeErr
+		}
+		e, err = morphology.New(shape, size)
+	} else if strings.ContainsAny(name, "01") {
+		e, err = morphology.Parse(name)
+	} else {
+		err = fmt.Errorf("%v or a custom element", shapeErr)
+	}
+	if err != nil {
+		return morphology.Element{}, fmt.Errorf("%v: %w", step.Operation, err)
+	}
+	return e, nil
+}
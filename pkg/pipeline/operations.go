@@ -0,0 +1,600 @@
+package pipeline
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// Func applies an already configured operation.
+type Func func(image.Image) (image.Image, error)
+
+// maskedFunc is a Func taking its statistics only from the pixels inside
+// mask, in the coordinates of img, or from every pixel if mask is nil.
+type maskedFunc func(img image.Image, mask *image.Alpha) (image.Image, error)
+
+// Param describes a parameter of an operation. Default is used when the
+// step doesn't give a value; an empty Default makes the parameter required.
+type Param struct {
+	Name    string
+	Default string
+	Usage   string
+}
+
+// Operation is an image operation that can be reached without the UI.
+type Operation struct {
+	Name   string
+	Suffix string // One of the suffixes, which OurImage adds to the name of the image too
+	Usage  string
+	Params []Param
+	build  func(Step) (Func, error)
+	// buildMasked replaces build for the operations computing statistics,
+	// which only take them from the region of their step.
+	buildMasked func(Step) (maskedFunc, error)
+	// ownRegion operations use the region of their step themselves instead
+	// of being restricted to it.
+	ownRegion bool
+}
+
+var operations = map[string]*Operation{}
+
+func register(op *Operation) {
+	operations[op.Name] = op
+}
+
+// Lookup returns the operation registered with the given name.
+func Lookup(name string) (*Operation, error) {
+	op, ok := operations[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown operation %q", name)
+	}
+	return op, nil
+}
+
+// Operations returns every registered operation sorted by name.
+func Operations() []*Operation {
+	list := make([]*Operation, 0, len(operations))
+	for _, op := range operations {
+		list = append(list, op)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// Build checks the parameters of step and returns the operation ready to be
+// applied. Missing parameters take their default value.
+func Build(step Step) (Func, error) {
+	op, err := Lookup(step.Operation)
+	if err != nil {
+		return nil, err
+	}
+	if step.Unreplayable != "" {
+		return nil, fmt.Errorf("%v can't be applied again: %v", op.Name, step.Unreplayable)
+	}
+	params := make(map[string]interface{}, len(step.Params))
+	for name, value := range step.Params {
+		params[name] = value
+	}
+	for _, param := range op.Params {
+		if _, ok := params[param.Name]; ok {
+			continue
+		}
+		if param.Default == "" {
+			return nil, fmt.Errorf("%v: missing parameter %v", op.Name, param.Name)
+		}
+		params[param.Name] = param.Default
+	}
+	for name := range params {
+		if !op.hasParam(name) {
+			return nil, fmt.Errorf("%v: unknown parameter %v", op.Name, name)
+		}
+	}
+	if step.Region != nil {
+		if err := step.Region.Validate(); err != nil {
+			return nil, fmt.Errorf("%v: %w", op.Name, err)
+		}
+	}
+	built := Step{Operation: op.Name, Params: params, Region: step.Region}
+	if op.buildMasked != nil {
+		f, err := op.buildMasked(built)
+		if err != nil {
+			return nil, err
+		}
+		if step.Region == nil {
+			return func(img image.Image) (image.Image, error) {
+				return f(img, nil)
+			}, nil
+		}
+		return restrict(f, *step.Region), nil
+	}
+	f, err := op.build(built)
+	if err != nil || step.Region == nil || op.ownRegion {
+		return f, err
+	}
+	return restrict(func(img image.Image, _ *image.Alpha) (image.Image, error) {
+		return f(img)
+	}, *step.Region), nil
+}
+
+// restrict applies f only to region, see Region.
+func restrict(f maskedFunc, region Region) Func {
+	return func(img image.Image) (image.Image, error) {
+		mask, rect, err := regionOf(img, region)
+		if err != nil {
+			return nil, err
+		}
+		result, err := f(processing.ROI(img, rect), processing.ROIMask(mask, rect))
+		if err != nil {
+			return nil, err
+		}
+		if result.Bounds().Size() != rect.Size() {
+			return result, nil
+		}
+		return processing.Paste(img, result, rect.Min, mask), nil
+	}
+}
+
+// regionOf returns the mask of region on img and its bounding box.
+func regionOf(img image.Image, region Region) (*image.Alpha, image.Rectangle, error) {
+	mask, err := region.Mask(img)
+	if err != nil {
+		return nil, image.Rectangle{}, err
+	}
+	rect := region.Rectangle()
+	if mask != nil {
+		rect = mask.Bounds()
+	}
+	if rect.Empty() || !rect.In(img.Bounds()) {
+		return nil, rect, fmt.Errorf("the region %v is outside of the image %v", rect, img.Bounds())
+	}
+	return mask, rect, nil
+}
+
+func (op *Operation) hasParam(name string) bool {
+	for _, param := range op.Params {
+		if param.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// luminanceParam are the weights of the grey levels of the operations that
+// compute them.
+var luminanceParam = Param{Name: "luminance", Default: "pal",
+	Usage: "weights of the grey levels: " + strings.Join(processing.Luminances(), ", ") + " or r,g,b"}
+
+func simple(name, suffix, usage string, f func(image.Image) image.Image) *Operation {
+	return &Operation{Name: name, Suffix: suffix, Usage: usage,
+		build: func(Step) (Func, error) {
+			return func(img image.Image) (image.Image, error) {
+				return f(img), nil
+			}, nil
+		},
+	}
+}
+
+func init() {
+	register(simple("negative", NegativeSuffix, "invert every channel", processing.Negative))
+	register(&Operation{Name: "monochrome", Suffix: MonochromeSuffix, Usage: "convert to grey levels",
+		Params: []Param{luminanceParam},
+		build: func(step Step) (Func, error) {
+			l, err := step.Luminance()
+			if err != nil {
+				return nil, err
+			}
+			return func(img image.Image) (image.Image, error) {
+				return processing.Monochrome(img, l), nil
+			}, nil
+		},
+	})
+	register(&Operation{Name: "equalize", Suffix: EqualizeSuffix, Usage: "equalize the histogram of every channel",
+		buildMasked: func(Step) (maskedFunc, error) {
+			return func(img image.Image, mask *image.Alpha) (image.Image, error) {
+				return processing.Equalization(img, mask), nil
+			}, nil
+		},
+	})
+	register(simple("hmirror", HMirrorSuffix, "mirror horizontally", processing.HorizontalMirror))
+	register(simple("vmirror", VMirrorSuffix, "mirror vertically", processing.VerticalMirror))
+	register(simple("rotate-right", RotateRightSuffix, "rotate 90 degrees clockwise", processing.RotateRight))
+	register(simple("rotate-left", RotateLeftSuffix, "rotate 90 degrees counterclockwise", processing.RotateLeft))
+	register(simple("transpose", TransposeSuffix, "swap rows and columns", processing.Transpose))
+
+	register(&Operation{Name: "brightness-contrast", Suffix: BrightnessContrastSuffix, Usage: "set the brightness and the contrast",
+		Params: []Param{
+			{Name: "brightness", Usage: "new brightness [0, 255]"},
+			{Name: "contrast", Usage: "new contrast [0, 255]"},
+			spaceParam,
+			channelParam,
+			luminanceParam,
+		},
+		buildMasked: onChannelMasked(buildBrightnessAndContrast),
+	})
+	register(&Operation{Name: "brightness-contrast-rgb", Suffix: BrightnessContrastRGBSuffix, Usage: "set the brightness and the contrast of each colour channel",
+		Params:      channelsBrightnessAndContrastParams(),
+		buildMasked: buildChannelsBrightnessAndContrast,
+	})
+	register(&Operation{Name: "gamma", Suffix: GammaSuffix, Usage: "gamma correction",
+		Params: []Param{{Name: "value", Usage: "gamma in [0.05, 20]"}, spaceParam, channelParam},
+		build:  onChannel(buildGamma),
+	})
+	register(&Operation{Name: "linear", Suffix: LinearSuffix, Usage: "linear transformation by sections",
+		Params: []Param{{Name: "points", Usage: "points of the sections as x:y,x:y (values in [0, 255])"}, spaceParam, channelParam},
+		build:  onChannel(buildLinearTransformation),
+	})
+	register(&Operation{Name: "rescale", Suffix: RescaleSuffix, Usage: "rescale the image",
+		Params: []Param{
+			{Name: "factor", Usage: "scale in % [1, 500]"},
+			{Name: "interp", Default: "vmp", Usage: "interpolation: vmp or bilinear"},
+		},
+		build: buildRescaling,
+	})
+	register(&Operation{Name: "rotate", Suffix: RotateSuffix, Usage: "rotate by any angle",
+		Params: []Param{
+			{Name: "angle", Usage: "angle in degrees"},
+			{Name: "interp", Default: "vmp", Usage: "interpolation: vmp or bilinear"},
+		},
+		build: buildRotate,
+	})
+	register(&Operation{Name: "rotate-print", Suffix: RotatePrintSuffix, Usage: "rotate by any angle with a direct mapping",
+		Params: []Param{{Name: "angle", Usage: "angle in degrees"}},
+		build:  buildRotateAndPrint,
+	})
+	register(&Operation{Name: "roi", Suffix: ROISuffix, Usage: "crop a region of interest",
+		Params: []Param{
+			{Name: "x", Default: "0", Usage: "left column"},
+			{Name: "y", Default: "0", Usage: "top row"},
+			{Name: "width", Usage: "width of the region"},
+			{Name: "height", Usage: "height of the region"},
+		},
+		build: buildROI,
+	})
+	register(&Operation{Name: "crop", Suffix: CropSuffix, Usage: "crop the region of the step, transparent outside of its shape",
+		build: buildCrop, ownRegion: true})
+	register(&Operation{Name: "specify", Suffix: SpecifySuffix, Usage: "match the histogram of a reference image",
+		Params:      []Param{{Name: "reference", Usage: "path of the reference image"}},
+		buildMasked: buildHistogramIgualation,
+	})
+	register(&Operation{Name: "difference", Suffix: DifferenceSuffix, Usage: "absolute difference with a reference image",
+		Params: []Param{{Name: "reference", Usage: "path of the reference image"}},
+		build:  buildImageDifference,
+	})
+	register(&Operation{Name: "change-map", Suffix: ChangeMapSuffix, Usage: "paint the pixels that changed from a reference image",
+		Params: []Param{
+			{Name: "reference", Usage: "path of the reference image"},
+			{Name: "threshold", Usage: "grey level difference T [0, 255]"},
+			{Name: "color", Default: "#ff0000", Usage: "colour of the changes as #rrggbb"},
+			luminanceParam,
+		},
+		build: buildChangeMap,
+	})
+}
+
+func buildBrightnessAndContrast(step Step) (maskedFunc, error) {
+	brightness, err := step.Float("brightness")
+	if err != nil {
+		return nil, err
+	}
+	contrast, err := step.Float("contrast")
+	if err != nil {
+		return nil, err
+	}
+	if brightness < 0 || brightness > 255 || contrast < 0 || contrast > 255 {
+		return nil, fmt.Errorf("%v: brightness and contrast must be in the range [0, 255]", step.Operation)
+	}
+	l, err := step.Luminance()
+	if err != nil {
+		return nil, err
+	}
+	return func(img image.Image, mask *image.Alpha) (image.Image, error) {
+		stats := processing.NewMaskedStatistics(img, mask, l)
+		return processing.BrightnessAndContrast(img, stats.Brightness, stats.Contrast, brightness, contrast), nil
+	}, nil
+}
+
+// keep is the value of the parameters of brightness-contrast-rgb that leave
+// a channel as it is.
+const keep = "keep"
+
+var rgbChannels = []string{"r", "g", "b"}
+
+func channelsBrightnessAndContrastParams() (params []Param) {
+	for _, name := range []string{"brightness", "contrast"} {
+		for _, channel := range rgbChannels {
+			params = append(params, Param{Name: name + "-" + channel, Default: keep, Usage: "new " + name + " of " + channel + " [0, 255] or " + keep})
+		}
+	}
+	return params
+}
+
+func buildChannelsBrightnessAndContrast(step Step) (maskedFunc, error) {
+	var values [2][3]float64 // Brightness and contrast, negative to keep
+	for i, name := range []string{"brightness", "contrast"} {
+		for j, channel := range rgbChannels {
+			values[i][j] = -1
+			if text, _ := step.Text(name + "-" + channel); strings.EqualFold(text, keep) {
+				continue
+			}
+			value, err := step.Float(name + "-" + channel)
+			if err != nil {
+				return nil, err
+			}
+			if value < 0 || value > 255 {
+				return nil, fmt.Errorf("%v: brightness and contrast must be in the range [0, 255]", step.Operation)
+			}
+			values[i][j] = value
+		}
+	}
+	return func(img image.Image, mask *image.Alpha) (image.Image, error) {
+		stats := processing.NewMaskedStatistics(img, mask, processing.PAL) // Only the channels, the weights don't matter
+		brightness, contrast := stats.ChannelBrightness, stats.ChannelContrast
+		for j := range rgbChannels {
+			if values[0][j] >= 0 {
+				brightness[j] = values[0][j]
+			}
+			if values[1][j] >= 0 {
+				contrast[j] = values[1][j]
+			}
+		}
+		return processing.ChannelsBrightnessAndContrast(img, stats.ChannelBrightness, stats.ChannelContrast, brightness, contrast), nil
+	}, nil
+}
+
+func buildGamma(step Step) (Func, error) {
+	gamma, err := step.Float("value")
+	if err != nil {
+		return nil, err
+	}
+	if gamma < 0.05 || gamma > 20 {
+		return nil, fmt.Errorf("gamma must be between values 0.05 and 20")
+	}
+	return func(img image.Image) (image.Image, error) {
+		return processing.GammaCorrection(img, gamma), nil
+	}, nil
+}
+
+func buildLinearTransformation(step Step) (Func, error) {
+	points, err := step.Points("points")
+	if err != nil {
+		return nil, err
+	}
+	if len(points) < 2 {
+		return nil, fmt.Errorf("the number of points must greater than two")
+	}
+	for _, point := range points {
+		if err := point.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return func(img image.Image) (image.Image, error) {
+		return processing.LinearTransformation(img, points), nil
+	}, nil
+}
+
+func interpolation(step Step) (int, error) {
+	interp, err := step.Text("interp")
+	if err != nil {
+		return 0, err
+	}
+	switch strings.ToLower(interp) {
+	case "vmp", "nearest":
+		return processing.VMP, nil
+	case "bilinear", "bilineal":
+		return processing.Bilineal, nil
+	}
+	return 0, fmt.Errorf("%v: interpolation must be vmp or bilinear", step.Operation)
+}
+
+func buildRescaling(step Step) (Func, error) {
+	factor, err := step.Float("factor")
+	if err != nil {
+		return nil, err
+	}
+	if factor < 1 || factor > 500 {
+		return nil, fmt.Errorf("rescalingfactor must be between values 1 and 500")
+	}
+	interp, err := interpolation(step)
+	if err != nil {
+		return nil, err
+	}
+	return func(img image.Image) (image.Image, error) {
+		return processing.Rescaling(img, factor/100, interp == processing.VMP), nil
+	}, nil
+}
+
+func buildRotate(step Step) (Func, error) {
+	angle, err := step.Float("angle")
+	if err != nil {
+		return nil, err
+	}
+	interp, err := interpolation(step)
+	if err != nil {
+		return nil, err
+	}
+	return func(img image.Image) (image.Image, error) {
+		return processing.Rotate(img, angle, interp), nil
+	}, nil
+}
+
+func buildRotateAndPrint(step Step) (Func, error) {
+	angle, err := step.Float("angle")
+	if err != nil {
+		return nil, err
+	}
+	return func(img image.Image) (image.Image, error) {
+		return processing.RotateAndPrint(img, angle), nil
+	}, nil
+}
+
+func buildROI(step Step) (Func, error) {
+	var values [4]int
+	for i, name := range []string{"x", "y", "width", "height"} {
+		value, err := step.Int(name)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	rect := image.Rect(values[0], values[1], values[0]+values[2], values[1]+values[3])
+	if values[2] <= 0 || values[3] <= 0 {
+		return nil, fmt.Errorf("%v: width and height must be positive", step.Operation)
+	}
+	return func(img image.Image) (image.Image, error) {
+		if !rect.In(img.Bounds()) {
+			return nil, fmt.Errorf("the region %v is outside of the image %v", rect, img.Bounds())
+		}
+		return processing.ROI(img, rect), nil
+	}, nil
+}
+
+func buildCrop(step Step) (Func, error) {
+	if step.Region == nil {
+		return nil, fmt.Errorf("%v: missing region", step.Operation)
+	}
+	region := *step.Region
+	return func(img image.Image) (image.Image, error) {
+		mask, rect, err := regionOf(img, region)
+		if err != nil {
+			return nil, err
+		}
+		if mask == nil {
+			return processing.ROI(img, rect), nil
+		}
+		return processing.CropMasked(img, mask), nil
+	}, nil
+}
+
+// loader opens the image at path the first time it is called, so that
+// building a step doesn't read files, and returns the same image afterwards
+// however many images the step is applied to.
+func loader(step Step, path string) func() (image.Image, error) {
+	var once sync.Once
+	var img image.Image
+	var err error
+	return func() (image.Image, error) {
+		once.Do(func() {
+			img, _, err = processing.Open(path)
+			if err != nil {
+				err = fmt.Errorf("%v: %w", step.Operation, err)
+			}
+		})
+		return img, err
+	}
+}
+
+// reference loads the reference image of step when it is applied.
+func reference(step Step) (func() (image.Image, error), error) {
+	path, err := step.Text("reference")
+	if err != nil {
+		return nil, err
+	}
+	return loader(step, path), nil
+}
+
+// compared is the reference of the operations between two images, cropped
+// to the region of the step so it can be compared with it.
+func compared(step Step) (func() (image.Image, error), error) {
+	load, err := reference(step)
+	if err != nil {
+		return nil, err
+	}
+	return func() (image.Image, error) {
+		img, err := load()
+		if err != nil {
+			return nil, err
+		}
+		return croppedToRegion(step, img), nil
+	}, nil
+}
+
+// croppedToRegion crops an image of the same size as the input to the region
+// of the step.
+func croppedToRegion(step Step, img image.Image) image.Image {
+	if step.Region == nil {
+		return img
+	}
+	rect := step.Region.Rectangle()
+	if rect.In(img.Bounds()) && rect.Size() != img.Bounds().Size() {
+		return processing.ROI(img, rect)
+	}
+	return img
+}
+
+func buildHistogramIgualation(step Step) (maskedFunc, error) {
+	load, err := reference(step)
+	if err != nil {
+		return nil, err
+	}
+	return func(img image.Image, mask *image.Alpha) (image.Image, error) {
+		ref, err := load()
+		if err != nil {
+			return nil, err
+		}
+		return processing.HistogramIgualation(img, ref, mask), nil
+	}, nil
+}
+
+func buildImageDifference(step Step) (Func, error) {
+	load, err := compared(step)
+	if err != nil {
+		return nil, err
+	}
+	return func(img image.Image) (image.Image, error) {
+		ref, err := load()
+		if err != nil {
+			return nil, err
+		}
+		return processing.ImageDiference(img, ref)
+	}, nil
+}
+
+func buildChangeMap(step Step) (Func, error) {
+	T, err := step.Int("threshold")
+	if err != nil {
+		return nil, err
+	}
+	if T < 0 || T > 255 {
+		return nil, fmt.Errorf("the values must be integers in the range [0, 255]")
+	}
+	hex, err := step.Text("color")
+	if err != nil {
+		return nil, err
+	}
+	colour, err := parseColor(hex)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", step.Operation, err)
+	}
+	l, err := step.Luminance()
+	if err != nil {
+		return nil, err
+	}
+	load, err := compared(step)
+	if err != nil {
+		return nil, err
+	}
+	return func(img image.Image) (image.Image, error) {
+		ref, err := load()
+		if err != nil {
+			return nil, err
+		}
+		return processing.ChangeMap(img, ref, colour, T, l)
+	}, nil
+}
+
+func parseColor(hex string) (color.Color, error) {
+	hex = strings.TrimPrefix(hex, "#")
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 6 {
+		return nil, fmt.Errorf("colours must be written as #rrggbb")
+	}
+	return color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 255}, nil
+}
@@ -0,0 +1,81 @@
+package pipeline
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// halves is black on its left half and has columns of 100 and 200 on its
+// right half.
+func halves() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		img.SetGray(2, y, color.Gray{Y: 100})
+		img.SetGray(3, y, color.Gray{Y: 200})
+	}
+	return img
+}
+
+func TestMaskedRegion(t *testing.T) {
+	tests := []struct {
+		spec string
+		want [4]uint8 // First row of the result
+	}{
+		{"equalize", [4]uint8{0, 0, 127, 191}},
+		// The wand selects the grey half, the black pixels don't count
+		{"equalize region=2,0,2,2 region-shape=wand region-points=3:0 region-tolerance=100", [4]uint8{0, 0, 0, 127}},
+		{"equalize region=1,0,3,2", [4]uint8{0, 0, 84, 170}},
+		{"brightness-contrast brightness=100 contrast=50 region=0,0,4,2 region-shape=wand region-points=3:0 region-tolerance=100",
+			[4]uint8{0, 0, 50, 150}},
+	}
+	for _, test := range tests {
+		step, err := ParseStep(test.spec)
+		if err != nil {
+			t.Fatalf("%v: %v", test.spec, err)
+		}
+		f, err := Build(step)
+		if err != nil {
+			t.Fatalf("%v: %v", test.spec, err)
+		}
+		result, err := f(halves())
+		if err != nil {
+			t.Fatalf("%v: %v", test.spec, err)
+		}
+		for x, want := range test.want {
+			if r, _, _, _ := result.At(x, 0).RGBA(); uint8(r>>8) != want {
+				t.Errorf("%v: pixel %v is %v, want %v", test.spec, x, r>>8, want)
+			}
+		}
+	}
+}
+
+func TestReferenceOpenedWhenApplied(t *testing.T) {
+	for _, spec := range []string{
+		"specify reference=missing.png",
+		"difference reference=missing.png",
+		"change-map reference=missing.png threshold=10",
+		"merge space=rgb channels=missing.png,missing.png",
+	} {
+		step, err := ParseStep(spec)
+		if err != nil {
+			t.Fatalf("%v: %v", spec, err)
+		}
+		f, err := Build(step)
+		if err != nil {
+			t.Errorf("%v: building reads the reference: %v", spec, err)
+			continue
+		}
+		if _, err := f(halves()); err == nil {
+			t.Errorf("%v: a missing reference is applied", spec)
+		}
+	}
+}
+
+func TestName(t *testing.T) {
+	p := Pipeline{Steps: []Step{{Operation: "rescale"}, {Operation: "change-map"}, {Operation: "negative"}}}
+	want := "a(" + RescaleSuffix + ")(" + ChangeMapSuffix + ")(" + NegativeSuffix + ").png"
+	if name := p.Name("a.png"); name != want {
+		t.Errorf("name %v, want %v", name, want)
+	}
+}
@@ -0,0 +1,92 @@
+package pipeline
+
+import (
+	"fmt"
+	"image"
+	"strings"
+)
+
+// Pipeline is a chain of operations applied one after another.
+type Pipeline struct {
+	Steps []Step `json:"steps" yaml:"steps"`
+	Save  *Save  `json:"save,omitempty" yaml:"save,omitempty"`
+}
+
+// Build checks every step and returns a function applying all of them.
+func (p Pipeline) Build() (Func, error) {
+	if len(p.Steps) == 0 {
+		return nil, fmt.Errorf("the pipeline has no operations")
+	}
+	funcs := make([]Func, len(p.Steps))
+	for i, step := range p.Steps {
+		f, err := Build(step)
+		if err != nil {
+			return nil, fmt.Errorf("step %v: %w", i+1, err)
+		}
+		funcs[i] = f
+	}
+	return func(img image.Image) (image.Image, error) {
+		var err error
+		for i, f := range funcs {
+			img, err = f(img)
+			if err != nil {
+				return nil, fmt.Errorf("step %v (%v): %w", i+1, p.Steps[i].Operation, err)
+			}
+		}
+		return img, nil
+	}, nil
+}
+
+// Name returns the name an image called original gets after the pipeline,
+// the same one the UI would show after applying every step.
+func (p Pipeline) Name(original string) string {
+	for _, step := range p.Steps {
+		if op, err := Lookup(step.Operation); err == nil {
+			original = AddOperationToName(original, op.Suffix)
+		}
+	}
+	return original
+}
+
+// AddOperationToName adds "(action)" before the extension of name.
+func AddOperationToName(name, action string) string {
+	if action == "" {
+		return name
+	}
+	action = "(" + action + ")"
+	pointIndex := strings.LastIndex(name, ".")
+	if pointIndex == -1 {
+		return name + action
+	}
+	return name[:pointIndex] + action + name[pointIndex:]
+}
+
+// ParseStep reads a step written as "operation key=value key=value", the
+// way chains are given in the command line and in the batch dialog.
+func ParseStep(spec string) (Step, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return Step{}, fmt.Errorf("empty operation")
+	}
+	step := Step{Operation: fields[0], Params: map[string]interface{}{}}
+	for _, field := range fields[1:] {
+		pair := strings.SplitN(field, "=", 2)
+		if len(pair) != 2 || pair[0] == "" {
+			return Step{}, fmt.Errorf("%v: parameters must be written as key=value, got %q", step.Operation, field)
+		}
+		if strings.HasPrefix(pair[0], "region") {
+			if step.Region == nil {
+				step.Region = &Region{}
+			}
+			if err := step.Region.Set(pair[0], pair[1]); err != nil {
+				return Step{}, fmt.Errorf("%v: %w", step.Operation, err)
+			}
+			continue
+		}
+		step.Params[pair[0]] = pair[1]
+	}
+	if _, err := Lookup(step.Operation); err != nil {
+		return Step{}, err
+	}
+	return step, nil
+}
@@ -0,0 +1,73 @@
+package pipeline
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Record is an operation applied to an image and when it happened.
+type Record struct {
+	Step `yaml:",inline"`
+	Time time.Time `json:"time" yaml:"time"`
+}
+
+// Provenance tells where an image comes from: the file it was opened from
+// and every operation applied since, with its parameters.
+type Provenance struct {
+	Source     string   `json:"source" yaml:"source"`
+	Operations []Record `json:"operations,omitempty" yaml:"operations,omitempty"`
+}
+
+// With returns a copy of the provenance with steps added now.
+func (p Provenance) With(steps ...Step) Provenance {
+	operations := make([]Record, len(p.Operations), len(p.Operations)+len(steps))
+	copy(operations, p.Operations)
+	now := time.Now()
+	for _, step := range steps {
+		operations = append(operations, Record{Step: step, Time: now})
+	}
+	return Provenance{Source: p.Source, Operations: operations}
+}
+
+// Pipeline returns the operations as a pipeline, so the same result can be
+// obtained from another image. It fails if any of them is unreplayable.
+func (p Provenance) Pipeline() (Pipeline, error) {
+	steps := make([]Step, len(p.Operations))
+	for i, record := range p.Operations {
+		if record.Unreplayable != "" {
+			return Pipeline{}, fmt.Errorf("operation %v, %v, can't be applied again: %v", i+1, record.Operation, record.Unreplayable)
+		}
+		steps[i] = record.Step
+	}
+	return Pipeline{Steps: steps}, nil
+}
+
+func (p Provenance) String() string {
+	message := "Source: " + p.Source
+	for i, record := range p.Operations {
+		message += fmt.Sprintf("\n%v. %v (%v)", i+1, record.Step, record.Time.Format("2006-01-02 15:04:05"))
+		if record.Unreplayable != "" {
+			message += ", not replayable: " + record.Unreplayable
+		}
+	}
+	return message
+}
+
+// String writes the step the way ParseStep reads it.
+func (step Step) String() string {
+	names := make([]string, 0, len(step.Params))
+	for name := range step.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	text := []string{step.Operation}
+	for _, name := range names {
+		text = append(text, fmt.Sprintf("%v=%v", name, step.Params[name]))
+	}
+	if step.Region != nil {
+		text = append(text, step.Region.String())
+	}
+	return strings.Join(text, " ")
+}
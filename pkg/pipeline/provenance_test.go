@@ -0,0 +1,27 @@
+package pipeline
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnreplayable(t *testing.T) {
+	replayable := Step{Operation: "negative"}
+	unreplayable := Step{Operation: "difference", Params: map[string]interface{}{"reference": "b.png"},
+		Unreplayable: "b.png was modified after opening b.png"}
+
+	p := Provenance{Source: "a.png"}.With(replayable)
+	if pipeline, err := p.Pipeline(); err != nil || len(pipeline.Steps) != 1 {
+		t.Errorf("a replayable provenance gives %v, %v", pipeline, err)
+	}
+	p = p.With(unreplayable)
+	if _, err := p.Pipeline(); err == nil {
+		t.Errorf("an unreplayable step is exported")
+	}
+	if !strings.Contains(p.String(), "not replayable: "+unreplayable.Unreplayable) {
+		t.Errorf("the provenance doesn't tell the step is unreplayable:\n%v", p)
+	}
+	if _, err := Build(unreplayable); err == nil {
+		t.Errorf("an unreplayable step is built")
+	}
+}
@@ -0,0 +1,165 @@
+package pipeline
+
+import (
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// Shapes of a region.
+const (
+	RectangleShape = "rectangle"
+	EllipseShape   = "ellipse"
+	PolygonShape   = "polygon"
+	WandShape      = "wand"
+)
+
+// Region restricts a step to a part of the image. If the operation keeps
+// the size of the region the result is pasted back into the image,
+// otherwise the result is the transformed region alone.
+//
+// By default the region is the rectangle X, Y, Width, Height. An ellipse is
+// inscribed in it, a polygon has the vertices of Points and a wand selects
+// the pixels connected to Points, the seed, whose colour differs less than
+// Tolerance from it.
+type Region struct {
+	X         int    `json:"x" yaml:"x"`
+	Y         int    `json:"y" yaml:"y"`
+	Width     int    `json:"width" yaml:"width"`
+	Height    int    `json:"height" yaml:"height"`
+	Shape     string `json:"shape,omitempty" yaml:"shape,omitempty"`
+	Points    string `json:"points,omitempty" yaml:"points,omitempty"`
+	Tolerance int    `json:"tolerance,omitempty" yaml:"tolerance,omitempty"`
+}
+
+// NewRegion returns the region of a rectangle.
+func NewRegion(rect image.Rectangle) *Region {
+	return &Region{X: rect.Min.X, Y: rect.Min.Y, Width: rect.Dx(), Height: rect.Dy()}
+}
+
+func (region Region) Rectangle() image.Rectangle {
+	return image.Rect(region.X, region.Y, region.X+region.Width, region.Y+region.Height)
+}
+
+// Vertices returns the points of a polygon or the seed of a wand.
+func (region Region) Vertices() ([]image.Point, error) {
+	points, err := Step{Operation: "region", Params: map[string]interface{}{"points": region.Points}}.Points("points")
+	if err != nil {
+		return nil, err
+	}
+	vertices := make([]image.Point, len(points))
+	for i, point := range points {
+		vertices[i] = image.Pt(point.X, point.Y)
+	}
+	return vertices, nil
+}
+
+// SetVertices sets the points of a polygon or the seed of a wand.
+func (region *Region) SetVertices(vertices []image.Point) {
+	pairs := make([]string, len(vertices))
+	for i, vertex := range vertices {
+		pairs[i] = strconv.Itoa(vertex.X) + ":" + strconv.Itoa(vertex.Y)
+	}
+	region.Points = strings.Join(pairs, ",")
+}
+
+func (region Region) Validate() error {
+	if region.Width <= 0 || region.Height <= 0 {
+		return fmt.Errorf("the width and the height of a region must be positive")
+	}
+	switch region.Shape {
+	case "", RectangleShape, EllipseShape:
+	case PolygonShape:
+		vertices, err := region.Vertices()
+		if err != nil {
+			return err
+		}
+		if len(vertices) < 3 {
+			return fmt.Errorf("a polygon needs at least 3 points")
+		}
+	case WandShape:
+		vertices, err := region.Vertices()
+		if err != nil {
+			return err
+		}
+		if len(vertices) != 1 {
+			return fmt.Errorf("a wand needs a single seed point")
+		}
+		if region.Tolerance < 0 || region.Tolerance > 255 {
+			return fmt.Errorf("the tolerance must be in the range [0, 255]")
+		}
+	default:
+		return fmt.Errorf("unknown region shape %q", region.Shape)
+	}
+	return nil
+}
+
+// Mask returns the mask of the region on img, nil for rectangles.
+func (region Region) Mask(img image.Image) (*image.Alpha, error) {
+	if err := region.Validate(); err != nil {
+		return nil, err
+	}
+	switch region.Shape {
+	case EllipseShape:
+		return processing.EllipseMask(region.Rectangle()), nil
+	case PolygonShape:
+		vertices, _ := region.Vertices()
+		return processing.PolygonMask(vertices, img.Bounds()), nil
+	case WandShape:
+		vertices, _ := region.Vertices()
+		return processing.MagicWand(img, vertices[0], region.Tolerance), nil
+	}
+	return nil, nil
+}
+
+// String writes the region as the parameters of ParseStep.
+func (region Region) String() string {
+	text := fmt.Sprintf("region=%v,%v,%v,%v", region.X, region.Y, region.Width, region.Height)
+	if region.Shape != "" && region.Shape != RectangleShape {
+		text += " region-shape=" + region.Shape
+	}
+	if region.Points != "" {
+		text += " region-points=" + region.Points
+	}
+	if region.Shape == WandShape {
+		text += " region-tolerance=" + strconv.Itoa(region.Tolerance)
+	}
+	return text
+}
+
+// Set sets one of the region parameters of ParseStep: region (x,y,width,height),
+// region-shape, region-points or region-tolerance.
+func (region *Region) Set(key, value string) error {
+	switch key {
+	case "region":
+		var values [4]int
+		fields := strings.Split(value, ",")
+		if len(fields) != 4 {
+			return fmt.Errorf("regions must be written as x,y,width,height")
+		}
+		for i, field := range fields {
+			number, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil {
+				return fmt.Errorf("regions must be written as x,y,width,height")
+			}
+			values[i] = number
+		}
+		region.X, region.Y, region.Width, region.Height = values[0], values[1], values[2], values[3]
+	case "region-shape":
+		region.Shape = value
+	case "region-points":
+		region.Points = value
+	case "region-tolerance":
+		tolerance, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("the tolerance must be an integer")
+		}
+		region.Tolerance = tolerance
+	default:
+		return fmt.Errorf("unknown region parameter %v", key)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package pipeline
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/vision-go/vision-go/pkg/histogram"
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+func init() {
+	register(&Operation{Name: "specify-distribution", Suffix: SpecifyDistributionSuffix, Usage: "match the histogram of every channel to a distribution",
+		Params: []Param{
+			{Name: "distribution", Default: "uniform", Usage: "target: " + strings.Join(histogram.Distributions(), ", ")},
+			{Name: "mean", Default: "128", Usage: "mean of the gaussian [0, 255] or of the exponential (0, 255]"},
+			{Name: "sigma", Default: "40", Usage: "standard deviation of the gaussian (0, 255]"},
+			{Name: "points", Default: "0:255,255:255", Usage: "points of the curve as level:frequency, e.g. 0:0,128:255,255:0 (values in [0, 255])"},
+		},
+		buildMasked: buildDistributionSpecification,
+	})
+}
+
+func buildDistributionSpecification(step Step) (maskedFunc, error) {
+	name, err := step.Text("distribution")
+	if err != nil {
+		return nil, err
+	}
+	distribution, err := histogram.ParseDistribution(name)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", step.Operation, err)
+	}
+	mean, err := step.Float("mean")
+	if err != nil {
+		return nil, err
+	}
+	sigma, err := step.Float("sigma")
+	if err != nil {
+		return nil, err
+	}
+	points, err := step.Points("points")
+	if err != nil {
+		return nil, err
+	}
+	target, err := histogram.Target(distribution, mean, sigma, points)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", step.Operation, err)
+	}
+	return func(img image.Image, mask *image.Alpha) (image.Image, error) {
+		return processing.HistogramSpecification(img, target, mask), nil
+	}, nil
+}
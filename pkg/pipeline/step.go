@@ -0,0 +1,139 @@
+package pipeline
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vision-go/vision-go/pkg/histogram"
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// Step is one operation of a chain together with its parameters. Parameter
+// values may be strings (as given in the command line) or the numbers and
+// lists decoded from a pipeline file.
+type Step struct {
+	Operation string                 `json:"operation" yaml:"operation"`
+	Params    map[string]interface{} `json:"params,omitempty" yaml:"params,omitempty"`
+	Region    *Region                `json:"region,omitempty" yaml:"region,omitempty"`
+	// Unreplayable says why a step of a provenance can't be applied again,
+	// such as a reference image that isn't on disk as it was used.
+	Unreplayable string `json:"unreplayable,omitempty" yaml:"unreplayable,omitempty"`
+}
+
+func (step Step) param(name string) (interface{}, bool) {
+	value, ok := step.Params[name]
+	return value, ok
+}
+
+// Float returns the parameter as a number.
+func (step Step) Float(name string) (float64, error) {
+	value, ok := step.param(name)
+	if !ok {
+		return 0, fmt.Errorf("%v: missing parameter %v", step.Operation, name)
+	}
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, fmt.Errorf("%v: parameter %v must be a number", step.Operation, name)
+		}
+		return f, nil
+	}
+	return 0, fmt.Errorf("%v: parameter %v must be a number", step.Operation, name)
+}
+
+// Int returns the parameter as an integer.
+func (step Step) Int(name string) (int, error) {
+	f, err := step.Float(name)
+	if err != nil {
+		return 0, err
+	}
+	if f != float64(int(f)) {
+		return 0, fmt.Errorf("%v: parameter %v must be an integer", step.Operation, name)
+	}
+	return int(f), nil
+}
+
+// Text returns the parameter as text.
+func (step Step) Text(name string) (string, error) {
+	value, ok := step.param(name)
+	if !ok {
+		return "", fmt.Errorf("%v: missing parameter %v", step.Operation, name)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// Points returns the parameter as a list of points of a linear
+// transformation. It accepts "x:y,x:y" strings and lists of {x, y} objects.
+func (step Step) Points(name string) ([]*histogram.Point, error) {
+	value, ok := step.param(name)
+	if !ok {
+		return nil, fmt.Errorf("%v: missing parameter %v", step.Operation, name)
+	}
+	var points []*histogram.Point
+	switch v := value.(type) {
+	case string:
+		for _, pair := range strings.Split(v, ",") {
+			coordinates := strings.Split(strings.TrimSpace(pair), ":")
+			if len(coordinates) != 2 {
+				return nil, fmt.Errorf("%v: points must be written as x:y,x:y", step.Operation)
+			}
+			x, errX := strconv.Atoi(coordinates[0])
+			y, errY := strconv.Atoi(coordinates[1])
+			if errX != nil || errY != nil {
+				return nil, fmt.Errorf("%v: points must be written as x:y,x:y", step.Operation)
+			}
+			points = append(points, &histogram.Point{X: x, Y: y})
+		}
+	case []interface{}:
+		for _, element := range v {
+			object, ok := element.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%v: every point must have x and y", step.Operation)
+			}
+			point := Step{Operation: step.Operation, Params: lowerKeys(object)}
+			x, errX := point.Int("x")
+			y, errY := point.Int("y")
+			if errX != nil || errY != nil {
+				return nil, fmt.Errorf("%v: every point must have integer x and y", step.Operation)
+			}
+			points = append(points, &histogram.Point{X: x, Y: y})
+		}
+	default:
+		return nil, fmt.Errorf("%v: parameter %v must be a list of points", step.Operation, name)
+	}
+	return points, nil
+}
+
+// Luminance returns the luminance parameter, the weights of the grey levels.
+func (step Step) Luminance() (processing.Luminance, error) {
+	text, err := step.Text("luminance")
+	if err != nil {
+		return processing.Luminance{}, err
+	}
+	l, err := processing.ParseLuminance(text)
+	if err != nil {
+		return processing.Luminance{}, fmt.Errorf("%v: %w", step.Operation, err)
+	}
+	return l, nil
+}
+
+func lowerKeys(object map[string]interface{}) map[string]interface{} {
+	lowered := make(map[string]interface{}, len(object))
+	for key, value := range object {
+		lowered[strings.ToLower(key)] = value
+	}
+	return lowered
+}
@@ -0,0 +1,55 @@
+package pipeline
+
+// The suffixes of the operations, what each one adds to the name of the
+// image. OurImage names its tabs with them too, so Pipeline.Name gives the
+// name the UI would show.
+const (
+	AdaptiveThresholdSuffix     = "Adaptive-Threshold"
+	BilateralSuffix             = "Bilateral"
+	BlackHatSuffix              = "Black-Hat"
+	BoxBlurSuffix               = "Box-Blur"
+	BrightnessContrastSuffix    = "B/C"
+	BrightnessContrastRGBSuffix = "B/C RGB"
+	CannySuffix                 = "Canny"
+	ChangeMapSuffix             = "Change Map"
+	ChannelSuffix               = "Channel"
+	CLAHESuffix                 = "CLAHE"
+	CloseSuffix                 = "Closing"
+	ConvolveSuffix              = "Convolution"
+	CropSuffix                  = ROISuffix
+	DepthSuffix                 = "Depth"
+	DifferenceSuffix            = "Image Difference"
+	DilateSuffix                = "Dilation"
+	EmbossSuffix                = "Emboss"
+	EqualizeSuffix              = "Ecualization"
+	ErodeSuffix                 = "Erosion"
+	GammaSuffix                 = "Gamma"
+	GaussianBlurSuffix          = "Gaussian-Blur"
+	GradientSuffix              = "Gradient"
+	HitOrMissSuffix             = "Hit-or-Miss"
+	HMirrorSuffix               = "Horizontal-Mirror"
+	LaplacianSuffix             = "Laplacian"
+	LinearSuffix                = "LinearTrans"
+	MedianSuffix                = "Median"
+	MergeSuffix                 = "Merged"
+	MonochromeSuffix            = "Monochrome"
+	MorphGradientSuffix         = "Morph-Gradient"
+	MultiOtsuSuffix             = "Multi-Otsu"
+	NegativeSuffix              = "Negative"
+	NLMeansSuffix               = "NL-Means"
+	OpenSuffix                  = "Opening"
+	RescaleSuffix               = "Rescaling"
+	ROISuffix                   = "ROI"
+	RotateSuffix                = "Rotate"
+	RotateLeftSuffix            = "Rotate-Left"
+	RotatePrintSuffix           = "Rotate and print"
+	RotateRightSuffix           = "Rotate-Right"
+	SharpenSuffix               = "Sharpen"
+	SkeletonSuffix              = "Skeleton"
+	SpecifySuffix               = "Histogram Igualated"
+	SpecifyDistributionSuffix   = "Specified"
+	ThresholdSuffix             = "Threshold"
+	TopHatSuffix                = "Top-Hat"
+	TransposeSuffix             = "Transpose"
+	VMirrorSuffix               = "Vertical-Mirror"
+)
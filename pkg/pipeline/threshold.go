@@ -0,0 +1,108 @@
+package pipeline
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/vision-go/vision-go/pkg/processing"
+	"github.com/vision-go/vision-go/pkg/threshold"
+)
+
+func init() {
+	register(&Operation{Name: "threshold", Suffix: ThresholdSuffix, Usage: "binarize, white over the threshold",
+		Params: []Param{
+			{Name: "method", Default: "otsu", Usage: "how the threshold is chosen: " + strings.Join(threshold.Methods(), ", ")},
+			{Name: "value", Default: "128", Usage: "threshold of the manual method [0, 255]"},
+			luminanceParam,
+		},
+		build: buildThreshold,
+	})
+	register(&Operation{Name: "multi-otsu", Suffix: MultiOtsuSuffix, Usage: "split the grey levels in classes with the otsu thresholds",
+		Params: []Param{{Name: "classes", Default: "3", Usage: "number of classes [2, 5]"}, luminanceParam},
+		build: func(step Step) (Func, error) {
+			classes, err := step.Int("classes")
+			if err != nil {
+				return nil, err
+			}
+			if classes < 2 || classes > 5 {
+				return nil, fmt.Errorf("%v: the number of classes must be from 2 to 5", step.Operation)
+			}
+			l, err := step.Luminance()
+			if err != nil {
+				return nil, err
+			}
+			return func(img image.Image) (image.Image, error) {
+				thresholds, err := threshold.MultiOtsu(processing.NewHistograms(img, l).Histogram, classes)
+				if err != nil {
+					return nil, err
+				}
+				return threshold.Levels(img, thresholds, l), nil
+			}, nil
+		},
+	})
+	register(&Operation{Name: "adaptive-threshold", Suffix: AdaptiveThresholdSuffix, Usage: "binarize against the average of the neighbourhood",
+		Params: []Param{
+			{Name: "local", Default: "mean", Usage: "local average: " + strings.Join(threshold.Locals(), ", ")},
+			{Name: "size", Default: "15", Usage: "odd side of the window [3, 255]"},
+			{Name: "c", Default: "5", Usage: "subtracted from the average [-255, 255]"},
+			luminanceParam,
+		},
+		build: buildAdaptiveThreshold,
+	})
+}
+
+func buildThreshold(step Step) (Func, error) {
+	name, err := step.Text("method")
+	if err != nil {
+		return nil, err
+	}
+	method, err := threshold.ParseMethod(name)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", step.Operation, err)
+	}
+	value, err := step.Int("value")
+	if err != nil {
+		return nil, err
+	}
+	if value < 0 || value > 255 {
+		return nil, fmt.Errorf("%v: the value must be in the range [0, 255]", step.Operation)
+	}
+	l, err := step.Luminance()
+	if err != nil {
+		return nil, err
+	}
+	return func(img image.Image) (image.Image, error) {
+		t := threshold.Compute(method, processing.NewHistograms(img, l).Histogram, value)
+		return threshold.Binarize(img, t, l), nil
+	}, nil
+}
+
+func buildAdaptiveThreshold(step Step) (Func, error) {
+	name, err := step.Text("local")
+	if err != nil {
+		return nil, err
+	}
+	local, err := threshold.ParseLocal(name)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", step.Operation, err)
+	}
+	size, err := step.Int("size")
+	if err != nil {
+		return nil, err
+	}
+	c, err := step.Float("c")
+	if err != nil {
+		return nil, err
+	}
+	if err := threshold.ValidateAdaptive(size, c); err != nil {
+		return nil, fmt.Errorf("%v: %w", step.Operation, err)
+	}
+	l, err := step.Luminance()
+	if err != nil {
+		return nil, err
+	}
+	return func(img image.Image) (image.Image, error) {
+		return threshold.Adaptive(img, local, size, c, l)
+	}, nil
+}
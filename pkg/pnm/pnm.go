@@ -0,0 +1,375 @@
+// Package pnm reads and writes the netpbm images: PBM bitmaps, PGM grey maps
+// and PPM pixel maps, both plain (ASCII) and raw (binary), with 8 or 16 bits
+// per sample.
+package pnm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strconv"
+)
+
+var errFormat = errors.New("pnm: not a netpbm image")
+
+// MaxSize is the most bytes the samples of a decoded image may take. The
+// header alone decides the size of the image, so a few bytes could otherwise
+// ask for more memory than there is.
+const MaxSize = 1 << 30
+
+func init() {
+	for _, magic := range []string{"P1", "P2", "P3", "P4", "P5", "P6"} {
+		image.RegisterFormat("pnm", magic, Decode, DecodeConfig)
+	}
+}
+
+// header is the start of every netpbm file: the magic number, the size and,
+// but for the bitmaps, the largest value of a sample.
+type header struct {
+	kind          byte // '1' to '6', after the P
+	width, height int
+	maxValue      int
+}
+
+func (h header) plain() bool {
+	return h.kind <= '3'
+}
+
+// channels returns how many samples a pixel has, 1 for bitmaps and grey
+// maps and 3 for pixel maps.
+func (h header) channels() int {
+	if h.kind == '3' || h.kind == '6' {
+		return 3
+	}
+	return 1
+}
+
+func (h header) bitmap() bool {
+	return h.kind == '1' || h.kind == '4'
+}
+
+// reader reads the whitespace separated tokens of the header and of the
+// plain formats, skipping the comments.
+type reader struct {
+	*bufio.Reader
+}
+
+func (r reader) token() (string, error) {
+	var token []byte
+	for {
+		c, err := r.ReadByte()
+		if err == io.EOF && len(token) != 0 {
+			return string(token), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case c == '#':
+			if _, err := r.ReadString('\n'); err != nil && err != io.EOF {
+				return "", err
+			}
+			if len(token) != 0 {
+				return string(token), nil
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f':
+			if len(token) != 0 {
+				return string(token), nil
+			}
+		default:
+			token = append(token, c)
+		}
+	}
+}
+
+func (r reader) number() (int, error) {
+	token, err := r.token()
+	if err != nil {
+		return 0, fmt.Errorf("pnm: %w", err)
+	}
+	value, err := strconv.Atoi(token)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("pnm: %q is not a valid number", token)
+	}
+	return value, nil
+}
+
+// bit reads a pixel of a plain bitmap, whose digits don't need to be
+// separated.
+func (r reader) bit() (int, error) {
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return 0, fmt.Errorf("pnm: %w", err)
+		}
+		switch c {
+		case '0', '1':
+			return int(c - '0'), nil
+		case '#':
+			if _, err := r.ReadString('\n'); err != nil {
+				return 0, fmt.Errorf("pnm: %w", err)
+			}
+		case ' ', '\t', '\n', '\r', '\v', '\f':
+		default:
+			return 0, fmt.Errorf("pnm: %q is not a valid bit", c)
+		}
+	}
+}
+
+func readHeader(r reader) (h header, err error) {
+	magic := make([]byte, 2)
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return h, err
+	}
+	if magic[0] != 'P' || magic[1] < '1' || magic[1] > '6' {
+		return h, errFormat
+	}
+	h.kind = magic[1]
+	if h.width, err = r.number(); err != nil {
+		return h, err
+	}
+	if h.height, err = r.number(); err != nil {
+		return h, err
+	}
+	h.maxValue = 1
+	if !h.bitmap() {
+		if h.maxValue, err = r.number(); err != nil {
+			return h, err
+		}
+		if h.maxValue < 1 || h.maxValue > 0xffff {
+			return h, fmt.Errorf("pnm: the largest value must be in [1, 65535]")
+		}
+	}
+	if h.width < 1 || h.height < 1 {
+		return h, fmt.Errorf("pnm: the image is empty")
+	}
+	// Divided instead of multiplied so that the product can't overflow
+	if h.width > MaxSize/h.height/h.bytesPerPixel() {
+		return h, fmt.Errorf("pnm: %vx%v pixels are too many to decode", h.width, h.height)
+	}
+	return h, nil
+}
+
+// bytesPerPixel returns the bytes a pixel takes decoded: grey maps and
+// bitmaps are decoded as grey images and pixel maps as RGBA ones.
+func (h header) bytesPerPixel() int {
+	size := 1
+	if h.channels() == 3 {
+		size = 4
+	}
+	if h.maxValue > 0xff {
+		size *= 2
+	}
+	return size
+}
+
+// DecodeConfig returns the colour model and the size of a netpbm image
+// without decoding its pixels.
+func DecodeConfig(r io.Reader) (image.Config, error) {
+	h, err := readHeader(reader{bufio.NewReader(r)})
+	if err != nil {
+		return image.Config{}, err
+	}
+	return image.Config{ColorModel: h.model(), Width: h.width, Height: h.height}, nil
+}
+
+func (h header) model() color.Model {
+	switch {
+	case h.channels() == 3 && h.maxValue > 0xff:
+		return color.RGBA64Model
+	case h.channels() == 3:
+		return color.RGBAModel
+	case h.maxValue > 0xff:
+		return color.Gray16Model
+	}
+	return color.GrayModel
+}
+
+// Decode reads a netpbm image: an image.Gray for bitmaps and grey maps, an
+// image.RGBA for pixel maps, or their 16-bit versions when the largest value
+// needs more than a byte. Samples are scaled to the full range of the image.
+func Decode(r io.Reader) (image.Image, error) {
+	br := reader{bufio.NewReader(r)}
+	h, err := readHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	if h.bitmap() {
+		return decodeBitmap(br, h)
+	}
+	rect := image.Rect(0, 0, h.width, h.height)
+	sample := h.sampleReader(br)
+	scale := func(value int) uint32 { return uint32(value) * 0xffff / uint32(h.maxValue) }
+	// The samples are stored in the Pix of the images, big endian as in the
+	// file when they take two bytes
+	var pix []uint8
+	var stride int
+	var img image.Image
+	switch {
+	case h.channels() == 1 && h.maxValue > 0xff:
+		gray := image.NewGray16(rect)
+		img, pix, stride = gray, gray.Pix, gray.Stride
+	case h.channels() == 1:
+		gray := image.NewGray(rect)
+		img, pix, stride = gray, gray.Pix, gray.Stride
+	case h.maxValue > 0xff:
+		rgba := image.NewRGBA64(rect)
+		img, pix, stride = rgba, rgba.Pix, rgba.Stride
+	default:
+		rgba := image.NewRGBA(rect)
+		img, pix, stride = rgba, rgba.Pix, rgba.Stride
+	}
+	for y := 0; y < h.height; y++ {
+		row := pix[y*stride : y*stride+h.width*h.bytesPerPixel()]
+		for i := 0; i < len(row); {
+			// Pixel maps get an opaque alpha after their three samples
+			for c := 0; c < h.channels(); c++ {
+				value, err := sample()
+				if err != nil {
+					return nil, err
+				}
+				if h.maxValue > 0xff {
+					scaled := scale(value)
+					row[i], row[i+1] = uint8(scaled>>8), uint8(scaled)
+					i += 2
+				} else {
+					row[i] = uint8(scale(value) >> 8)
+					i++
+				}
+			}
+			if h.channels() == 3 {
+				for end := i + h.bytesPerPixel()/4; i < end; i++ {
+					row[i] = 0xff
+				}
+			}
+		}
+	}
+	return img, nil
+}
+
+// sampleReader returns the function reading the next sample of the grey and
+// pixel maps, one or two big endian bytes in the raw formats.
+func (h header) sampleReader(r reader) func() (int, error) {
+	if h.plain() {
+		return func() (int, error) {
+			value, err := r.number()
+			if err == nil && value > h.maxValue {
+				return 0, fmt.Errorf("pnm: the sample %v is over the largest value %v", value, h.maxValue)
+			}
+			return value, err
+		}
+	}
+	size := 1
+	if h.maxValue > 0xff {
+		size = 2
+	}
+	buffer := make([]byte, size)
+	return func() (int, error) {
+		if _, err := io.ReadFull(r, buffer); err != nil {
+			return 0, fmt.Errorf("pnm: %w", err)
+		}
+		value := int(buffer[0])
+		if size == 2 {
+			value = value<<8 | int(buffer[1])
+		}
+		if value > h.maxValue {
+			return 0, fmt.Errorf("pnm: the sample %v is over the largest value %v", value, h.maxValue)
+		}
+		return value, nil
+	}
+}
+
+// decodeBitmap reads a PBM, whose 1 bits are black.
+func decodeBitmap(r reader, h header) (image.Image, error) {
+	img := image.NewGray(image.Rect(0, 0, h.width, h.height))
+	row := make([]byte, (h.width+7)/8)
+	for y := 0; y < h.height; y++ {
+		if !h.plain() {
+			if _, err := io.ReadFull(r, row); err != nil {
+				return nil, fmt.Errorf("pnm: %w", err)
+			}
+		}
+		for x := 0; x < h.width; x++ {
+			var bit int
+			if h.plain() {
+				var err error
+				if bit, err = r.bit(); err != nil {
+					return nil, err
+				}
+			} else {
+				bit = int(row[x/8]>>(7-x%8)) & 1
+			}
+			if bit == 0 {
+				img.Pix[y*img.Stride+x] = 0xff
+			}
+		}
+	}
+	return img, nil
+}
+
+// Options are the encoding parameters. Plain writes the samples as ASCII
+// numbers instead of bytes.
+type Options struct {
+	Plain bool
+}
+
+// Encode writes img as a grey map if it is an image.Gray or an image.Gray16
+// and as a pixel map otherwise, with 16 bits per sample for the 16-bit
+// images. The alpha channel is dropped.
+func Encode(w io.Writer, img image.Image, o *Options) error {
+	h := header{kind: '6', width: img.Bounds().Dx(), height: img.Bounds().Dy(), maxValue: 0xff}
+	switch img.(type) {
+	case *image.Gray:
+		h.kind = '5'
+	case *image.Gray16:
+		h.kind, h.maxValue = '5', 0xffff
+	case *image.RGBA64, *image.NRGBA64:
+		h.maxValue = 0xffff
+	}
+	if o != nil && o.Plain {
+		h.kind -= 3
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "P%c\n%v %v\n%v\n", h.kind, h.width, h.height, h.maxValue)
+	b := img.Bounds()
+	written := 0
+	write := func(value uint32) {
+		if h.maxValue == 0xff {
+			value >>= 8
+		}
+		if !h.plain() {
+			if h.maxValue > 0xff {
+				bw.WriteByte(byte(value >> 8))
+			}
+			bw.WriteByte(byte(value))
+			return
+		}
+		separator := byte(' ')
+		if written++; written%16 == 0 { // Lines must not be longer than 70 characters
+			separator = '\n'
+		}
+		bw.WriteString(strconv.Itoa(int(value)))
+		bw.WriteByte(separator)
+	}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := img.At(x, y)
+			if h.channels() == 1 {
+				write(uint32(color.Gray16Model.Convert(c).(color.Gray16).Y))
+				continue
+			}
+			// Not premultiplied, so that the colour survives without alpha
+			nrgba := color.NRGBA64Model.Convert(c).(color.NRGBA64)
+			write(uint32(nrgba.R))
+			write(uint32(nrgba.G))
+			write(uint32(nrgba.B))
+		}
+	}
+	if h.plain() {
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
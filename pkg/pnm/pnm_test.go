@@ -0,0 +1,124 @@
+package pnm
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func testImages() map[string]image.Image {
+	rect := image.Rect(0, 0, 3, 2)
+	gray, gray16 := image.NewGray(rect), image.NewGray16(rect)
+	rgba, rgba64 := image.NewRGBA(rect), image.NewRGBA64(rect)
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			v := uint8(40*x + 100*y)
+			gray.SetGray(x, y, color.Gray{Y: v})
+			gray16.SetGray16(x, y, color.Gray16{Y: uint16(v)<<8 | 0x5a})
+			rgba.SetRGBA(x, y, color.RGBA{R: v, G: 255 - v, B: v / 2, A: 255})
+			rgba64.SetRGBA64(x, y, color.RGBA64{R: uint16(v) << 8, G: 0x1234, B: uint16(x*y) + 7, A: 0xffff})
+		}
+	}
+	return map[string]image.Image{"gray": gray, "gray16": gray16, "rgba": rgba, "rgba64": rgba64}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		image string
+		plain bool
+		magic string
+	}{
+		{"gray", false, "P5"},
+		{"gray", true, "P2"},
+		{"gray16", false, "P5"},
+		{"gray16", true, "P2"},
+		{"rgba", false, "P6"},
+		{"rgba", true, "P3"},
+		{"rgba64", false, "P6"},
+		{"rgba64", true, "P3"},
+	}
+	images := testImages()
+	for _, test := range tests {
+		img := images[test.image]
+		var buffer bytes.Buffer
+		if err := Encode(&buffer, img, &Options{Plain: test.plain}); err != nil {
+			t.Fatalf("%v plain=%v: %v", test.image, test.plain, err)
+		}
+		if magic := buffer.String()[:2]; magic != test.magic {
+			t.Errorf("%v plain=%v: written as %v, want %v", test.image, test.plain, magic, test.magic)
+		}
+		decoded, format, err := image.Decode(&buffer)
+		if err != nil {
+			t.Fatalf("%v plain=%v: %v", test.image, test.plain, err)
+		}
+		if format != "pnm" {
+			t.Errorf("%v plain=%v: format %v, want pnm", test.image, test.plain, format)
+		}
+		if decoded.ColorModel() != img.ColorModel() {
+			t.Errorf("%v plain=%v: decoded as %T", test.image, test.plain, decoded)
+		}
+		for y := 0; y < 2; y++ {
+			for x := 0; x < 3; x++ {
+				if got, want := color.RGBA64Model.Convert(decoded.At(x, y)), color.RGBA64Model.Convert(img.At(x, y)); got != want {
+					t.Errorf("%v plain=%v: pixel %v,%v is %v, want %v", test.image, test.plain, x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want [][]uint8 // Grey levels, or the red of pixel maps
+	}{
+		{"plain bitmap", "P1\n3 2\n010\n1 0 1\n", [][]uint8{{255, 0, 255}, {0, 255, 0}}},
+		{"raw bitmap", "P4\n3 2\n\x40\xa0", [][]uint8{{255, 0, 255}, {0, 255, 0}}},
+		{"comments", "P2\n# a comment\n3 2 # another\n15\n0 5 15\n1 2 3\n", [][]uint8{{0, 85, 255}, {17, 34, 51}}},
+		{"plain pixel map", "P3 2 1 255 255 0 0 0 0 255\n", [][]uint8{{255, 0}}},
+		{"16-bit", "P5 2 1 65535 \xff\xff\x80\x00", [][]uint8{{255, 128}}},
+	}
+	for _, test := range tests {
+		img, err := Decode(strings.NewReader(test.data))
+		if err != nil {
+			t.Errorf("%v: %v", test.name, err)
+			continue
+		}
+		for y, row := range test.want {
+			for x, want := range row {
+				r, _, _, _ := img.At(x, y).RGBA()
+				if got := uint8(r >> 8); got != want {
+					t.Errorf("%v: pixel %v,%v is %v, want %v", test.name, x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		inHeader bool // DecodeConfig fails too
+	}{
+		{"not netpbm", "P7\n1 1\n255\n", true},
+		{"too large", "P5\n200000 200000\n255\n", true},
+		{"overflowing size", "P6 4000000000 4000000000 255\n", true},
+		{"empty", "P5 0 3 255\n", true},
+		{"largest value", "P2 1 1 70000 1\n", true},
+		{"sample over largest", "P2 1 1 15 16\n", false},
+		{"short", "P5 2 2 255 \x01\x02", false},
+		{"bad bit", "P1 2 1 0 2\n", false},
+	}
+	for _, test := range tests {
+		if _, err := Decode(strings.NewReader(test.data)); err == nil {
+			t.Errorf("%v: no error", test.name)
+		}
+		if _, err := DecodeConfig(strings.NewReader(test.data)); test.inHeader && err == nil {
+			t.Errorf("%v: no error from DecodeConfig", test.name)
+		}
+	}
+}
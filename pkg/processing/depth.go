@@ -0,0 +1,154 @@
+package processing
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+)
+
+// Depth is the precision of the samples of an image.
+type Depth int
+
+const (
+	Depth8     Depth = iota // image.RGBA and the rest of 8-bit images
+	Depth16                 // image.RGBA64, image.NRGBA64 and image.Gray16
+	DepthFloat              // Float
+)
+
+var depthNames = []string{"8", "16", "float"}
+
+// Depths returns the names of the depths.
+func Depths() []string {
+	return append([]string(nil), depthNames...)
+}
+
+func ParseDepth(name string) (Depth, error) {
+	for i, depthName := range depthNames {
+		if strings.EqualFold(name, depthName) {
+			return Depth(i), nil
+		}
+	}
+	return 0, fmt.Errorf("the depth must be one of %v", strings.Join(depthNames, ", "))
+}
+
+func (d Depth) String() string {
+	return depthNames[d]
+}
+
+// Description returns the depth as shown to the user, as "16-bit".
+func (d Depth) Description() string {
+	if d == DepthFloat {
+		return "32-bit float"
+	}
+	return d.String() + "-bit"
+}
+
+// DepthOf tells the precision of the samples of img.
+func DepthOf(img image.Image) Depth {
+	switch img.(type) {
+	case *Float:
+		return DepthFloat
+	case *image.RGBA64, *image.NRGBA64, *image.Gray16:
+		return Depth16
+	}
+	return Depth8
+}
+
+// deeper returns the most precise of two depths.
+func deeper(a, b Depth) Depth {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// NewDepthImage returns an empty image with the depth d: image.RGBA,
+// image.RGBA64 or Float.
+func NewDepthImage(d Depth, rect image.Rectangle) draw.Image {
+	switch d {
+	case Depth16:
+		return image.NewRGBA64(rect)
+	case DepthFloat:
+		return NewFloat(rect)
+	}
+	return image.NewRGBA(rect)
+}
+
+// ConvertDepth copies img with the depth d, grey and not premultiplied
+// images keeping their colour model. Going down to 8 bits rounds the samples
+// and any float sample out of 0-255 is clamped.
+func ConvertDepth(img image.Image, d Depth) image.Image {
+	return fromPlanesModel(Planes(img), img, d)
+}
+
+// Float is an image of float32 samples, red, green, blue and alpha
+// interleaved and premultiplied like image.RGBA, in the same 0-255 scale as
+// Plane. Samples out of that range are kept until the image is converted or
+// saved, so chained operations don't lose precision.
+type Float struct {
+	Pix    []float32
+	Stride int
+	Rect   image.Rectangle
+}
+
+func NewFloat(rect image.Rectangle) *Float {
+	return &Float{Pix: make([]float32, 4*rect.Dx()*rect.Dy()), Stride: 4 * rect.Dx(), Rect: rect}
+}
+
+// ColorModel is the one of 16-bit images, the most precise of the standard
+// library, which the encoders use.
+func (img *Float) ColorModel() color.Model {
+	return color.RGBA64Model
+}
+
+func (img *Float) Bounds() image.Rectangle {
+	return img.Rect
+}
+
+func (img *Float) PixOffset(x, y int) int {
+	return (y-img.Rect.Min.Y)*img.Stride + (x-img.Rect.Min.X)*4
+}
+
+func (img *Float) At(x, y int) color.Color {
+	if !(image.Point{x, y}.In(img.Rect)) {
+		return FloatColor{}
+	}
+	i := img.PixOffset(x, y)
+	return FloatColor{img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3]}
+}
+
+func (img *Float) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(img.Rect)) {
+		return
+	}
+	i := img.PixOffset(x, y)
+	if c, ok := c.(FloatColor); ok { // Copied from another Float
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+		return
+	}
+	r, g, b, a := c.RGBA()
+	img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = float32(r)/257, float32(g)/257, float32(b)/257, float32(a)/257
+}
+
+// FloatColor is a pixel of Float, so that moving pixels between Float images
+// keeps the samples out of 0-255.
+type FloatColor struct {
+	R, G, B, A float32
+}
+
+func (c FloatColor) RGBA() (r, g, b, a uint32) {
+	return uint32(clamp16(c.R)), uint32(clamp16(c.G)), uint32(clamp16(c.B)), uint32(clamp16(c.A))
+}
+
+// clamp16 takes a sample of the 0-255 scale to the nearest 16-bit value.
+func clamp16(value float32) uint16 {
+	switch {
+	case value <= 0 || value != value: // NaN
+		return 0
+	case value >= 255:
+		return 0xffff
+	}
+	return uint16(value*257 + 0.5)
+}
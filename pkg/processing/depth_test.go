@@ -0,0 +1,112 @@
+package processing
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// ramp16 is a 3x2 16-bit grey image with levels closer than an 8-bit step.
+func ramp16() *image.Gray16 {
+	img := image.NewGray16(image.Rect(0, 0, 3, 2))
+	for i := 0; i < 6; i++ {
+		img.SetGray16(i%3, i/3, color.Gray16{Y: uint16(1012 + i)})
+	}
+	return img
+}
+
+// levels16 returns the 16-bit grey levels of img row by row.
+func levels16(img image.Image) [][]uint16 {
+	b := img.Bounds()
+	rows := make([][]uint16, b.Dy())
+	for y := range rows {
+		rows[y] = make([]uint16, b.Dx())
+		for x := range rows[y] {
+			rows[y][x] = color.Gray16Model.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.Gray16).Y
+		}
+	}
+	return rows
+}
+
+func TestDepthOperations(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func(image.Image) image.Image
+		want [][]uint16
+	}{
+		{"negative", Negative, [][]uint16{{64523, 64522, 64521}, {64520, 64519, 64518}}},
+		{"identity", func(img image.Image) image.Image { return LinearTransformation(img, points(0, 0, 255, 255)) },
+			[][]uint16{{1012, 1013, 1014}, {1015, 1016, 1017}}},
+		{"gamma 1", func(img image.Image) image.Image { return GammaCorrection(img, 1) },
+			[][]uint16{{1012, 1013, 1014}, {1015, 1016, 1017}}},
+		{"horizontal mirror", HorizontalMirror, [][]uint16{{1014, 1013, 1012}, {1017, 1016, 1015}}},
+		{"rotate right", RotateRight, [][]uint16{{1015, 1012}, {1016, 1013}, {1017, 1014}}},
+		{"monochrome", func(img image.Image) image.Image { return Monochrome(img, PAL) },
+			[][]uint16{{1012, 1013, 1014}, {1015, 1016, 1017}}},
+	}
+	for _, d := range []Depth{Depth16, DepthFloat} {
+		for _, test := range tests {
+			result := test.op(ConvertDepth(ramp16(), d))
+			if got := DepthOf(result); got != d {
+				t.Errorf("%v %v: the result is %v", d, test.name, got.Description())
+			}
+			got := levels16(result)
+			for y := range test.want {
+				for x := range test.want[y] {
+					if got[y][x] != test.want[y][x] {
+						t.Errorf("%v %v: %v, want %v", d, test.name, got, test.want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestEncode16(t *testing.T) {
+	colour := image.NewNRGBA64(image.Rect(0, 0, 3, 1))
+	for x := 0; x < 3; x++ {
+		colour.SetNRGBA64(x, 0, color.NRGBA64{uint16(1012 + x), 30000, uint16(65000 - x), 0xffff})
+	}
+	for _, format := range []string{"png", "tif"} {
+		for _, img := range []image.Image{ramp16(), colour, ConvertDepth(ramp16(), DepthFloat)} {
+			var buf bytes.Buffer
+			if err := Encode(&buf, img, format); err != nil {
+				t.Fatal(err)
+			}
+			decoded, _, err := Decode(bytes.NewReader(buf.Bytes()), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if DepthOf(decoded) != Depth16 {
+				t.Errorf("%v of a %T: read as %T", format, img, decoded)
+			}
+			for i := 0; i < 3; i++ {
+				want := color.NRGBA64Model.Convert(img.At(i%3, i/3))
+				if got := color.NRGBA64Model.Convert(decoded.At(i%3, i/3)); got != want {
+					t.Errorf("%v of a %T: %v becomes %v", format, img, want, got)
+				}
+			}
+		}
+	}
+}
+
+func TestBinnedHistograms(t *testing.T) {
+	hist, err := NewBinnedHistograms(ramp16(), MaxBins, PAL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for level := 1012; level < 1018; level++ {
+		if hist.Histogram.At(level) != 1 {
+			t.Errorf("%v samples at level %v, want 1", hist.Histogram.At(level), level)
+		}
+	}
+	if hist, _ := NewBinnedHistograms(ramp16(), 256, PAL); hist.Histogram.At(4) != 6 {
+		t.Errorf("256 bins: %v samples at level 4, want all of them", hist.Histogram.At(4))
+	}
+	for _, bins := range []int{1, MaxBins + 1} {
+		if _, err := NewBinnedHistograms(ramp16(), bins, PAL); err == nil {
+			t.Errorf("%v bins: no error", bins)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package processing
+
+import (
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"strings"
+
+	"golang.org/x/image/tiff"
+)
+
+// PNGCompression is how hard the png encoder compresses.
+type PNGCompression int
+
+const (
+	PNGDefault PNGCompression = iota
+	PNGNone
+	PNGFast
+	PNGBest
+)
+
+var pngCompressionNames = []string{"default", "none", "fast", "best"}
+
+// PNGCompressions returns the names of the png compression levels.
+func PNGCompressions() []string {
+	return append([]string(nil), pngCompressionNames...)
+}
+
+func ParsePNGCompression(name string) (PNGCompression, error) {
+	for i, compressionName := range pngCompressionNames {
+		if strings.EqualFold(name, compressionName) {
+			return PNGCompression(i), nil
+		}
+	}
+	return 0, fmt.Errorf("the png compression must be one of %v", strings.Join(pngCompressionNames, ", "))
+}
+
+func (c PNGCompression) String() string {
+	return pngCompressionNames[c]
+}
+
+func (c PNGCompression) level() png.CompressionLevel {
+	switch c {
+	case PNGNone:
+		return png.NoCompression
+	case PNGFast:
+		return png.BestSpeed
+	case PNGBest:
+		return png.BestCompression
+	}
+	return png.DefaultCompression
+}
+
+// TIFFCompression is the compression of the tif files. The encoder only
+// writes uncompressed and deflate files; LZW, and the predictor the encoder
+// ties to it, are only read.
+type TIFFCompression int
+
+const (
+	TIFFNone TIFFCompression = iota
+	TIFFDeflate
+)
+
+var tiffCompressionNames = []string{"none", "deflate"}
+
+// TIFFCompressions returns the names of the tif compressions.
+func TIFFCompressions() []string {
+	return append([]string(nil), tiffCompressionNames...)
+}
+
+func ParseTIFFCompression(name string) (TIFFCompression, error) {
+	for i, compressionName := range tiffCompressionNames {
+		if strings.EqualFold(name, compressionName) {
+			return TIFFCompression(i), nil
+		}
+	}
+	if strings.EqualFold(name, "lzw") {
+		return 0, fmt.Errorf("lzw tif files can be read but not written, use deflate")
+	}
+	return 0, fmt.Errorf("the tif compression must be one of %v", strings.Join(tiffCompressionNames, ", "))
+}
+
+func (c TIFFCompression) String() string {
+	return tiffCompressionNames[c]
+}
+
+// SaveOptions are the settings of the encoders that have any. The zero value
+// saves as Encode does.
+type SaveOptions struct {
+	JPEGQuality     int // 1 to 100, jpeg.DefaultQuality if 0
+	PNGCompression  PNGCompression
+	TIFFCompression TIFFCompression
+	PNMPlain        bool      // ASCII samples instead of bytes
+	Luminance       Luminance // Of colour images saved as pgm, PAL if zero
+}
+
+func (o SaveOptions) Validate() error {
+	if o.JPEGQuality < 0 || o.JPEGQuality > 100 {
+		return fmt.Errorf("the jpeg quality must be in [1, 100]")
+	}
+	if o.PNGCompression < 0 || int(o.PNGCompression) >= len(pngCompressionNames) {
+		return fmt.Errorf("the png compression must be one of %v", strings.Join(pngCompressionNames, ", "))
+	}
+	if o.TIFFCompression < 0 || int(o.TIFFCompression) >= len(tiffCompressionNames) {
+		return fmt.Errorf("the tif compression must be one of %v", strings.Join(tiffCompressionNames, ", "))
+	}
+	return nil
+}
+
+// SetCompression sets the compressions of the formats that have one named
+// name, so one setting serves whatever format is saved: "best" is for png,
+// "deflate" for tif and "none" for both.
+func (o *SaveOptions) SetCompression(name string) error {
+	pngCompression, pngErr := ParsePNGCompression(name)
+	if pngErr == nil {
+		o.PNGCompression = pngCompression
+	}
+	tiffCompression, tiffErr := ParseTIFFCompression(name)
+	if tiffErr == nil {
+		o.TIFFCompression = tiffCompression
+	}
+	if pngErr != nil && tiffErr != nil {
+		if strings.EqualFold(name, "lzw") {
+			return tiffErr
+		}
+		return fmt.Errorf("the compression must be one of %v for png or %v for tif",
+			strings.Join(pngCompressionNames, ", "), strings.Join(tiffCompressionNames, ", "))
+	}
+	return nil
+}
+
+func (o SaveOptions) luminance() Luminance {
+	if o.Luminance == (Luminance{}) {
+		return PAL
+	}
+	return o.Luminance
+}
+
+func (o SaveOptions) jpeg() *jpeg.Options {
+	if o.JPEGQuality == 0 {
+		return &jpeg.Options{Quality: jpeg.DefaultQuality}
+	}
+	return &jpeg.Options{Quality: o.JPEGQuality}
+}
+
+func (o SaveOptions) png() *png.Encoder {
+	return &png.Encoder{CompressionLevel: o.PNGCompression.level()}
+}
+
+func (o SaveOptions) tiff() *tiff.Options {
+	if o.TIFFCompression == TIFFDeflate {
+		return &tiff.Options{Compression: tiff.Deflate}
+	}
+	return &tiff.Options{Compression: tiff.Uncompressed}
+}
+
+// byteCounter is a writer that only counts what is written to it.
+type byteCounter int64
+
+func (counter *byteCounter) Write(p []byte) (int, error) {
+	*counter += byteCounter(len(p))
+	return len(p), nil
+}
+
+// EstimateSize returns the bytes img takes saved in format with o, encoding
+// it without writing anything.
+func EstimateSize(img image.Image, format string, o SaveOptions) (int64, error) {
+	var counter byteCounter
+	if err := EncodeOptions(&counter, img, format, o); err != nil {
+		return 0, err
+	}
+	return int64(counter), nil
+}
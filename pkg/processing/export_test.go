@@ -0,0 +1,123 @@
+package processing
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// textured returns a colour image of 8x8 blocks with a gradient inside them,
+// which the encoders can compress more or less.
+func textured() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(x / 8 * 32), uint8(y / 8 * 32), uint8(x%8 + y%8), 255})
+		}
+	}
+	return img
+}
+
+func TestSaveOptions(t *testing.T) {
+	tests := []struct {
+		name          string
+		format        string
+		small, larger SaveOptions
+	}{
+		{"jpeg quality", "jpg", SaveOptions{JPEGQuality: 10}, SaveOptions{JPEGQuality: 95}},
+		{"png compression", "png", SaveOptions{PNGCompression: PNGBest}, SaveOptions{PNGCompression: PNGNone}},
+		{"tif deflate", "tif", SaveOptions{TIFFCompression: TIFFDeflate}, SaveOptions{TIFFCompression: TIFFNone}},
+		{"plain pnm", "pnm", SaveOptions{}, SaveOptions{PNMPlain: true}},
+	}
+	img := textured()
+	for _, test := range tests {
+		small, err := EstimateSize(img, test.format, test.small)
+		if err != nil {
+			t.Fatal(err)
+		}
+		larger, err := EstimateSize(img, test.format, test.larger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if small >= larger {
+			t.Errorf("%v: %v bytes, not less than the %v of %+v", test.name, small, larger, test.larger)
+		}
+	}
+}
+
+// TestEstimateSize checks that the estimate is what is written to a file.
+func TestEstimateSize(t *testing.T) {
+	dir := t.TempDir()
+	img := textured()
+	for _, format := range SaveFormats {
+		for _, o := range []SaveOptions{{}, {JPEGQuality: 30, PNGCompression: PNGFast, TIFFCompression: TIFFDeflate, PNMPlain: true}} {
+			size, err := EstimateSize(img, format, o)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(dir, "image."+format)
+			file, err := os.Create(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = EncodeOptions(file, img, format, o)
+			file.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info, err := os.Stat(path); err != nil || info.Size() != size {
+				t.Errorf("%v with %+v: %v bytes estimated, %v written", format, o, size, info.Size())
+			}
+		}
+	}
+}
+
+func TestInvalidSaveOptions(t *testing.T) {
+	for _, o := range []SaveOptions{
+		{JPEGQuality: -1},
+		{JPEGQuality: 101},
+		{PNGCompression: PNGCompression(len(pngCompressionNames))},
+		{TIFFCompression: -1},
+	} {
+		if err := o.Validate(); err == nil {
+			t.Errorf("%+v: no error", o)
+		}
+		if err := EncodeOptions(new(bytes.Buffer), textured(), "png", o); err == nil {
+			t.Errorf("%+v: no error encoding", o)
+		}
+		if _, err := EstimateSize(textured(), "png", o); err == nil {
+			t.Errorf("%+v: no error estimating the size", o)
+		}
+	}
+	if _, err := EstimateSize(textured(), "xyz", SaveOptions{}); err == nil {
+		t.Error("no error estimating the size of an unknown format")
+	}
+}
+
+func TestSetCompression(t *testing.T) {
+	tests := []struct {
+		name string
+		want SaveOptions
+	}{
+		{"best", SaveOptions{PNGCompression: PNGBest}},
+		{"Deflate", SaveOptions{TIFFCompression: TIFFDeflate}},
+		{"none", SaveOptions{PNGCompression: PNGNone, TIFFCompression: TIFFNone}},
+	}
+	for _, test := range tests {
+		var o SaveOptions
+		if err := o.SetCompression(test.name); err != nil {
+			t.Errorf("%v: %v", test.name, err)
+		} else if o != test.want {
+			t.Errorf("%v: %+v, want %+v", test.name, o, test.want)
+		}
+	}
+	for _, name := range []string{"lzw", "xyz"} {
+		var o SaveOptions
+		if err := o.SetCompression(name); err == nil {
+			t.Errorf("%v: no error", name)
+		}
+	}
+}
@@ -0,0 +1,547 @@
+package processing
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/vision-go/vision-go/pkg/histogram"
+)
+
+// mapLevels applies to the red, green and blue samples of img the function
+// of their channel, whose levels go from 0 to 255. 8-bit images go through a
+// look-up table and are rounded; deeper ones keep their depth and the
+// precision of the result. The colour model of img is kept, see
+// FromPlanesLike.
+func mapLevels(img image.Image, levels [3]func(float64) float64) image.Image {
+	depth := DepthOf(img)
+	planes := Planes(img)
+	notPremultiplied := isNotPremultiplied(img)
+	if notPremultiplied { // Maps the colour itself, not its product by alpha
+		premultiply(planes, false)
+	}
+	for channel, level := range levels {
+		pix := planes[channel].Pix
+		if depth == Depth8 {
+			var localLookUpTable [256]float32
+			for colour := range localLookUpTable {
+				localLookUpTable[colour] = float32(level(float64(colour)))
+			}
+			for i, value := range pix {
+				pix[i] = localLookUpTable[Clamp(value)]
+			}
+			continue
+		}
+		for i, value := range pix {
+			pix[i] = float32(level(float64(value)))
+		}
+	}
+	if notPremultiplied {
+		premultiply(planes, true)
+	}
+	return FromPlanesLike(planes, img)
+}
+
+// sameLevels uses level for the three channels.
+func sameLevels(level func(float64) float64) [3]func(float64) float64 {
+	return [3]func(float64) float64{level, level, level}
+}
+
+// interpolated turns a look-up table of the 256 levels into a function,
+// linearly interpolating the levels of deeper samples.
+func interpolated(lookUpTableArray [256]int) func(float64) float64 {
+	return func(value float64) float64 {
+		if value <= 0 {
+			return float64(lookUpTableArray[0])
+		}
+		if value >= 255 {
+			return float64(lookUpTableArray[255])
+		}
+		floor := math.Floor(value)
+		low, high := float64(lookUpTableArray[int(floor)]), float64(lookUpTableArray[int(math.Ceil(value))])
+		return low + (high-low)*(value-floor)
+	}
+}
+
+func Negative(img image.Image) image.Image {
+	return mapLevels(img, sameLevels(func(value float64) float64 { return 255 - value }))
+}
+
+// Monochrome returns the grey level of every pixel with the weights l.
+// Opaque images give image.Gray or, for 16 bits, image.Gray16.
+func Monochrome(img image.Image, l Luminance) image.Image {
+	depth := DepthOf(img)
+	planes := Planes(img)
+	grey, alpha := NewPlane(planes[0].Width, planes[0].Height), NewPlane(planes[0].Width, planes[0].Height)
+	opaque := true
+	for i := range grey.Pix {
+		if planes[3].Pix[i] == 0 { // Stays transparent
+			opaque = false
+			continue
+		}
+		value := l.Grey(float64(planes[0].Pix[i]), float64(planes[1].Pix[i]), float64(planes[2].Pix[i]))
+		if depth == Depth8 {
+			value = math.Trunc(value)
+		}
+		grey.Pix[i], alpha.Pix[i] = float32(value), 255
+	}
+	if opaque && depth != DepthFloat {
+		return fromGreyPlane(grey, depth)
+	}
+	return FromPlanesDepth([4]*Plane{grey, grey, grey, alpha}, depth)
+}
+
+func ROI(img image.Image, rect image.Rectangle) image.Image {
+	b := rect.Bounds()
+	NewImage := newLike(img, image.Rect(0, 0, b.Dx(), b.Dy()), false, false)
+	for y := 0; y < rect.Dy(); y++ {
+		for x := 0; x < rect.Dx(); x++ {
+			NewImage.Set(x, y, img.At(x+rect.Min.X, y+rect.Min.Y))
+		}
+	}
+	return NewImage
+}
+
+// BrightnessAndContrast linearly maps every channel so that an image with
+// brightness oldbr and contrast oldctr ends up with newbr and newctr.
+func BrightnessAndContrast(img image.Image, oldbr, oldctr, newbr, newctr float64) image.Image {
+	same := func(value float64) [3]float64 { return [3]float64{value, value, value} }
+	return ChannelsBrightnessAndContrast(img, same(oldbr), same(oldctr), same(newbr), same(newctr))
+}
+
+// ChannelsBrightnessAndContrast maps each of the red, green and blue channels
+// on its own, from its brightness oldbr and contrast oldctr to newbr and
+// newctr, which corrects colour casts.
+func ChannelsBrightnessAndContrast(img image.Image, oldbr, oldctr, newbr, newctr [3]float64) image.Image {
+	var levels [3]func(float64) float64
+	for channel := range levels {
+		A := 1.0 // A flat channel has no contrast to stretch
+		if oldctr[channel] != 0 {
+			A = newctr[channel] / oldctr[channel]
+		}
+		B := newbr[channel] - A*oldbr[channel]
+		levels[channel] = func(colour float64) float64 {
+			return A*colour + B
+		}
+	}
+	return mapLevels(img, levels)
+}
+
+func GammaCorrection(img image.Image, gamma float64) image.Image {
+	return mapLevels(img, sameLevels(func(colour float64) float64 {
+		return math.Pow(colour/255, gamma) * 255
+	}))
+}
+
+func LinearTransformation(img image.Image, points []*histogram.Point) image.Image {
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].X == points[j].X {
+			return points[i].Y > points[j].Y
+		}
+		return points[i].X < points[j].X
+	})
+	if points[0].X != 0 {
+		points = append([]*histogram.Point{{X: 0, Y: 0}}, points...)
+	}
+	if points[len(points)-1].X != 255 {
+		points = append(points, &histogram.Point{X: 255, Y: 255})
+	}
+	return mapLevels(img, sameLevels(func(colour float64) float64 {
+		for i, j := 0, 1; j < len(points); i, j = i+1, j+1 {
+			p1, p2 := *points[i], *points[j]
+			if p2.X == p1.X {
+				p2.X++
+			}
+			if colour < float64(p2.X) || j == len(points)-1 {
+				m := float64(p2.Y-p1.Y) / float64(p2.X-p1.X)
+				n := float64(p1.Y) - m*float64(p1.X)
+				return m*colour + n
+			}
+		}
+		return colour
+	}))
+}
+
+// Equalization equalizes the histogram of every channel of img, taken from
+// the pixels inside mask, every pixel if mask is nil.
+func Equalization(img image.Image, mask *image.Alpha) image.Image {
+	hist, size := maskedHistograms(img, mask, PAL) // Only the channels, the weights don't matter
+	var lookUpTableArrayR [256]int
+	var lookUpTableArrayG [256]int
+	var lookUpTableArrayB [256]int
+
+	for i := 0; i < 256; i++ {
+		lookUpTableArrayR[i] = int(math.Round(math.Max(0, (float64(hist.HistogramAccumulativeR.At(i)*256)/float64(size))-1)))
+		lookUpTableArrayG[i] = int(math.Round(math.Max(0, (float64(hist.HistogramAccumulativeG.At(i)*256)/float64(size))-1)))
+		lookUpTableArrayB[i] = int(math.Round(math.Max(0, (float64(hist.HistogramAccumulativeB.At(i)*256)/float64(size))-1)))
+	}
+	return specification(img, lookUpTableArrayR, lookUpTableArrayG, lookUpTableArrayB)
+}
+
+// HistogramIgualation maps img so that its accumulative histograms, taken
+// from the pixels inside mask, match the ones of reference.
+func HistogramIgualation(img, reference image.Image, mask *image.Alpha) image.Image {
+	original, size := maskedHistograms(img, mask, PAL) // Only the channels
+	wanted := NewHistograms(reference, PAL)
+	sizeF, sizeF2 := float64(size), float64(pixels(reference))
+	return specification(img,
+		lookUpTableOfSpecification(normalize(original.HistogramAccumulativeR, sizeF), normalize(wanted.HistogramAccumulativeR, sizeF2)),
+		lookUpTableOfSpecification(normalize(original.HistogramAccumulativeG, sizeF), normalize(wanted.HistogramAccumulativeG, sizeF2)),
+		lookUpTableOfSpecification(normalize(original.HistogramAccumulativeB, sizeF), normalize(wanted.HistogramAccumulativeB, sizeF2)))
+}
+
+// HistogramSpecification maps every channel of img so that its histogram,
+// taken from the pixels inside mask, follows target, a normalized histogram.
+func HistogramSpecification(img image.Image, target histogram.HistogramNormalized, mask *image.Alpha) image.Image {
+	original, size := maskedHistograms(img, mask, PAL) // Only the channels
+	sizeF := float64(size)
+	wanted := target.Accumulative()
+	return specification(img,
+		lookUpTableOfSpecification(normalize(original.HistogramAccumulativeR, sizeF), wanted),
+		lookUpTableOfSpecification(normalize(original.HistogramAccumulativeG, sizeF), wanted),
+		lookUpTableOfSpecification(normalize(original.HistogramAccumulativeB, sizeF), wanted))
+}
+
+func pixels(img image.Image) int {
+	return img.Bounds().Dx() * img.Bounds().Dy()
+}
+
+func normalize(hist histogram.Histogram, size float64) (normalized histogram.HistogramNormalized) {
+	for i, count := range hist {
+		normalized[i] = float64(count) / size
+	}
+	return normalized
+}
+
+// lookUpTableOfSpecification takes every level a to the highest level of the
+// target whose accumulative frequency Pr doesn't reach the one of a in Po.
+func lookUpTableOfSpecification(Po, Pr histogram.HistogramNormalized) (lookUpTableArray [256]int) {
+	M := 256
+	for a := range lookUpTableArray {
+		for j := M - 1; j >= 0; j-- {
+			lookUpTableArray[a] = j
+			if Po[a] > Pr[j] {
+				break
+			}
+		}
+	}
+	return lookUpTableArray
+}
+
+func specification(img image.Image, lookUpTableArrayR, lookUpTableArrayG, lookUpTableArrayB [256]int) image.Image {
+	return mapLevels(img, [3]func(float64) float64{interpolated(lookUpTableArrayR), interpolated(lookUpTableArrayG), interpolated(lookUpTableArrayB)})
+}
+
+func ImageDiference(img, imageIn image.Image) (image.Image, error) {
+	if img.Bounds() != imageIn.Bounds() {
+		return nil, fmt.Errorf("images must have the same dimensions")
+	}
+	planes, others := Planes(img), Planes(imageIn)
+	for channel := 0; channel < 3; channel++ {
+		for i, value := range planes[channel].Pix {
+			planes[channel].Pix[i] = float32(math.Abs(float64(value - others[channel].Pix[i])))
+		}
+	}
+	if DepthOf(imageIn) > DepthOf(img) {
+		return FromPlanesLike(planes, imageIn), nil
+	}
+	return FromPlanesLike(planes, img), nil
+}
+
+// ChangeMap paints with colour every pixel whose grey level, with the
+// weights l, differs more than T between img and imageIn.
+func ChangeMap(img, imageIn image.Image, colour color.Color, T int, l Luminance) (image.Image, error) {
+	if img.Bounds() != imageIn.Bounds() {
+		return nil, fmt.Errorf("images must have the same dimensions")
+	}
+	b := img.Bounds()
+	NewImage := NewDepthImage(DepthOf(img), image.Rect(0, 0, b.Dx(), b.Dy())) // Colour, even for grey images
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			oldColour := img.At(x, y)
+			r, g, b, _ := oldColour.RGBA()
+			r2, g2, b2, _ := imageIn.At(x, y).RGBA()
+			grey := l.Grey(float64(r), float64(g), float64(b)) / 257 // In 0-255 like T
+			grey2 := l.Grey(float64(r2), float64(g2), float64(b2)) / 257
+			difference := math.Abs(grey2 - grey)
+
+			if difference > float64(T) {
+				oldColour = colour
+			}
+			NewImage.Set(x, y, oldColour)
+		}
+	}
+	return NewImage, nil
+}
+
+func HorizontalMirror(img image.Image) image.Image {
+	b := img.Bounds()
+	NewImage := newLike(img, image.Rect(0, 0, b.Dx(), b.Dy()), false, false)
+
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			oldColour := img.At(b.Dx()-1-x, y)
+			NewImage.Set(x, y, oldColour)
+		}
+	}
+	return NewImage
+}
+
+func VerticalMirror(img image.Image) image.Image {
+	b := img.Bounds()
+	NewImage := newLike(img, image.Rect(0, 0, b.Dx(), b.Dy()), false, false)
+
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			oldColour := img.At(x, b.Dy()-1-y)
+			NewImage.Set(x, y, oldColour)
+		}
+	}
+	return NewImage
+}
+
+func RotateRight(img image.Image) image.Image {
+	b := img.Bounds()
+	NewImage := newLike(img, image.Rect(0, 0, b.Dy(), b.Dx()), false, false)
+
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			oldColour := img.At(x, y)
+			NewImage.Set(b.Dy()-1-y, x, oldColour)
+		}
+	}
+	return NewImage
+}
+
+func RotateLeft(img image.Image) image.Image {
+	b := img.Bounds()
+	NewImage := newLike(img, image.Rect(0, 0, b.Dy(), b.Dx()), false, false)
+
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			oldColour := img.At(x, y)
+			NewImage.Set(y, b.Dx()-1-x, oldColour)
+		}
+	}
+	return NewImage
+}
+
+func Transpose(img image.Image) image.Image {
+	b := img.Bounds()
+	NewImage := newLike(img, image.Rect(0, 0, b.Dy(), b.Dx()), false, false)
+
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			oldColour := img.At(x, y)
+			NewImage.Set(y, x, oldColour)
+		}
+	}
+	return NewImage
+}
+
+// Rescaling resizes img by rescalingFactor (1 keeps the size) using the
+// nearest neighbour (VMP) or the bilinear interpolation.
+func Rescaling(img image.Image, rescalingFactor float64, VMP bool) image.Image {
+	if VMP {
+		return rescalingVMP(img, rescalingFactor)
+	}
+	return rescalingBilineal(img, rescalingFactor)
+}
+
+func rescalingVMP(img image.Image, rescalingFactor float64) image.Image {
+	b := img.Bounds()
+	width := int(math.Round(float64(b.Dx()) * (rescalingFactor)))
+	height := int(math.Round(float64(b.Dy()) * (rescalingFactor)))
+	NewImage := newLike(img, image.Rect(0, 0, width, height), false, false)
+
+	var Colour color.Color
+	for y := 0; y <= height; y++ {
+		for x := 0; x <= width; x++ {
+			cordX := float64(x) / (rescalingFactor)
+			cordY := float64(y) / (rescalingFactor)
+			indexI := int(math.Round(cordX))
+			indexJ := int(math.Round(cordY))
+			Colour = img.At(indexI, indexJ)
+			NewImage.Set(x, y, Colour)
+		}
+	}
+	return NewImage
+}
+
+func rescalingBilineal(img image.Image, rescalingFactor float64) image.Image {
+	b := img.Bounds()
+	width := int(math.Round(float64(b.Dx()) * (rescalingFactor)))
+	height := int(math.Round(float64(b.Dy()) * (rescalingFactor)))
+	NewImage := newLike(img, image.Rect(0, 0, width, height), true, false)
+
+	for y := 0; y <= height; y++ {
+		for x := 0; x <= width; x++ {
+			cordX := float64(x) / (rescalingFactor)
+			cordY := float64(y) / (rescalingFactor)
+			indexICeil := int(math.Ceil(cordX))
+			indexIFloor := int(math.Floor(cordX))
+			indexJCeil := int(math.Ceil(cordY))
+			indexJFloor := int(math.Floor(cordY))
+
+			p := cordX - math.Floor(cordX)
+			q := cordY - math.Floor(cordY)
+			A := img.At(indexIFloor, indexJCeil)
+			D := img.At(indexICeil, indexJCeil)
+			C := img.At(indexIFloor, indexJFloor)
+			B := img.At(indexICeil, indexJFloor)
+
+			NewImage.Set(x, y, bilinear(A, B, C, D, p, q))
+		}
+	}
+	return NewImage
+}
+
+// bilinear interpolates the four neighbours of a point, being C the top
+// left one and p, q the distances to it.
+func bilinear(A, B, C, D color.Color, p, q float64) color.Color {
+	ra, ga, ba, aa := A.RGBA()
+	rb, gb, bb, ab := B.RGBA()
+	rc, gc, bc, ac := C.RGBA()
+	rd, gd, bd, ad := D.RGBA()
+	interpolate := func(a, b, c, d uint32) uint16 {
+		aF, bF, cF, dF := float64(a), float64(b), float64(c), float64(d)
+		return uint16(cF + (dF-cF)*p + (aF-cF)*q + (bF+cF-aF-dF)*p*q)
+	}
+	return color.RGBA64{ // Keeps the precision of 16-bit images
+		R: interpolate(ra, rb, rc, rd),
+		G: interpolate(ga, gb, gc, gd),
+		B: interpolate(ba, bb, bc, bd),
+		A: interpolate(aa, ab, ac, ad),
+	}
+}
+
+type point struct {
+	X, Y float64
+}
+
+func rotateX(x, y int, angleRadian, factor float64) float64 {
+	return float64(x)*math.Cos(angleRadian*factor) - float64(y)*math.Sin(angleRadian*factor)
+}
+
+func rotateY(x, y int, angleRadian, factor float64) float64 {
+	return float64(x)*math.Sin(angleRadian*factor) + float64(y)*math.Cos(angleRadian*factor)
+}
+
+// RotateAndPrint rotates img by angle degrees with a direct mapping, so the
+// result may contain holes.
+func RotateAndPrint(img image.Image, angle float64) image.Image {
+	b := img.Bounds()
+	angleRadian := -angle * math.Pi / 180
+	min, max := getMinMaxPointsForRotation(b, angleRadian)
+
+	newImage := newLike(img, image.Rect(0, 0, int(math.Ceil(math.Abs(max.X-min.X))), int(math.Ceil(math.Abs(max.Y-min.Y)))), false, true)
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			newImage.Set(int(math.Round(rotateX(x, y, angleRadian, 1)+math.Abs(min.X))),
+				int(math.Round(rotateY(x, y, angleRadian, 1)+math.Abs(min.Y))),
+				img.At(x, y))
+		}
+	}
+	return newImage
+}
+
+const (
+	VMP = iota
+	Bilineal
+)
+
+// Rotate rotates img by angle degrees using an inverse mapping and the
+// interpolation given by selection (VMP or Bilineal).
+func Rotate(img image.Image, angle float64, selection int) image.Image {
+	if selection == VMP {
+		return rotateVMP(img, angle)
+	}
+	return rotateBilineal(img, angle)
+}
+
+func getMinMaxPointsForRotation(b image.Rectangle, angleRadian float64) (point, point) {
+	A := point{X: rotateX(0, 0, angleRadian, 1), Y: rotateY(0, 0, angleRadian, 1)}
+	B := point{X: rotateX(b.Dx(), 0, angleRadian, 1), Y: rotateY(b.Dx(), 0, angleRadian, 1)}
+	C := point{X: rotateX(0, b.Dy(), angleRadian, 1), Y: rotateY(0, b.Dy(), angleRadian, 1)}
+	D := point{X: rotateX(b.Dx(), b.Dy(), angleRadian, 1), Y: rotateY(b.Dx(), b.Dy(), angleRadian, 1)}
+	minX := math.Min(math.Min(A.X, B.X), math.Min(C.X, D.X))
+	maxX := math.Max(math.Max(A.X, B.X), math.Max(C.X, D.X))
+	minY := math.Min(math.Min(A.Y, B.Y), math.Min(C.Y, D.Y))
+	maxY := math.Max(math.Max(A.Y, B.Y), math.Max(C.Y, D.Y))
+	return point{minX, minY}, point{maxX, maxY}
+}
+
+func rotateVMP(img image.Image, angle float64) image.Image {
+	b := img.Bounds()
+	angleRadian := -angle * math.Pi / 180
+	min, max := getMinMaxPointsForRotation(b, angleRadian)
+
+	newImage := newLike(img, image.Rect(0, 0, int(math.Ceil(math.Abs(max.X-min.X))), int(math.Ceil(math.Abs(max.Y-min.Y)))), false, true)
+	for y := 0; y < newImage.Bounds().Dy(); y++ {
+		for x := 0; x < newImage.Bounds().Dx(); x++ {
+			rotatedX := int(math.Round(rotateX(x-int(math.Abs(min.X)), y-int(math.Abs(min.Y)), angleRadian, -1)))
+			rotatedY := int(math.Round(rotateY(x-int(math.Abs(min.X)), y-int(math.Abs(min.Y)), angleRadian, -1)))
+			if rotatedX >= 0 && rotatedX < b.Dx() && rotatedY >= 0 && rotatedY < b.Dy() {
+				newImage.Set(x, y, img.At(rotatedX, rotatedY))
+			}
+		}
+	}
+	return newImage
+}
+
+func rotateBilineal(img image.Image, angle float64) image.Image {
+	b := img.Bounds()
+	angleRadian := -angle * math.Pi / 180
+	min, max := getMinMaxPointsForRotation(b, angleRadian)
+	newImage := newLike(img, image.Rect(0, 0, int(math.Ceil(math.Abs(max.X-min.X))), int(math.Ceil(math.Abs(max.Y-min.Y)))), true, true)
+
+	for y := 0; y < newImage.Bounds().Dy(); y++ {
+		for x := 0; x < newImage.Bounds().Dx(); x++ {
+			rotatedX := rotateX(x-int(math.Abs(min.X)), y-int(math.Abs(min.Y)), angleRadian, -1)
+			rotatedY := rotateY(x-int(math.Abs(min.X)), y-int(math.Abs(min.Y)), angleRadian, -1)
+			if rotatedX < 0 || rotatedX >= float64(b.Dx()) || rotatedY < 0 || rotatedY >= float64(b.Dy()) {
+				continue
+			}
+
+			indexICeil := int(math.Ceil(rotatedX))
+			indexIFloor := int(math.Floor(rotatedX))
+			indexJCeil := int(math.Ceil(rotatedY))
+			indexJFloor := int(math.Floor(rotatedY))
+
+			p := rotatedX - float64(indexIFloor)
+			q := rotatedY - float64(indexJFloor)
+			A := img.At(indexIFloor, indexJCeil)
+			B := img.At(indexICeil, indexJCeil)
+			C := img.At(indexIFloor, indexJFloor)
+			D := img.At(indexICeil, indexJFloor)
+
+			newImage.Set(x, y, bilinear(A, B, C, D, p, q))
+		}
+	}
+	return newImage
+}
+
+// Paste returns a copy of img with patch drawn with its top left corner at
+// the point at. Only the pixels inside mask are drawn, all of them if mask is
+// nil.
+func Paste(img, patch image.Image, at image.Point, mask *image.Alpha) image.Image {
+	b := img.Bounds()
+	NewImage := NewDepthImage(deeper(DepthOf(img), DepthOf(patch)), image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			NewImage.Set(x, y, img.At(x, y))
+		}
+	}
+	pb := patch.Bounds()
+	for y := 0; y < pb.Dy(); y++ {
+		for x := 0; x < pb.Dx(); x++ {
+			if mask != nil && mask.AlphaAt(x+at.X, y+at.Y).A == 0 {
+				continue
+			}
+			NewImage.Set(x+at.X, y+at.Y, patch.At(x+pb.Min.X, y+pb.Min.Y))
+		}
+	}
+	return NewImage
+}
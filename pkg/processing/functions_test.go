@@ -0,0 +1,121 @@
+package processing
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/vision-go/vision-go/pkg/histogram"
+)
+
+// ramp is a 3x2 grey image whose row y has the levels 0, 100 and 254 plus y.
+func ramp() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 3, 2))
+	for y := 0; y < 2; y++ {
+		for x, level := range []uint8{0, 100, 254} {
+			img.SetGray(x, y, color.Gray{Y: level + uint8(y)})
+		}
+	}
+	return img
+}
+
+// levels returns the grey levels of img row by row.
+func levels(img image.Image) [][]uint8 {
+	b := img.Bounds()
+	rows := make([][]uint8, b.Dy())
+	for y := range rows {
+		rows[y] = make([]uint8, b.Dx())
+		for x := range rows[y] {
+			rows[y][x] = color.GrayModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.Gray).Y
+		}
+	}
+	return rows
+}
+
+func points(xy ...int) (points []*histogram.Point) {
+	for i := 0; i < len(xy); i += 2 {
+		points = append(points, &histogram.Point{X: xy[i], Y: xy[i+1]})
+	}
+	return points
+}
+
+func TestOperations(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func(image.Image) image.Image
+		want [][]uint8
+	}{
+		{"negative", Negative, [][]uint8{{255, 155, 1}, {254, 154, 0}}},
+		{"identity", func(img image.Image) image.Image { return LinearTransformation(img, points(0, 0, 255, 255)) },
+			[][]uint8{{0, 100, 254}, {1, 101, 255}}},
+		{"255 to 0", func(img image.Image) image.Image { return LinearTransformation(img, points(0, 255, 255, 0)) },
+			[][]uint8{{255, 155, 1}, {254, 154, 0}}},
+		{"only 255 to 0", func(img image.Image) image.Image { return LinearTransformation(img, points(255, 0)) },
+			[][]uint8{{0, 0, 0}, {0, 0, 0}}},
+		{"two sections", func(img image.Image) image.Image { return LinearTransformation(img, points(100, 200)) },
+			[][]uint8{{0, 200, 255}, {2, 200, 255}}},
+		{"gamma 1", func(img image.Image) image.Image { return GammaCorrection(img, 1) }, [][]uint8{{0, 100, 254}, {1, 101, 255}}},
+		{"horizontal mirror", HorizontalMirror, [][]uint8{{254, 100, 0}, {255, 101, 1}}},
+		{"vertical mirror", VerticalMirror, [][]uint8{{1, 101, 255}, {0, 100, 254}}},
+		{"transpose", Transpose, [][]uint8{{0, 1}, {100, 101}, {254, 255}}},
+		{"rotate right", RotateRight, [][]uint8{{1, 0}, {101, 100}, {255, 254}}},
+		{"rotate left", RotateLeft, [][]uint8{{254, 255}, {100, 101}, {0, 1}}},
+		{"roi", func(img image.Image) image.Image { return ROI(img, image.Rect(1, 1, 3, 2)) }, [][]uint8{{101, 255}}},
+	}
+	for _, test := range tests {
+		got := levels(test.op(ramp()))
+		if len(got) != len(test.want) {
+			t.Errorf("%v: %v, want %v", test.name, got, test.want)
+			continue
+		}
+		for y := range got {
+			for x := range got[y] {
+				if got[y][x] != test.want[y][x] {
+					t.Errorf("%v: %v, want %v", test.name, got, test.want)
+				}
+			}
+		}
+	}
+}
+
+func TestBrightnessAndContrast(t *testing.T) {
+	stats := NewStatistics(ramp(), PAL)
+	same := BrightnessAndContrast(ramp(), stats.Brightness, stats.Contrast, stats.Brightness, stats.Contrast)
+	if got, want := levels(same), levels(ramp()); got[0][1] != want[0][1] || got[1][2] != want[1][2] {
+		t.Errorf("keeping the brightness and contrast changes the image: %v", got)
+	}
+	changed := NewStatistics(BrightnessAndContrast(ramp(), stats.Brightness, stats.Contrast, 120, 30), PAL)
+	if changed.Brightness < 119 || changed.Brightness > 121 || changed.Contrast < 29 || changed.Contrast > 31 {
+		t.Errorf("brightness %v and contrast %v, want 120 and 30", changed.Brightness, changed.Contrast)
+	}
+}
+
+// TestHistogramSpecification maps an image with every level once onto known
+// targets.
+func TestHistogramSpecification(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 16, 16))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i)
+	}
+	tests := []struct {
+		name   string
+		d      histogram.Distribution
+		points []*histogram.Point
+		want   func(level int) int
+	}{
+		{"uniform", histogram.Uniform, nil, func(level int) int { return level }},
+		{"upper half", histogram.Curve, points(0, 0, 127, 0, 128, 1, 255, 1), func(level int) int { return 128 + level/2 }},
+	}
+	for _, test := range tests {
+		target, err := histogram.Target(test.d, 0, 0, test.points)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := levels(HistogramSpecification(img, target, nil))
+		for level := 1; level < 256; level++ { // Nothing is below 0, which stays
+			if got, want := int(got[level/16][level%16]), test.want(level); got < want-1 || got > want+1 {
+				t.Errorf("%v: level %v becomes %v, want %v", test.name, level, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package processing
+
+import (
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp" // Registers the decoder, there's no encoder
+
+	"github.com/vision-go/vision-go/pkg/pnm"
+)
+
+// RawExtensions are the extensions of the files read as raw pixels when they
+// aren't in a registered format.
+var RawExtensions = []string{".tfe", ".tfi", ".raw"}
+
+// IsRaw tells if the file called name is read as raw pixels when it isn't in
+// a registered format.
+func IsRaw(name string) bool {
+	extension := strings.ToLower(filepath.Ext(name))
+	for _, raw := range RawExtensions {
+		if extension == raw {
+			return true
+		}
+	}
+	return false
+}
+
+// Decode reads an image in any registered format. The files whose name has
+// one of RawExtensions are read as raw pixels when they aren't in any, with
+// the likeliest options for their size (see GuessRaw); the tfe files, raw
+// 320x200 8-bit grey, are the first guess of their size. Other unknown files
+// give image.ErrFormat.
+func Decode(r io.ReadSeeker, name string) (image.Image, string, error) {
+	inputImg, format, err := image.Decode(r)
+	if err == image.ErrFormat && IsRaw(name) {
+		size, err := r.Seek(0, io.SeekEnd)
+		if err != nil {
+			return nil, RawFormat, err
+		}
+		guesses := GuessRaw(size)
+		if len(guesses) == 0 {
+			return nil, RawFormat, fmt.Errorf("unknown format; it can't be read as raw pixels without giving their dimensions")
+		}
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			return nil, RawFormat, err
+		}
+		inputImg, err := DecodeRaw(r, guesses[0])
+		return inputImg, RawDescription(guesses[0]), err
+	}
+	return inputImg, format, err
+}
+
+// SaveFormats are the formats Encode is able to write, without the other
+// spellings it also takes (jpeg, tiff).
+var SaveFormats = []string{"png", "jpg", "tif", "bmp", "gif", "pgm", "ppm", "pnm"}
+
+// CanEncode tells if Encode is able to write format.
+func CanEncode(format string) bool {
+	switch format {
+	case "png", "jpeg", "jpg", "tif", "tiff", "bmp", "gif", "pgm", "ppm", "pnm":
+		return true
+	}
+	return false
+}
+
+// Encode writes img to w in the given format, one of SaveFormats, with the
+// default options of the encoders.
+func Encode(w io.Writer, img image.Image, format string) error {
+	return EncodeOptions(w, img, format, SaveOptions{})
+}
+
+// EncodeOptions writes img to w in the given format, one of SaveFormats, with
+// the colour type chosen by encodable and the settings of o. pgm converts
+// colour images to grey levels and ppm saves grey ones as colour; pnm picks
+// the one of the image. gif reduces the colours to a palette of 256.
+func EncodeOptions(w io.Writer, img image.Image, format string, o SaveOptions) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	img = encodable(img)
+	pnmOptions := &pnm.Options{Plain: o.PNMPlain}
+	switch format {
+	case "png":
+		return o.png().Encode(w, img)
+	case "jpeg", "jpg":
+		return jpeg.Encode(w, img, o.jpeg())
+	case "tif", "tiff":
+		return tiff.Encode(w, img, o.tiff())
+	case "bmp":
+		return bmp.Encode(w, img)
+	case "gif":
+		return gif.Encode(w, img, nil)
+	case "pgm":
+		if !isGrey(img) {
+			img = encodable(Monochrome(img, o.luminance()))
+		}
+		return pnm.Encode(w, img, pnmOptions)
+	case "ppm":
+		if isGrey(img) { // Written as a pixel map when it isn't grey any more
+			img = FromPlanesDepth(Planes(img), DepthOf(img))
+		}
+		return pnm.Encode(w, img, pnmOptions)
+	case "pnm":
+		return pnm.Encode(w, img, pnmOptions)
+	}
+	return fmt.Errorf("incorrrect format")
+}
+
+func isGrey(img image.Image) bool {
+	switch img.(type) {
+	case *image.Gray, *image.Gray16:
+		return true
+	}
+	return false
+}
+
+// encodable picks the colour type an image is saved with. The encoders follow
+// the type of the image, so opaque images whose three channels are equal are
+// saved as grey whatever type the operations left them in, and float images
+// as 16-bit, the most precise the encoders know.
+func encodable(img image.Image) image.Image {
+	switch img.(type) {
+	case *image.Gray, *image.Gray16, *image.Paletted:
+		return img
+	}
+	depth := DepthOf(img)
+	if depth == DepthFloat {
+		depth = Depth16
+	}
+	planes := Planes(img)
+	if isOpaqueGrey(planes) {
+		return fromGreyPlane(planes[0], depth)
+	}
+	if _, ok := img.(*Float); ok {
+		return FromPlanesDepth(planes, depth)
+	}
+	return img
+}
+
+// Open decodes the image stored in path.
+func Open(path string) (image.Image, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+	return Decode(f, path)
+}
+
+// Extensions are the file extensions Decode is able to read.
+var Extensions = []string{".png", ".jpeg", ".jpg", ".tfe", ".tfi", ".raw", ".tif", ".tiff",
+	".bmp", ".gif", ".webp", ".pbm", ".pgm", ".ppm", ".pnm"}
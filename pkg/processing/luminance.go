@@ -0,0 +1,80 @@
+package processing
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Luminance are the weights of the red, green and blue channels in the grey
+// level of a pixel. They add up to 1. Every function computing grey levels
+// takes them: the grey histogram and the statistics taken from it,
+// Monochrome, ChangeMap and LuminancePlane. PAL are the weights the grey
+// levels always had.
+type Luminance struct {
+	R, G, B float64
+}
+
+var (
+	PAL     = Luminance{0.222, 0.707, 0.071}
+	Rec601  = Luminance{0.299, 0.587, 0.114}
+	Rec709  = Luminance{0.2126, 0.7152, 0.0722}
+	Rec2020 = Luminance{0.2627, 0.6780, 0.0593}
+	Average = Luminance{1.0 / 3, 1.0 / 3, 1.0 / 3}
+)
+
+var luminanceNames = []string{"pal", "rec601", "rec709", "rec2020", "average"}
+
+var luminances = []Luminance{PAL, Rec601, Rec709, Rec2020, Average}
+
+// Luminances returns the names of the standard weights.
+func Luminances() []string {
+	return append([]string(nil), luminanceNames...)
+}
+
+// ParseLuminance reads the name of standard weights or custom ones as
+// "r,g,b", which are scaled to add up to 1.
+func ParseLuminance(text string) (Luminance, error) {
+	for i, name := range luminanceNames {
+		if strings.EqualFold(text, name) {
+			return luminances[i], nil
+		}
+	}
+	fields := strings.Split(text, ",")
+	if len(fields) != 3 {
+		return Luminance{}, fmt.Errorf("the luminance must be one of %v or custom weights as r,g,b", strings.Join(luminanceNames, ", "))
+	}
+	var weights [3]float64
+	for i, field := range fields {
+		weight, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil || weight < 0 {
+			return Luminance{}, fmt.Errorf("the weights of the luminance must be numbers not below 0")
+		}
+		weights[i] = weight
+	}
+	return NewLuminance(weights[0], weights[1], weights[2])
+}
+
+// NewLuminance returns custom weights, scaled to add up to 1.
+func NewLuminance(r, g, b float64) (Luminance, error) {
+	sum := r + g + b
+	if r < 0 || g < 0 || b < 0 || sum <= 0 {
+		return Luminance{}, fmt.Errorf("the weights of the luminance must not be below 0 nor all of them 0")
+	}
+	return Luminance{r / sum, g / sum, b / sum}, nil
+}
+
+// String returns the name of standard weights and r,g,b for custom ones.
+func (l Luminance) String() string {
+	for i, standard := range luminances {
+		if l == standard {
+			return luminanceNames[i]
+		}
+	}
+	return strconv.FormatFloat(l.R, 'g', 4, 64) + "," + strconv.FormatFloat(l.G, 'g', 4, 64) + "," + strconv.FormatFloat(l.B, 'g', 4, 64)
+}
+
+// Grey returns the grey level of a colour.
+func (l Luminance) Grey(r, g, b float64) float64 {
+	return l.R*r + l.G*g + l.B*b
+}
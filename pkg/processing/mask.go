@@ -0,0 +1,137 @@
+package processing
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sort"
+)
+
+// Masks are *image.Alpha in the coordinates of the image they select from:
+// a pixel is selected when its alpha is not 0 and the bounds of the mask are
+// the bounding box of the selection.
+
+// EllipseMask selects the ellipse inscribed in rect.
+func EllipseMask(rect image.Rectangle) *image.Alpha {
+	mask := image.NewAlpha(rect)
+	a, b := float64(rect.Dx())/2, float64(rect.Dy())/2
+	cx, cy := float64(rect.Min.X)+a, float64(rect.Min.Y)+b
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			dx, dy := (float64(x)+0.5-cx)/a, (float64(y)+0.5-cy)/b
+			if dx*dx+dy*dy <= 1 {
+				mask.SetAlpha(x, y, color.Alpha{A: 255})
+			}
+		}
+	}
+	return mask
+}
+
+// ROIMask returns the part of mask inside rect in the coordinates of
+// ROI(img, rect), nil if mask is nil.
+func ROIMask(mask *image.Alpha, rect image.Rectangle) *image.Alpha {
+	if mask == nil {
+		return nil
+	}
+	roi := mask.SubImage(rect).(*image.Alpha)
+	roi.Rect = roi.Rect.Sub(rect.Min)
+	return roi
+}
+
+// PolygonMask selects the inside of the polygon with the given vertices,
+// clipped to bounds. Freehand selections are polygons with many vertices.
+func PolygonMask(vertices []image.Point, bounds image.Rectangle) *image.Alpha {
+	var box image.Rectangle
+	for i, vertex := range vertices {
+		pixel := image.Rectangle{Min: vertex, Max: vertex.Add(image.Pt(1, 1))}
+		if i == 0 {
+			box = pixel
+		} else {
+			box = box.Union(pixel)
+		}
+	}
+	box = box.Intersect(bounds)
+	mask := image.NewAlpha(box)
+	if len(vertices) < 3 {
+		return mask
+	}
+	for y := box.Min.Y; y < box.Max.Y; y++ {
+		center := float64(y) + 0.5
+		var crossings []float64
+		for i := range vertices {
+			p, q := vertices[i], vertices[(i+1)%len(vertices)]
+			py, qy := float64(p.Y)+0.5, float64(q.Y)+0.5
+			if (py <= center) == (qy <= center) {
+				continue
+			}
+			crossings = append(crossings, float64(p.X)+0.5+(center-py)/(qy-py)*float64(q.X-p.X))
+		}
+		sort.Float64s(crossings)
+		for i := 0; i+1 < len(crossings); i += 2 { // Even-odd rule
+			from := int(math.Ceil(crossings[i] - 0.5))
+			to := int(math.Floor(crossings[i+1] - 0.5))
+			for x := from; x <= to; x++ {
+				if x >= box.Min.X && x < box.Max.X {
+					mask.SetAlpha(x, y, color.Alpha{A: 255})
+				}
+			}
+		}
+	}
+	return mask
+}
+
+// MagicWand selects the pixels connected to seed whose channels all differ
+// less than tolerance (0-255) from the colour of seed.
+func MagicWand(img image.Image, seed image.Point, tolerance int) *image.Alpha {
+	bounds := img.Bounds()
+	mask := image.NewAlpha(bounds)
+	if !seed.In(bounds) {
+		return image.NewAlpha(image.Rectangle{})
+	}
+	sr, sg, sb, _ := img.At(seed.X, seed.Y).RGBA()
+	similar := func(x, y int) bool {
+		r, g, b, _ := img.At(x, y).RGBA()
+		return absDiff(r>>8, sr>>8) <= tolerance && absDiff(g>>8, sg>>8) <= tolerance && absDiff(b>>8, sb>>8) <= tolerance
+	}
+	box := image.Rectangle{Min: seed, Max: seed.Add(image.Pt(1, 1))}
+	stack := []image.Point{seed}
+	mask.SetAlpha(seed.X, seed.Y, color.Alpha{A: 255})
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		box = box.Union(image.Rectangle{Min: p, Max: p.Add(image.Pt(1, 1))})
+		for _, n := range []image.Point{{p.X - 1, p.Y}, {p.X + 1, p.Y}, {p.X, p.Y - 1}, {p.X, p.Y + 1}} {
+			if n.In(bounds) && mask.AlphaAt(n.X, n.Y).A == 0 && similar(n.X, n.Y) {
+				mask.SetAlpha(n.X, n.Y, color.Alpha{A: 255})
+				stack = append(stack, n)
+			}
+		}
+	}
+	return mask.SubImage(box).(*image.Alpha)
+}
+
+func absDiff(a, b uint32) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
+
+// CropMasked crops img to the bounding box of mask, the pixels outside of
+// the mask being transparent.
+func CropMasked(img image.Image, mask *image.Alpha) image.Image {
+	b := mask.Bounds()
+	var NewImage draw.Image = image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	if DepthOf(img) != Depth8 {
+		NewImage = newLike(img, NewImage.Bounds(), false, true)
+	}
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			if mask.AlphaAt(x+b.Min.X, y+b.Min.Y).A != 0 {
+				NewImage.Set(x, y, img.At(x+b.Min.X, y+b.Min.Y))
+			}
+		}
+	}
+	return NewImage
+}
@@ -0,0 +1,141 @@
+package processing
+
+import (
+	"image"
+	"image/draw"
+)
+
+// newLike returns an empty image of size rect with the colour model of img
+// where the result fits in it: interpolated pixels need more colours than a
+// palette, and transparent ones, as the corners of a rotation, more than
+// grey levels.
+func newLike(img image.Image, rect image.Rectangle, interpolated, transparent bool) draw.Image {
+	switch img := img.(type) {
+	case *image.Gray:
+		if !transparent {
+			return image.NewGray(rect)
+		}
+	case *image.Gray16:
+		if !transparent {
+			return image.NewGray16(rect)
+		}
+	case *image.NRGBA:
+		return image.NewNRGBA(rect)
+	case *image.NRGBA64:
+		return image.NewNRGBA64(rect)
+	case *image.Paletted:
+		if !interpolated && !transparent {
+			return image.NewPaletted(rect, img.Palette)
+		}
+	}
+	return NewDepthImage(DepthOf(img), rect)
+}
+
+// FromPlanesLike joins the planes into an image with the depth of img and,
+// where the samples fit in it, its colour model: grey images stay grey while
+// the three channels are equal and opaque, and not premultiplied ones stay
+// so. The rest are image.RGBA, image.RGBA64 or Float as in FromPlanesDepth.
+func FromPlanesLike(planes [4]*Plane, img image.Image) image.Image {
+	return fromPlanesModel(planes, img, DepthOf(img))
+}
+
+func fromPlanesModel(planes [4]*Plane, img image.Image, d Depth) image.Image {
+	if d == DepthFloat {
+		return FromPlanesDepth(planes, d)
+	}
+	switch img.(type) {
+	case *image.Gray, *image.Gray16:
+		if isOpaqueGrey(planes) {
+			return fromGreyPlane(planes[0], d)
+		}
+	case *image.NRGBA, *image.NRGBA64:
+		return fromPlanesNotPremultiplied(planes, d)
+	}
+	return FromPlanesDepth(planes, d)
+}
+
+func isNotPremultiplied(img image.Image) bool {
+	switch img.(type) {
+	case *image.NRGBA, *image.NRGBA64:
+		return true
+	}
+	return false
+}
+
+// premultiply multiplies the colour of the planes by their alpha or, if not
+// forward, divides it.
+func premultiply(planes [4]*Plane, forward bool) {
+	for i, alpha := range planes[3].Pix {
+		for c := 0; c < 3; c++ {
+			switch {
+			case forward:
+				planes[c].Pix[i] *= alpha / 255
+			case alpha > 0:
+				planes[c].Pix[i] *= 255 / alpha
+			}
+		}
+	}
+}
+
+func isOpaqueGrey(planes [4]*Plane) bool {
+	for i, alpha := range planes[3].Pix {
+		if alpha < 255 || planes[0].Pix[i] != planes[1].Pix[i] || planes[0].Pix[i] != planes[2].Pix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// fromGreyPlane returns an image.Gray or, for 16 bits, an image.Gray16.
+func fromGreyPlane(p *Plane, d Depth) image.Image {
+	if d == Depth16 {
+		NewImage := image.NewGray16(image.Rect(0, 0, p.Width, p.Height))
+		for i, value := range p.Pix {
+			sample := clamp16(value)
+			NewImage.Pix[2*i], NewImage.Pix[2*i+1] = uint8(sample>>8), uint8(sample)
+		}
+		return NewImage
+	}
+	NewImage := image.NewGray(image.Rect(0, 0, p.Width, p.Height))
+	for i, value := range p.Pix {
+		NewImage.Pix[i] = Clamp(value)
+	}
+	return NewImage
+}
+
+// fromPlanesNotPremultiplied divides the colour of the planes by their alpha
+// into an image.NRGBA or, for 16 bits, an image.NRGBA64.
+func fromPlanesNotPremultiplied(planes [4]*Plane, d Depth) image.Image {
+	w, h := planes[0].Width, planes[0].Height
+	var NewImage draw.Image = image.NewNRGBA(image.Rect(0, 0, w, h))
+	if d == Depth16 {
+		NewImage = image.NewNRGBA64(image.Rect(0, 0, w, h))
+	}
+	ParallelRows(h, func(y int) {
+		for x := 0; x < w; x++ {
+			i := y*w + x
+			alpha := planes[3].Pix[i]
+			var samples [4]float32
+			if alpha > 0 {
+				for c := 0; c < 3; c++ {
+					samples[c] = planes[c].Pix[i] * 255 / alpha
+				}
+				samples[3] = alpha
+			}
+			switch NewImage := NewImage.(type) {
+			case *image.NRGBA64:
+				row := NewImage.Pix[NewImage.PixOffset(x, y):]
+				for c, sample := range samples {
+					value := clamp16(sample)
+					row[2*c], row[2*c+1] = uint8(value>>8), uint8(value)
+				}
+			case *image.NRGBA:
+				row := NewImage.Pix[NewImage.PixOffset(x, y):]
+				for c, sample := range samples {
+					row[c] = Clamp(sample)
+				}
+			}
+		}
+	})
+	return NewImage
+}
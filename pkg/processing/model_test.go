@@ -0,0 +1,89 @@
+package processing
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"testing"
+)
+
+// models returns a grey, a translucent not premultiplied and a paletted
+// version of ramp.
+func models() []image.Image {
+	grey := ramp()
+	translucent := image.NewNRGBA(grey.Rect)
+	paletted := image.NewPaletted(grey.Rect, palette.Plan9)
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			level := grey.GrayAt(x, y).Y
+			translucent.SetNRGBA(x, y, color.NRGBA{level, level / 2, 255 - level, 100})
+			paletted.Set(x, y, color.Gray{Y: level})
+		}
+	}
+	return []image.Image{grey, translucent, paletted}
+}
+
+func TestColourModel(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func(image.Image) image.Image
+		want []string // Type of the result for each of models
+	}{
+		{"negative", Negative, []string{"*image.Gray", "*image.NRGBA", "*image.RGBA"}},
+		{"gamma", func(img image.Image) image.Image { return GammaCorrection(img, 2) }, []string{"*image.Gray", "*image.NRGBA", "*image.RGBA"}},
+		{"horizontal mirror", HorizontalMirror, []string{"*image.Gray", "*image.NRGBA", "*image.Paletted"}},
+		{"rotate right", RotateRight, []string{"*image.Gray", "*image.NRGBA", "*image.Paletted"}},
+		{"roi", func(img image.Image) image.Image { return ROI(img, image.Rect(1, 0, 3, 2)) }, []string{"*image.Gray", "*image.NRGBA", "*image.Paletted"}},
+		{"rotate 30", func(img image.Image) image.Image { return Rotate(img, 30, 1) }, []string{"*image.RGBA", "*image.NRGBA", "*image.RGBA"}},
+		{"monochrome", func(img image.Image) image.Image { return Monochrome(img, PAL) }, []string{"*image.Gray", "*image.Gray", "*image.Gray"}},
+	}
+	for _, test := range tests {
+		for i, img := range models() {
+			if got := fmt.Sprintf("%T", test.op(img)); got != test.want[i] {
+				t.Errorf("%v of a %T: %v, want %v", test.name, img, got, test.want[i])
+			}
+		}
+	}
+}
+
+// TestNotPremultiplied checks that translucent colours aren't rounded by
+// going through premultiplied ones.
+func TestNotPremultiplied(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{201, 99, 7, 3})
+	result, ok := Negative(img).(*image.NRGBA)
+	if !ok {
+		t.Fatalf("the result is a %T", Negative(img))
+	}
+	if got, want := result.NRGBAAt(0, 0), (color.NRGBA{54, 156, 248, 3}); got != want {
+		t.Errorf("%v becomes %v, want %v", img.NRGBAAt(0, 0), got, want)
+	}
+}
+
+// TestEncodeGrey checks that grey images are saved as grey, even when an
+// operation leaves them in a colour type.
+func TestEncodeGrey(t *testing.T) {
+	colour := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			colour.Set(x, y, ramp().At(x, y))
+		}
+	}
+	for _, format := range []string{"png", "tif", "pnm"} {
+		for _, img := range []image.Image{ramp(), colour} {
+			var buf bytes.Buffer
+			if err := Encode(&buf, img, format); err != nil {
+				t.Fatal(err)
+			}
+			decoded, _, err := Decode(bytes.NewReader(buf.Bytes()), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := decoded.(*image.Gray); !ok {
+				t.Errorf("%v of a %T: read as %T", format, img, decoded)
+			}
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package processing
+
+import (
+	"image"
+	"runtime"
+	"sync"
+)
+
+// Plane is one channel of an image as float32 samples, 0-255 for the usual
+// 8-bit channels and fractions of them for deeper ones, so that spatial
+// filters can work without rounding in between passes.
+type Plane struct {
+	Width, Height int
+	Pix           []float32 // Row major
+}
+
+func NewPlane(width, height int) *Plane {
+	return &Plane{Width: width, Height: height, Pix: make([]float32, width*height)}
+}
+
+func (p *Plane) At(x, y int) float32 {
+	return p.Pix[y*p.Width+x]
+}
+
+func (p *Plane) Set(x, y int, value float32) {
+	p.Pix[y*p.Width+x] = value
+}
+
+// Planes splits img into its red, green, blue and alpha planes.
+func Planes(img image.Image) [4]*Plane {
+	b := img.Bounds()
+	var planes [4]*Plane
+	for i := range planes {
+		planes[i] = NewPlane(b.Dx(), b.Dy())
+	}
+	ParallelRows(b.Dy(), func(y int) {
+		switch img := img.(type) { // Fast paths for the usual types
+		case *image.RGBA:
+			row := img.Pix[img.PixOffset(b.Min.X, y+b.Min.Y):]
+			for x := 0; x < b.Dx(); x++ {
+				for c := range planes {
+					planes[c].Pix[y*b.Dx()+x] = float32(row[4*x+c])
+				}
+			}
+			return
+		case *Float:
+			row := img.Pix[img.PixOffset(b.Min.X, y+b.Min.Y):]
+			for x := 0; x < b.Dx(); x++ {
+				for c := range planes {
+					planes[c].Pix[y*b.Dx()+x] = row[4*x+c]
+				}
+			}
+			return
+		case *image.Gray:
+			row := img.Pix[img.PixOffset(b.Min.X, y+b.Min.Y):]
+			for x := 0; x < b.Dx(); x++ {
+				i := y*b.Dx() + x
+				planes[0].Pix[i], planes[1].Pix[i], planes[2].Pix[i], planes[3].Pix[i] = float32(row[x]), float32(row[x]), float32(row[x]), 255
+			}
+			return
+		}
+		for x := 0; x < b.Dx(); x++ {
+			r, g, bl, a := img.At(x+b.Min.X, y+b.Min.Y).RGBA()
+			i := y*b.Dx() + x
+			planes[0].Pix[i] = float32(r) / 257 // Exact for 8-bit samples
+			planes[1].Pix[i] = float32(g) / 257
+			planes[2].Pix[i] = float32(bl) / 257
+			planes[3].Pix[i] = float32(a) / 257
+		}
+	})
+	return planes
+}
+
+// FromPlanes joins the red, green, blue and alpha planes into an image,
+// rounding and clamping the samples to 0-255.
+func FromPlanes(planes [4]*Plane) image.Image {
+	return FromPlanesDepth(planes, Depth8)
+}
+
+// FromPlanesDepth joins the planes into an image with the depth d. Only
+// Float keeps the samples out of 0-255.
+func FromPlanesDepth(planes [4]*Plane, d Depth) image.Image {
+	w, h := planes[0].Width, planes[0].Height
+	switch d {
+	case Depth16:
+		NewImage := image.NewRGBA64(image.Rect(0, 0, w, h))
+		ParallelRows(h, func(y int) {
+			row := NewImage.Pix[y*NewImage.Stride:]
+			for x := 0; x < w; x++ {
+				for c := range planes {
+					value := clamp16(planes[c].Pix[y*w+x])
+					row[8*x+2*c], row[8*x+2*c+1] = uint8(value>>8), uint8(value)
+				}
+			}
+		})
+		return NewImage
+	case DepthFloat:
+		NewImage := NewFloat(image.Rect(0, 0, w, h))
+		ParallelRows(h, func(y int) {
+			row := NewImage.Pix[y*NewImage.Stride:]
+			for x := 0; x < w; x++ {
+				for c := range planes {
+					row[4*x+c] = planes[c].Pix[y*w+x]
+				}
+			}
+		})
+		return NewImage
+	}
+	NewImage := image.NewRGBA(image.Rect(0, 0, w, h))
+	ParallelRows(h, func(y int) {
+		row := NewImage.Pix[y*NewImage.Stride:]
+		for x := 0; x < w; x++ {
+			for c := range planes {
+				row[4*x+c] = Clamp(planes[c].Pix[y*w+x])
+			}
+		}
+	})
+	return NewImage
+}
+
+// Clamp rounds a sample to the nearest 8-bit value.
+func Clamp(value float32) uint8 {
+	switch {
+	case value <= 0 || value != value: // NaN
+		return 0
+	case value >= 255:
+		return 255
+	}
+	return uint8(value + 0.5)
+}
+
+// ParallelRows calls row for every y in [0, height) spreading the rows among
+// the CPUs.
+func ParallelRows(height int, row func(y int)) {
+	workers := runtime.NumCPU()
+	if workers > height {
+		workers = height
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for y := w; y < height; y += workers {
+				row(y)
+			}
+		}(w)
+	}
+	wg.Wait()
+}
+
+// LuminancePlane returns the grey level of every pixel of img with the
+// weights l.
+func LuminancePlane(img image.Image, l Luminance) *Plane {
+	planes := Planes(img)
+	grey := NewPlane(planes[0].Width, planes[0].Height)
+	r, g, b := float32(l.R), float32(l.G), float32(l.B)
+	for i := range grey.Pix {
+		grey.Pix[i] = r*planes[0].Pix[i] + g*planes[1].Pix[i] + b*planes[2].Pix[i]
+	}
+	return grey
+}
+
+// GreyImage returns an opaque grey image with the samples of p.
+func GreyImage(p *Plane) image.Image {
+	return GreyImageDepth(p, Depth8)
+}
+
+// GreyImageDepth returns an opaque grey image with the samples of p and the
+// depth d: image.Gray, image.Gray16 or Float.
+func GreyImageDepth(p *Plane, d Depth) image.Image {
+	if d != DepthFloat {
+		return fromGreyPlane(p, d)
+	}
+	opaque := NewPlane(p.Width, p.Height)
+	for i := range opaque.Pix {
+		opaque.Pix[i] = 255
+	}
+	return FromPlanesDepth([4]*Plane{p, p, p, opaque}, d)
+}
@@ -0,0 +1,304 @@
+package processing
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RawFormat starts the format of the files read as raw pixels, see
+// RawDescription.
+const RawFormat = "raw"
+
+// RawDescription is the format of a file read as raw pixels with o.
+func RawDescription(o RawOptions) string {
+	return RawFormat + " (" + o.String() + ")"
+}
+
+// RawOptions describe the pixels of a raw file: Offset bytes of header and
+// then Height rows of Width pixels, whose Channels samples (grey, RGB or
+// RGBA) are interleaved.
+type RawOptions struct {
+	Width, Height int
+	BitDepth      int  // 8 or 16
+	BigEndian     bool // Byte order of the 16-bit samples
+	Channels      int  // 1, 3 or 4
+	Offset        int64
+}
+
+// DefaultRaw are the options of the tfe files, raw 320x200 8-bit grey.
+var DefaultRaw = RawOptions{Width: 320, Height: 200, BitDepth: 8, Channels: 1}
+
+func (o RawOptions) Validate() error {
+	if o.Width < 1 || o.Height < 1 {
+		return fmt.Errorf("the width and the height of a raw image must be positive")
+	}
+	if o.BitDepth != 8 && o.BitDepth != 16 {
+		return fmt.Errorf("the bit depth of a raw image must be 8 or 16")
+	}
+	if o.Channels != 1 && o.Channels != 3 && o.Channels != 4 {
+		return fmt.Errorf("a raw image must have 1, 3 or 4 channels")
+	}
+	if o.Offset < 0 {
+		return fmt.Errorf("the header of a raw image can't be negative")
+	}
+	// Divided instead of multiplied so that Size can't overflow
+	if int64(o.Width) > math.MaxInt32/int64(o.Height)/int64(o.Channels*o.BitDepth/8) {
+		return fmt.Errorf("a raw image of %vx%v pixels is too large", o.Width, o.Height)
+	}
+	return nil
+}
+
+// Size returns the bytes of the pixels, without the header.
+func (o RawOptions) Size() int64 {
+	return int64(o.Width) * int64(o.Height) * int64(o.Channels) * int64(o.BitDepth/8)
+}
+
+// String writes the options the way ParseRawOptions reads them.
+func (o RawOptions) String() string {
+	order := "little"
+	if o.BigEndian {
+		order = "big"
+	}
+	return fmt.Sprintf("width=%v,height=%v,depth=%v,order=%v,channels=%v,offset=%v", o.Width, o.Height, o.BitDepth, order, o.Channels, o.Offset)
+}
+
+// ParseRawOptions reads "width=640,height=480,depth=16,order=big,channels=1,
+// offset=0". Only the width and the height are required; the rest default to
+// 8-bit little endian grey without header.
+func ParseRawOptions(text string) (RawOptions, error) {
+	o := RawOptions{BitDepth: 8, Channels: 1}
+	for _, field := range strings.Split(text, ",") {
+		pair := strings.SplitN(field, "=", 2)
+		if len(pair) != 2 {
+			return o, fmt.Errorf("raw options must be written as key=value, not %q", field)
+		}
+		key, value := strings.ToLower(strings.TrimSpace(pair[0])), strings.TrimSpace(pair[1])
+		if key == "order" {
+			switch strings.ToLower(value) {
+			case "little":
+				o.BigEndian = false
+			case "big":
+				o.BigEndian = true
+			default:
+				return o, fmt.Errorf("the byte order must be little or big")
+			}
+			continue
+		}
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return o, fmt.Errorf("the raw option %v must be an integer", key)
+		}
+		switch key {
+		case "width":
+			o.Width = int(number)
+		case "height":
+			o.Height = int(number)
+		case "depth":
+			o.BitDepth = int(number)
+		case "channels":
+			o.Channels = int(number)
+		case "offset":
+			o.Offset = number
+		default:
+			return o, fmt.Errorf("unknown raw option %v", key)
+		}
+	}
+	return o, o.Validate()
+}
+
+// DecodeRaw reads the pixels described by o, giving Gray, RGBA or NRGBA
+// images for 8-bit samples and Gray16 or NRGBA64 for 16-bit ones. An alpha
+// channel is taken as not premultiplied.
+func DecodeRaw(r io.Reader, o RawOptions) (image.Image, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	data, err := readRaw(r, o)
+	if err != nil {
+		return nil, err
+	}
+	bounds := image.Rect(0, 0, o.Width, o.Height)
+	if o.BitDepth == 8 {
+		switch o.Channels {
+		case 1:
+			return &image.Gray{Pix: data, Stride: o.Width, Rect: bounds}, nil
+		case 4:
+			return &image.NRGBA{Pix: data, Stride: 4 * o.Width, Rect: bounds}, nil
+		}
+		img := image.NewRGBA(bounds)
+		for i := 0; i < o.Width*o.Height; i++ {
+			copy(img.Pix[4*i:4*i+3], data[3*i:3*i+3])
+			img.Pix[4*i+3] = 255
+		}
+		return img, nil
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if o.BigEndian {
+		order = binary.BigEndian
+	}
+	sample := func(i int) uint16 { return order.Uint16(data[2*i:]) }
+	if o.Channels == 1 {
+		img := image.NewGray16(bounds)
+		for i := 0; i < o.Width*o.Height; i++ {
+			img.SetGray16(i%o.Width, i/o.Width, color.Gray16{Y: sample(i)})
+		}
+		return img, nil
+	}
+	img := image.NewNRGBA64(bounds)
+	for i := 0; i < o.Width*o.Height; i++ {
+		c := color.NRGBA64{R: sample(o.Channels * i), G: sample(o.Channels*i + 1), B: sample(o.Channels*i + 2), A: 0xffff}
+		if o.Channels == 4 {
+			c.A = sample(o.Channels*i + 3)
+		}
+		img.SetNRGBA64(i%o.Width, i/o.Width, c)
+	}
+	return img, nil
+}
+
+// readRaw skips the header and returns the bytes of the pixels. The file is
+// checked to be long enough before allocating them when its length is known,
+// and read as it comes otherwise, so that wrong dimensions give an error
+// instead of running out of memory.
+func readRaw(r io.Reader, o RawOptions) ([]byte, error) {
+	if length, ok := remaining(r); ok && length < o.Offset+o.Size() {
+		return nil, fmt.Errorf("the raw file is shorter than %vx%v pixels after its header", o.Width, o.Height)
+	}
+	if _, err := io.CopyN(io.Discard, r, o.Offset); err != nil {
+		return nil, fmt.Errorf("the raw file is shorter than its header: %w", err)
+	}
+	var data bytes.Buffer
+	if _, err := io.CopyN(&data, r, o.Size()); err != nil {
+		return nil, fmt.Errorf("the raw file is shorter than %vx%v pixels: %w", o.Width, o.Height, err)
+	}
+	return data.Bytes(), nil
+}
+
+// remaining returns the bytes left to read in r if it can seek. Pipes are
+// files that can't.
+func remaining(r io.Reader) (int64, bool) {
+	seeker, ok := r.(io.Seeker)
+	if !ok {
+		return 0, false
+	}
+	current, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, false
+	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, false
+	}
+	if _, err := seeker.Seek(current, io.SeekStart); err != nil {
+		return 0, false
+	}
+	return end - current, true
+}
+
+// OpenRaw decodes the raw file stored in path.
+func OpenRaw(path string, o RawOptions) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return DecodeRaw(f, o)
+}
+
+// commonSizes are the usual dimensions of sensors and screens, tried first
+// when guessing.
+var commonSizes = [][2]int{
+	{320, 200}, {320, 240}, {640, 480}, {752, 480}, {800, 600}, {1024, 768},
+	{1280, 720}, {1280, 960}, {1280, 1024}, {1600, 1200}, {1920, 1080},
+	{2048, 1536}, {2560, 1920}, {3840, 2160}, {4096, 3072},
+}
+
+// GuessRaw returns the options that could have made a raw file of size bytes,
+// the likeliest first: the common dimensions, then the common dimensions
+// with the rest of the file as header, then square images and then the one
+// closest to 4:3. Within each of them 8-bit grey goes before 16-bit and
+// before colour.
+func GuessRaw(size int64) []RawOptions {
+	type guess struct {
+		RawOptions
+		rank int
+	}
+	var guesses []guess
+	// The rank is group*1000 + format*100 + index of the common dimensions
+	for format, kind := range [][2]int{{1, 8}, {1, 16}, {3, 8}, {3, 16}, {4, 8}, {4, 16}} {
+		o := RawOptions{Channels: kind[0], BitDepth: kind[1]}
+		for i, common := range commonSizes {
+			o.Width, o.Height = common[0], common[1]
+			if header := size - o.Size(); header >= 0 && header < 4096 {
+				o.Offset = header
+				group := 0
+				if header != 0 {
+					group = 1
+				}
+				guesses = append(guesses, guess{o, group*1000 + format*100 + i})
+			}
+		}
+		o.Offset = 0
+		sampleSize := int64(kind[0] * kind[1] / 8)
+		if size%sampleSize != 0 {
+			continue
+		}
+		if width, height, square := dimensions(size / sampleSize); width != 0 && !isCommon(width, height) {
+			o.Width, o.Height = width, height
+			group := 2
+			if !square {
+				group = 3
+			}
+			guesses = append(guesses, guess{o, group*1000 + format*100})
+		}
+	}
+	sort.SliceStable(guesses, func(i, j int) bool { return guesses[i].rank < guesses[j].rank })
+	options := make([]RawOptions, len(guesses))
+	for i, guess := range guesses {
+		options[i] = guess.RawOptions
+	}
+	return options
+}
+
+// dimensions factors pixels as the image closest to 4:3, wider than tall,
+// telling if it is square.
+func dimensions(pixels int64) (width, height int, square bool) {
+	best := -1.0
+	for h := int64(1); h*h <= pixels; h++ {
+		if pixels%h != 0 {
+			continue
+		}
+		w := pixels / h
+		if w/h > 16 { // Too thin to be an image
+			continue
+		}
+		distance := float64(w)/float64(h) - 4.0/3
+		if distance < 0 {
+			distance = -distance
+		}
+		if w == h {
+			return int(w), int(h), true
+		}
+		if best < 0 || distance < best {
+			best, width, height = distance, int(w), int(h)
+		}
+	}
+	return width, height, false
+}
+
+func isCommon(width, height int) bool {
+	for _, common := range commonSizes {
+		if common[0] == width && common[1] == height {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package processing
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDecodeRaw(t *testing.T) {
+	tests := []struct {
+		name string
+		o    RawOptions
+		data []byte
+		want []color.Color // The pixels in order
+	}{
+		{"8-bit grey", RawOptions{Width: 2, Height: 1, BitDepth: 8, Channels: 1},
+			[]byte{10, 200}, []color.Color{color.Gray{Y: 10}, color.Gray{Y: 200}}},
+		{"header", RawOptions{Width: 1, Height: 2, BitDepth: 8, Channels: 1, Offset: 3},
+			[]byte{1, 2, 3, 40, 50}, []color.Color{color.Gray{Y: 40}, color.Gray{Y: 50}}},
+		{"16-bit little endian", RawOptions{Width: 2, Height: 1, BitDepth: 16, Channels: 1},
+			[]byte{0x34, 0x12, 0xff, 0x00}, []color.Color{color.Gray16{Y: 0x1234}, color.Gray16{Y: 0x00ff}}},
+		{"16-bit big endian", RawOptions{Width: 2, Height: 1, BitDepth: 16, Channels: 1, BigEndian: true},
+			[]byte{0x34, 0x12, 0xff, 0x00}, []color.Color{color.Gray16{Y: 0x3412}, color.Gray16{Y: 0xff00}}},
+		{"8-bit rgb", RawOptions{Width: 2, Height: 1, BitDepth: 8, Channels: 3},
+			[]byte{1, 2, 3, 4, 5, 6}, []color.Color{color.RGBA{1, 2, 3, 255}, color.RGBA{4, 5, 6, 255}}},
+		{"8-bit rgba", RawOptions{Width: 1, Height: 1, BitDepth: 8, Channels: 4},
+			[]byte{200, 100, 50, 128}, []color.Color{color.NRGBA{200, 100, 50, 128}}},
+		{"16-bit rgb", RawOptions{Width: 1, Height: 1, BitDepth: 16, Channels: 3, BigEndian: true},
+			[]byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc}, []color.Color{color.NRGBA64{0x1234, 0x5678, 0x9abc, 0xffff}}},
+		{"16-bit rgba", RawOptions{Width: 1, Height: 1, BitDepth: 16, Channels: 4},
+			[]byte{1, 0, 2, 0, 3, 0, 0, 0x80}, []color.Color{color.NRGBA64{1, 2, 3, 0x8000}}},
+	}
+	for _, test := range tests {
+		img, err := DecodeRaw(bytes.NewReader(test.data), test.o)
+		if err != nil {
+			t.Errorf("%v: %v", test.name, err)
+			continue
+		}
+		if img.Bounds() != image.Rect(0, 0, test.o.Width, test.o.Height) {
+			t.Errorf("%v: bounds %v", test.name, img.Bounds())
+		}
+		for i, want := range test.want {
+			got := img.At(i%test.o.Width, i/test.o.Width)
+			if img.ColorModel().Convert(want) != got {
+				t.Errorf("%v: pixel %v is %v, want %v", test.name, i, got, want)
+			}
+		}
+	}
+}
+
+func TestDecodeRawErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		o    RawOptions
+		data []byte
+	}{
+		{"short", RawOptions{Width: 2, Height: 2, BitDepth: 8, Channels: 1}, []byte{1, 2, 3}},
+		{"short header", RawOptions{Width: 1, Height: 1, BitDepth: 8, Channels: 1, Offset: 10}, []byte{1, 2, 3}},
+		{"huge", RawOptions{Width: 100000, Height: 100000, BitDepth: 16, Channels: 4}, []byte{1, 2, 3}},
+		{"bit depth", RawOptions{Width: 1, Height: 1, BitDepth: 12, Channels: 1}, []byte{1, 2}},
+		{"channels", RawOptions{Width: 1, Height: 1, BitDepth: 8, Channels: 2}, []byte{1, 2}},
+	}
+	for _, test := range tests {
+		if _, err := DecodeRaw(bytes.NewReader(test.data), test.o); err == nil {
+			t.Errorf("%v: no error", test.name)
+		}
+		// Without seeking, as a pipe
+		if _, err := DecodeRaw(bytes.NewBuffer(test.data), test.o); err == nil {
+			t.Errorf("%v: no error without seeking", test.name)
+		}
+	}
+}
+
+func TestParseRawOptions(t *testing.T) {
+	o, err := ParseRawOptions("width=640, height=480,depth=16,order=big,channels=3,offset=12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := RawOptions{Width: 640, Height: 480, BitDepth: 16, BigEndian: true, Channels: 3, Offset: 12}
+	if o != want {
+		t.Errorf("got %+v, want %+v", o, want)
+	}
+	if again, err := ParseRawOptions(o.String()); err != nil || again != o {
+		t.Errorf("String doesn't parse back: %+v, %v", again, err)
+	}
+	for _, text := range []string{"width=640", "width=640,height=480,order=middle", "width=a,height=1", "size=3"} {
+		if _, err := ParseRawOptions(text); err == nil {
+			t.Errorf("%q: no error", text)
+		}
+	}
+}
+
+func TestGuessRaw(t *testing.T) {
+	tests := []struct {
+		size int64
+		want RawOptions // The first guess
+	}{
+		{64000, RawOptions{Width: 320, Height: 200, BitDepth: 8, Channels: 1}},          // tfe
+		{128000, RawOptions{Width: 320, Height: 200, BitDepth: 16, Channels: 1}},        // Common before square
+		{640 * 480 * 3, RawOptions{Width: 1280, Height: 720, BitDepth: 8, Channels: 1}}, // Grey before colour
+		{800*600 + 100, RawOptions{Width: 800, Height: 600, BitDepth: 8, Channels: 1, Offset: 100}},
+		{64512, RawOptions{Width: 320, Height: 200, BitDepth: 8, Channels: 1, Offset: 512}},
+		{10000, RawOptions{Width: 100, Height: 100, BitDepth: 8, Channels: 1}},
+		{12, RawOptions{Width: 2, Height: 2, BitDepth: 8, Channels: 3}}, // Square before 4:3
+	}
+	for _, test := range tests {
+		guesses := GuessRaw(test.size)
+		if len(guesses) == 0 {
+			t.Errorf("%v bytes: no guess", test.size)
+			continue
+		}
+		if guesses[0] != test.want {
+			t.Errorf("%v bytes: first guess %+v, want %+v", test.size, guesses[0], test.want)
+		}
+		for _, guess := range guesses {
+			if guess.Offset+guess.Size() != test.size {
+				t.Errorf("%v bytes: guess %+v doesn't fill the file", test.size, guess)
+			}
+		}
+	}
+}
+
+func TestDecodeRawFallback(t *testing.T) {
+	data := bytes.Repeat([]byte("not an image "), 5000)[:64000]
+	if _, _, err := Decode(bytes.NewReader(data), "notes.txt"); err != image.ErrFormat {
+		t.Errorf("an unknown file gives %v, want image.ErrFormat", err)
+	}
+	img, format, err := Decode(bytes.NewReader(data), "capture.TFE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != 320 || img.Bounds().Dy() != 200 || format != RawDescription(DefaultRaw) {
+		t.Errorf("a tfe file is read as %v, %v", img.Bounds(), format)
+	}
+}
@@ -0,0 +1,226 @@
+package processing
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/vision-go/vision-go/pkg/histogram"
+)
+
+// Histograms groups the absolute, accumulative and normalized histograms of
+// every RGB channel and of the grey level.
+type Histograms struct {
+	HistogramR histogram.Histogram
+	HistogramG histogram.Histogram
+	HistogramB histogram.Histogram
+	Histogram  histogram.Histogram
+
+	HistogramAccumulativeR histogram.Histogram
+	HistogramAccumulativeG histogram.Histogram
+	HistogramAccumulativeB histogram.Histogram
+	HistogramAccumulative  histogram.Histogram
+
+	HistogramNormalizedR histogram.HistogramNormalized
+	HistogramNormalizedG histogram.HistogramNormalized
+	HistogramNormalizedB histogram.HistogramNormalized
+	HistogramNormalized  histogram.HistogramNormalized
+}
+
+// Statistics are the values shown in the information view of an image.
+type Statistics struct {
+	Histograms
+	Size               int
+	Brightness         float64 // Of the grey levels
+	Contrast           float64
+	ChannelBrightness  [3]float64 // Of the red, green and blue channels
+	ChannelContrast    [3]float64
+	Entropy            float64
+	NumberOfColors     int
+	MinColor, MaxColor int
+}
+
+// NewHistograms counts the levels of img, its grey levels with the weights l.
+func NewHistograms(img image.Image, l Luminance) (hist Histograms) {
+	b := img.Bounds()
+	return newHistograms(img, image.Rect(0, 0, b.Dx(), b.Dy()), nil, l)
+}
+
+// newHistograms counts the pixels of rect that are inside mask, all of them
+// if mask is nil.
+func newHistograms(img image.Image, rect image.Rectangle, mask *image.Alpha, l Luminance) (hist Histograms) {
+	size := 0
+	for i := rect.Min.X; i < rect.Max.X; i++ {
+		for j := rect.Min.Y; j < rect.Max.Y; j++ {
+			if mask != nil && mask.AlphaAt(i, j).A == 0 {
+				continue
+			}
+			size++
+			r, g, b, a := img.At(i, j).RGBA()
+			if a != 0 {
+				r, g, b = r>>8, g>>8, b>>8
+				hist.HistogramR[r] = hist.HistogramR.At(int(r)) + 1
+				hist.HistogramG[g] = hist.HistogramG.At(int(g)) + 1
+				hist.HistogramB[b] = hist.HistogramB.At(int(b)) + 1
+
+				grey := l.Grey(float64(r), float64(g), float64(b))
+				hist.Histogram[int(math.Round(grey))] = hist.Histogram.At(int(math.Round(grey))) + 1
+			}
+		}
+	}
+	for index := range hist.Histogram {
+		for i := 0; i < index; i++ {
+			hist.HistogramAccumulativeR[index] += hist.HistogramR.At(i)
+			hist.HistogramAccumulativeG[index] += hist.HistogramG.At(i)
+			hist.HistogramAccumulativeB[index] += hist.HistogramB.At(i)
+			hist.HistogramAccumulative[index] += hist.Histogram.At(i)
+		}
+	}
+	for i := 0; i < 256; i++ {
+		hist.HistogramNormalized[i] = float64(hist.Histogram[i]) / float64(size)
+		hist.HistogramNormalizedR[i] = float64(hist.HistogramR[i]) / float64(size)
+		hist.HistogramNormalizedG[i] = float64(hist.HistogramG[i]) / float64(size)
+		hist.HistogramNormalizedB[i] = float64(hist.HistogramB[i]) / float64(size)
+	}
+	return hist
+}
+
+// MaxBins is the finest binned histogram, one bin per level of the 16-bit
+// images.
+const MaxBins = 65536
+
+// BinnedHistograms are the histograms of the grey level and of every RGB
+// channel with a chosen number of bins.
+type BinnedHistograms struct {
+	Histogram, HistogramR, HistogramG, HistogramB histogram.Bins
+}
+
+// NewBinnedHistograms counts the samples of img in bins bins, reading them
+// with the precision of its depth, and its grey levels with the weights l.
+func NewBinnedHistograms(img image.Image, bins int, l Luminance) (hist BinnedHistograms, err error) {
+	if bins < 2 || bins > MaxBins {
+		return hist, fmt.Errorf("the histogram must have between 2 and %v bins", MaxBins)
+	}
+	channels := []*histogram.Bins{&hist.HistogramR, &hist.HistogramG, &hist.HistogramB, &hist.Histogram}
+	for _, channel := range channels {
+		*channel = make(histogram.Bins, bins)
+	}
+	bin := func(value float32) int {
+		index := int(math.Round(float64(value) / 255 * float64(bins-1)))
+		if index < 0 {
+			return 0
+		} else if index >= bins {
+			return bins - 1
+		}
+		return index
+	}
+	planes := Planes(img)
+	for i, alpha := range planes[3].Pix {
+		if alpha == 0 {
+			continue
+		}
+		r, g, b := planes[0].Pix[i], planes[1].Pix[i], planes[2].Pix[i]
+		grey := float32(l.Grey(float64(r), float64(g), float64(b)))
+		for c, value := range []float32{r, g, b, grey} {
+			(*channels[c])[bin(value)]++
+		}
+	}
+	return hist, nil
+}
+
+// NewStatistics takes the brightness and contrast of the grey levels with the
+// weights l.
+func NewStatistics(img image.Image, l Luminance) (stats Statistics) {
+	stats.Size = img.Bounds().Dx() * img.Bounds().Dy()
+	stats.Histograms = NewHistograms(img, l)
+	stats.calculate()
+	return stats
+}
+
+// NewMaskedStatistics only takes into account the pixels inside mask, which
+// is in the coordinates of img, every pixel if mask is nil.
+func NewMaskedStatistics(img image.Image, mask *image.Alpha, l Luminance) (stats Statistics) {
+	stats.Histograms, stats.Size = maskedHistograms(img, mask, l)
+	stats.calculate()
+	return stats
+}
+
+// maskedHistograms returns the histograms of the pixels inside mask, of
+// every pixel if mask is nil, and how many pixels they counted.
+func maskedHistograms(img image.Image, mask *image.Alpha, l Luminance) (Histograms, int) {
+	if mask == nil {
+		return NewHistograms(img, l), pixels(img)
+	}
+	size := 0
+	for y := mask.Rect.Min.Y; y < mask.Rect.Max.Y; y++ {
+		for x := mask.Rect.Min.X; x < mask.Rect.Max.X; x++ {
+			if mask.AlphaAt(x, y).A != 0 {
+				size++
+			}
+		}
+	}
+	return newHistograms(img, mask.Bounds(), mask, l), size
+}
+
+func (stats *Statistics) calculate() {
+	stats.MinColor, stats.MaxColor = stats.calculateMinAndMaxColor()
+	stats.Brightness = stats.calculateBrightness(stats.Histogram)
+	stats.Contrast = stats.calculateContrast(stats.Histogram, stats.Brightness)
+	for i, hist := range []histogram.Histogram{stats.HistogramR, stats.HistogramG, stats.HistogramB} {
+		stats.ChannelBrightness[i] = stats.calculateBrightness(hist)
+		stats.ChannelContrast[i] = stats.calculateContrast(hist, stats.ChannelBrightness[i])
+	}
+	stats.Entropy, stats.NumberOfColors = stats.calculateEntropyAndNumberOfColors()
+}
+
+func (stats *Statistics) calculateBrightness(hist histogram.Histogram) (value float64) {
+	for color, count := range hist {
+		value += float64(color * count)
+	}
+	return value / float64(stats.Size)
+}
+
+func (stats *Statistics) calculateContrast(hist histogram.Histogram, brightness float64) (value float64) {
+	for color, count := range hist {
+		value += float64(count) * (float64(color) - brightness) * (float64(color) - brightness)
+	}
+	return math.Sqrt(value / float64(stats.Size))
+}
+
+func (stats *Statistics) calculateMinAndMaxColor() (min, max int) {
+	for color, count := range stats.Histogram {
+		if count != 0 {
+			min = color
+			break
+		}
+	}
+	for max = stats.Histogram.Len() - 1; max >= 0; max-- {
+		if stats.Histogram.At(max) != 0 {
+			break
+		}
+	}
+	return
+}
+
+func (stats *Statistics) calculateEntropyAndNumberOfColors() (float64, int) {
+	var sum float64
+	var numberOfColors int
+	for _, count := range stats.Histogram {
+		if count != 0 {
+			numberOfColors++
+		}
+	}
+	for _, count := range stats.Histogram {
+		if count != 0 {
+			probability := 1 / float64(numberOfColors)
+			sum += probability * math.Log2(probability)
+		}
+	}
+	return sum * -1, numberOfColors
+}
+
+// Range returns how many grey levels there are between the min and the max.
+// [4,8] -> 4, 5, 6, 7, 8 (5)
+func (stats *Statistics) Range() int {
+	return stats.MaxColor - stats.MinColor + 1
+}
@@ -0,0 +1,75 @@
+package processing
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// halves is black on its left half and has columns of 100 and 200 on its
+// right half.
+func halves() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		img.SetGray(2, y, color.Gray{Y: 100})
+		img.SetGray(3, y, color.Gray{Y: 200})
+	}
+	return img
+}
+
+// rightHalf selects the grey half of halves.
+func rightHalf() *image.Alpha {
+	mask := image.NewAlpha(image.Rect(2, 0, 4, 2))
+	for i := range mask.Pix {
+		mask.Pix[i] = 255
+	}
+	return mask
+}
+
+func TestMaskedStatistics(t *testing.T) {
+	tests := []struct {
+		name       string
+		mask       *image.Alpha
+		size       int
+		brightness float64
+	}{
+		{"whole image", nil, 8, 75},
+		{"right half", rightHalf(), 4, 150},
+	}
+	for _, test := range tests {
+		stats := NewMaskedStatistics(halves(), test.mask, PAL)
+		if stats.Size != test.size || stats.Brightness != test.brightness {
+			t.Errorf("%v: size %v and brightness %v, want %v and %v", test.name, stats.Size, stats.Brightness, test.size, test.brightness)
+		}
+	}
+}
+
+func TestMaskedEqualization(t *testing.T) {
+	tests := []struct {
+		name string
+		mask *image.Alpha
+		want uint32 // Level 200 becomes
+	}{
+		{"whole image", nil, 191},        // 6 of 8 pixels below
+		{"right half", rightHalf(), 127}, // 2 of 4, the black ones don't count
+	}
+	for _, test := range tests {
+		equalized := Equalization(halves(), test.mask)
+		if r, _, _, _ := equalized.At(3, 0).RGBA(); r>>8 != test.want {
+			t.Errorf("%v: 200 became %v, want %v", test.name, r>>8, test.want)
+		}
+	}
+}
+
+func TestROIMask(t *testing.T) {
+	mask := ROIMask(rightHalf(), image.Rect(1, 0, 4, 2))
+	if mask.Bounds() != image.Rect(1, 0, 3, 2) {
+		t.Errorf("bounds %v, want %v", mask.Bounds(), image.Rect(1, 0, 3, 2))
+	}
+	if mask.AlphaAt(0, 0).A != 0 || mask.AlphaAt(1, 0).A != 255 {
+		t.Errorf("the mask wasn't moved with the region")
+	}
+	if ROIMask(nil, image.Rect(0, 0, 1, 1)) != nil {
+		t.Errorf("no mask gives a mask")
+	}
+}
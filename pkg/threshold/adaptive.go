@@ -0,0 +1,77 @@
+package threshold
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/vision-go/vision-go/pkg/convolution"
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// Local is how the neighbourhood of a pixel is averaged by Adaptive.
+type Local int
+
+const (
+	Mean Local = iota
+	Gaussian
+)
+
+var localNames = []string{"mean", "gaussian"}
+
+// Locals returns the names of the local averages.
+func Locals() []string {
+	return append([]string(nil), localNames...)
+}
+
+func ParseLocal(name string) (Local, error) {
+	for i, localName := range localNames {
+		if strings.EqualFold(name, localName) {
+			return Local(i), nil
+		}
+	}
+	return 0, fmt.Errorf("the local average must be one of %v", strings.Join(localNames, ", "))
+}
+
+func (local Local) String() string {
+	return localNames[local]
+}
+
+// ValidateAdaptive checks the parameters of Adaptive.
+func ValidateAdaptive(size int, c float64) error {
+	if size < 3 || size > 255 || size%2 == 0 {
+		return fmt.Errorf("the window must be an odd number from 3 to 255")
+	}
+	if c < -255 || c > 255 {
+		return fmt.Errorf("the constant must be in the range [-255, 255]")
+	}
+	return nil
+}
+
+// Adaptive paints white the pixels whose grey level is over the average of
+// the size x size window around them minus c, and black the others, which
+// copes with uneven lighting. The gaussian average weights the window with
+// the sigma OpenCV uses for the same size. The grey levels are taken with
+// the weights l.
+func Adaptive(img image.Image, local Local, size int, c float64, l processing.Luminance) (image.Image, error) {
+	if err := ValidateAdaptive(size, c); err != nil {
+		return nil, err
+	}
+	k, err := convolution.Box(size)
+	if local == Gaussian {
+		k, err = convolution.Gaussian(0.3*(float64(size-1)/2-1) + 0.8)
+	}
+	if err != nil {
+		return nil, err
+	}
+	grey := processing.LuminancePlane(img, l)
+	average := convolution.ConvolvePlane(grey, k, convolution.Replicate)
+	for i, value := range grey.Pix {
+		if value > average.Pix[i]-float32(c) {
+			grey.Pix[i] = 255
+		} else {
+			grey.Pix[i] = 0
+		}
+	}
+	return processing.GreyImage(grey), nil
+}
@@ -0,0 +1,180 @@
+// Package threshold binarizes images by their grey level, with global
+// thresholds computed from the grey histogram or local ones computed from the
+// neighbourhood of every pixel.
+package threshold
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strings"
+
+	"github.com/vision-go/vision-go/pkg/histogram"
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// Method is how a global threshold is chosen.
+type Method int
+
+const (
+	Manual Method = iota
+	Otsu
+	Triangle
+)
+
+var methodNames = []string{"manual", "otsu", "triangle"}
+
+// Methods returns the names of the methods.
+func Methods() []string {
+	return append([]string(nil), methodNames...)
+}
+
+func ParseMethod(name string) (Method, error) {
+	for i, methodName := range methodNames {
+		if strings.EqualFold(name, methodName) {
+			return Method(i), nil
+		}
+	}
+	return 0, fmt.Errorf("the method must be one of %v", strings.Join(methodNames, ", "))
+}
+
+func (method Method) String() string {
+	return methodNames[method]
+}
+
+// Compute returns the threshold chosen by method for hist, value for Manual.
+func Compute(method Method, hist histogram.Histogram, value int) int {
+	switch method {
+	case Otsu:
+		return OtsuThreshold(hist)
+	case Triangle:
+		return TriangleThreshold(hist)
+	}
+	return value
+}
+
+// OtsuThreshold returns the threshold that maximizes the variance between
+// the levels up to it and the ones over it.
+func OtsuThreshold(hist histogram.Histogram) int {
+	thresholds, _ := MultiOtsu(hist, 2)
+	return thresholds[0]
+}
+
+// MultiOtsu returns the classes-1 thresholds that maximize the variance
+// between the classes (2 to 5), a class going from the level after a
+// threshold to the next threshold. It is solved exactly by dynamic
+// programming over the levels.
+func MultiOtsu(hist histogram.Histogram, classes int) ([]int, error) {
+	if classes < 2 || classes > 5 {
+		return nil, fmt.Errorf("the number of classes must be from 2 to 5")
+	}
+	var count, sum [257]float64 // Of the levels before i
+	for i, n := range hist {
+		count[i+1] = count[i] + float64(n)
+		sum[i+1] = sum[i] + float64(i*n)
+	}
+	// score of the class of the levels from a to b, both included: its
+	// weight times its squared mean, up to constants.
+	score := func(a, b int) float64 {
+		n := count[b+1] - count[a]
+		if n == 0 {
+			return 0
+		}
+		s := sum[b+1] - sum[a]
+		return s * s / n
+	}
+	// best[c][b] is the best score of c+1 classes covering the levels 0 to
+	// b, the last one starting at start[c][b].
+	best := make([][256]float64, classes)
+	start := make([][256]int, classes)
+	for b := 0; b < 256; b++ {
+		best[0][b] = score(0, b)
+	}
+	for c := 1; c < classes; c++ {
+		for b := c; b < 256; b++ {
+			best[c][b] = math.Inf(-1)
+			for a := c; a <= b; a++ {
+				if value := best[c-1][a-1] + score(a, b); value > best[c][b] {
+					best[c][b], start[c][b] = value, a
+				}
+			}
+		}
+	}
+	thresholds := make([]int, classes-1)
+	b := 255
+	for c := classes - 1; c > 0; c-- {
+		thresholds[c-1] = start[c][b] - 1
+		b = start[c][b] - 1
+	}
+	return thresholds, nil
+}
+
+// TriangleThreshold draws a line from the peak of hist to the end of its
+// longest tail and returns the level farthest below the line, which suits
+// histograms with a single peak.
+func TriangleThreshold(hist histogram.Histogram) int {
+	peak, first, last := 0, -1, 0
+	for i, n := range hist {
+		if n > hist[peak] {
+			peak = i
+		}
+		if n != 0 {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return 0
+	}
+	end := last
+	if peak-first > last-peak {
+		end = first
+	}
+	step := 1
+	if end < peak {
+		step = -1
+	}
+	// Distance from (i, hist[i]) to the line, up to the norm of the line.
+	dx, dy := float64(end-peak), float64(hist[end]-hist[peak])
+	threshold, farthest := peak, 0.0
+	for i := peak; i != end; i += step {
+		distance := dy*float64(i-peak) - dx*float64(hist[i]-hist[peak])
+		if step < 0 {
+			distance = -distance
+		}
+		if distance > farthest {
+			threshold, farthest = i, distance
+		}
+	}
+	if step < 0 { // The dark side is the foreground
+		threshold--
+	}
+	return threshold
+}
+
+// Binarize paints white the pixels whose grey level, with the weights l, is
+// over t and black the others.
+func Binarize(img image.Image, t int, l processing.Luminance) image.Image {
+	return Levels(img, []int{t}, l)
+}
+
+// Levels paints the pixels of the n+1 classes separated by the n increasing
+// thresholds with n+1 grey levels evenly spread from black to white. The
+// grey levels of img are taken with the weights l.
+func Levels(img image.Image, thresholds []int, l processing.Luminance) image.Image {
+	var lut [256]float32
+	for level := range lut {
+		class := 0
+		for class < len(thresholds) && level > thresholds[class] {
+			class++
+		}
+		lut[level] = float32(255 * class / len(thresholds))
+	}
+	grey := processing.LuminancePlane(img, l)
+	for i, value := range grey.Pix {
+		grey.Pix[i] = lut[processing.Clamp(value)]
+	}
+	return processing.GreyImage(grey)
+}
@@ -0,0 +1,164 @@
+package threshold
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/vision-go/vision-go/pkg/histogram"
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// bimodal returns an image whose left half has grey levels from 40 to 60
+// and whose right half has levels from 180 to 220.
+func bimodal() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 20, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 20; x++ {
+			noise := (x*7 + y*13) % 21
+			if x < 10 {
+				img.SetGray(x, y, color.Gray{Y: uint8(40 + noise)})
+			} else {
+				img.SetGray(x, y, color.Gray{Y: uint8(180 + 2*noise)})
+			}
+		}
+	}
+	return img
+}
+
+func histogramOf(img *image.Gray) (hist histogram.Histogram) {
+	for _, level := range img.Pix {
+		hist[level]++
+	}
+	return hist
+}
+
+func TestOtsuBimodal(t *testing.T) {
+	img := bimodal()
+	threshold := OtsuThreshold(histogramOf(img))
+	if threshold < 60 || threshold >= 180 {
+		t.Fatalf("threshold %v, want it between the modes", threshold)
+	}
+	result := Binarize(img, threshold, processing.PAL)
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 20; x++ {
+			r, _, _, _ := result.At(x, y).RGBA()
+			if white := r != 0; white != (x >= 10) {
+				t.Errorf("pixel %v,%v is white %v", x, y, white)
+			}
+		}
+	}
+}
+
+// betweenVariance is the variance between the levels up to t and the ones
+// over it, what Otsu maximizes.
+func betweenVariance(hist histogram.Histogram, t int) float64 {
+	var n0, n1, s0, s1 float64
+	for i, n := range hist {
+		if i <= t {
+			n0, s0 = n0+float64(n), s0+float64(i*n)
+		} else {
+			n1, s1 = n1+float64(n), s1+float64(i*n)
+		}
+	}
+	if n0 == 0 || n1 == 0 {
+		return 0
+	}
+	d := s0/n0 - s1/n1
+	return n0 * n1 * d * d
+}
+
+func TestOtsuSearch(t *testing.T) {
+	var hist histogram.Histogram
+	for i := range hist {
+		hist[i] = i*i*31%97 + 50*int(math.Abs(float64(i-128))/16) // Overlapping, no gap
+	}
+	threshold, best := OtsuThreshold(hist), 0.0
+	for i := 0; i < 255; i++ {
+		best = math.Max(best, betweenVariance(hist, i))
+	}
+	if got := betweenVariance(hist, threshold); got < best*(1-1e-9) {
+		t.Errorf("threshold %v gives a variance of %v, the best is %v", threshold, got, best)
+	}
+}
+
+func TestMultiOtsu(t *testing.T) {
+	var hist histogram.Histogram
+	for _, mode := range []int{30, 120, 220} {
+		for i := mode - 5; i <= mode+5; i++ {
+			hist[i] = 10 - int(math.Abs(float64(i-mode)))
+		}
+	}
+	thresholds, err := MultiOtsu(hist, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(thresholds) != 2 || thresholds[0] < 35 || thresholds[0] >= 115 || thresholds[1] < 125 || thresholds[1] >= 215 {
+		t.Errorf("thresholds %v, want one in each gap", thresholds)
+	}
+	for _, classes := range []int{1, 6} {
+		if _, err := MultiOtsu(hist, classes); err == nil {
+			t.Errorf("%v classes: no error", classes)
+		}
+	}
+}
+
+func TestTriangle(t *testing.T) {
+	var hist, mirrored histogram.Histogram
+	hist[50] = 1000
+	for i := 51; i < 256; i++ {
+		hist[i] = (255 - i) * (255 - i) / 50 // Under the line from the peak
+	}
+	for i := range hist {
+		mirrored[255-i] = hist[i]
+	}
+	threshold := TriangleThreshold(hist)
+	if threshold <= 50 || threshold >= 255 {
+		t.Errorf("threshold %v, want it in the tail", threshold)
+	}
+	if got := TriangleThreshold(mirrored); got != 254-threshold {
+		t.Errorf("the mirrored histogram gives %v, want %v", got, 254-threshold)
+	}
+}
+
+func TestLevels(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 6, 1))
+	copy(img.Pix, []uint8{0, 85, 86, 170, 171, 255})
+	result := Levels(img, []int{85, 170}, processing.PAL)
+	for x, want := range []uint8{0, 0, 127, 127, 255, 255} {
+		if got := color.GrayModel.Convert(result.At(x, 0)).(color.Gray).Y; got != want {
+			t.Errorf("level %v is %v, want %v", img.Pix[x], got, want)
+		}
+	}
+}
+
+// TestAdaptive finds dark dots over a background whose lighting goes from
+// dark to bright, which no global threshold separates.
+func TestAdaptive(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 40, 9))
+	dot := func(x, y int) bool { return y == 4 && x%8 == 4 }
+	for y := 0; y < 9; y++ {
+		for x := 0; x < 40; x++ {
+			level := 60 + 5*x
+			if dot(x, y) {
+				level -= 50
+			}
+			img.SetGray(x, y, color.Gray{Y: uint8(level)})
+		}
+	}
+	for _, local := range []Local{Mean, Gaussian} {
+		result, err := Adaptive(img, local, 7, 10, processing.PAL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for y := 0; y < 9; y++ {
+			for x := 0; x < 40; x++ {
+				r, _, _, _ := result.At(x, y).RGBA()
+				if black := r == 0; black != dot(x, y) {
+					t.Errorf("%v: pixel %v,%v is black %v", local, x, y, black)
+				}
+			}
+		}
+	}
+}
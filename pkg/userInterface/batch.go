@@ -0,0 +1,187 @@
+package userinterface
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/vision-go/vision-go/pkg/batch"
+	"github.com/vision-go/vision-go/pkg/pipeline"
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+const sameFormat = "Same as input"
+
+func (ui *UI) folderSelector(label *widget.Label) *widget.Button {
+	return widget.NewButton("Select...", func() {
+		dialog.ShowFolderOpen(func(folder fyne.ListableURI, err error) {
+			if err != nil {
+				dialog.ShowError(err, ui.MainWindow)
+				return
+			}
+			if folder == nil {
+				return
+			}
+			label.SetText(folder.Path())
+		}, ui.MainWindow)
+	})
+}
+
+func (ui *UI) batchDialog() {
+	inputLabel, outputLabel := widget.NewLabel(""), widget.NewLabel("")
+	operations := widget.NewMultiLineEntry()
+	operations.SetPlaceHolder("equalize\ngamma value=0.5")
+	pattern := widget.NewEntry()
+	pattern.SetText(batch.DefaultPattern)
+	format := widget.NewSelect(append([]string{sameFormat}, processing.SaveFormats...), nil)
+	format.SetSelected(sameFormat)
+	overwrite := widget.NewCheck("Replace existing files", nil)
+	form := []*widget.FormItem{
+		widget.NewFormItem("Input folder", container.NewBorder(nil, nil, nil, ui.folderSelector(inputLabel), inputLabel)),
+		widget.NewFormItem("Output folder", container.NewBorder(nil, nil, nil, ui.folderSelector(outputLabel), outputLabel)),
+		widget.NewFormItem("Operations (one per line)", operations),
+		widget.NewFormItem("Name", pattern),
+		widget.NewFormItem("Format", format),
+		widget.NewFormItem("", overwrite),
+	}
+	dialog.ShowForm("Batch processing", "Run", "Cancel", form,
+		func(choice bool) {
+			if !choice {
+				return
+			}
+			if inputLabel.Text == "" || outputLabel.Text == "" {
+				dialog.ShowError(fmt.Errorf("select the input and the output folders"), ui.MainWindow)
+				return
+			}
+			var steps []pipeline.Step
+			for _, line := range strings.Split(operations.Text, "\n") {
+				if strings.TrimSpace(line) == "" {
+					continue
+				}
+				step, err := pipeline.ParseStep(line)
+				if err != nil {
+					dialog.ShowError(err, ui.MainWindow)
+					return
+				}
+				steps = append(steps, step)
+			}
+			options := batch.Options{
+				InputDir:  inputLabel.Text,
+				OutputDir: outputLabel.Text,
+				Pattern:   pattern.Text,
+				Overwrite: overwrite.Checked,
+				Pipeline:  pipeline.Pipeline{Steps: steps},
+				Progress: func(done, total int, file string) {
+					ui.label.SetText(fmt.Sprintf("Batch: %v/%v", done, total))
+				},
+			}
+			if format.Selected != sameFormat {
+				options.Format = format.Selected
+			}
+			if _, err := options.Pipeline.Build(); err != nil { // Don't start a batch that can't work
+				dialog.ShowError(err, ui.MainWindow)
+				return
+			}
+			ui.runBatch(options)
+		},
+		ui.MainWindow)
+}
+
+func (ui *UI) runBatch(options batch.Options) {
+	ui.progessBar.Start()
+	ui.progessBar.Show()
+	go func() {
+		report, err := batch.Run(options)
+		ui.progessBar.Hide()
+		ui.progessBar.Stop()
+		ui.label.SetText("")
+		if err != nil {
+			dialog.ShowError(err, ui.MainWindow)
+			return
+		}
+		dialog.ShowInformation("Batch finished", report.String(), ui.MainWindow)
+	}()
+}
+
+func (ui *UI) runPipelineDialog() {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	dialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, ui.MainWindow)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		defer reader.Close()
+		p, err := pipeline.Load(reader.URI().Path())
+		if err != nil {
+			dialog.ShowError(err, ui.MainWindow)
+			return
+		}
+		img, err := currentImage.RunPipeline(p)
+		if err != nil {
+			dialog.ShowError(err, ui.MainWindow)
+			return
+		}
+		ui.showResult(currentImage, img)
+		if p.Save != nil {
+			options, _ := p.Save.Options() // Checked by Load
+			ui.saveDialog(img, p.Save.Format, options)
+		}
+	}, ui.MainWindow)
+	dialog.SetFilter(storage.NewExtensionFileFilter([]string{".yaml", ".yml", ".json"}))
+	dialog.Show()
+}
+
+func (ui *UI) exportPipelineDialog() {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	p, err := currentImage.Provenance().Pipeline()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	if len(p.Steps) == 0 {
+		dialog.ShowError(fmt.Errorf("no operations have been applied to this image"), ui.MainWindow)
+		return
+	}
+	dialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, ui.MainWindow)
+			return
+		}
+		if writer == nil {
+			return
+		}
+		defer writer.Close()
+		format := "yaml"
+		if strings.ToLower(writer.URI().Extension()) == ".json" {
+			format = "json"
+		}
+		data, err := p.Marshal(format)
+		if err != nil {
+			dialog.ShowError(err, ui.MainWindow)
+			return
+		}
+		if _, err := writer.Write(data); err != nil {
+			dialog.ShowError(err, ui.MainWindow)
+		}
+	}, ui.MainWindow)
+	dialog.SetFileName(strings.TrimSuffix(currentImage.Name(), filepath.Ext(currentImage.Name())) + ".yaml")
+	dialog.SetFilter(storage.NewExtensionFileFilter([]string{".yaml", ".yml", ".json"}))
+	dialog.Show()
+}
@@ -0,0 +1,61 @@
+package userinterface
+
+import (
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/vision-go/vision-go/pkg/clahe"
+	ourimage "github.com/vision-go/vision-go/pkg/ourImage"
+)
+
+func (ui *UI) claheOp() {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	originalPreview, previewImg := newPreview(currentImage)
+	mode := clahe.Lab
+	columnsValue, rowsValue, clipValue := binding.NewFloat(), binding.NewFloat(), binding.NewFloat()
+	columnsSlider, rowsSlider, clipSlider :=
+		widget.NewSliderWithData(1, 32, columnsValue),
+		widget.NewSliderWithData(1, 32, rowsValue),
+		widget.NewSliderWithData(1, 20, clipValue)
+	clipSlider.Step = 0.5
+	update := func() {
+		columns, _ := columnsValue.Get()
+		rows, _ := rowsValue.Get()
+		clip, _ := clipValue.Get()
+		preview, err := clahe.Equalize(originalPreview, int(columns), int(rows), clip, mode)
+		if err != nil { // A grid finer than the preview
+			return
+		}
+		previewImg.Image = preview
+		previewImg.Refresh()
+	}
+	columnsSlider.SetValue(8)
+	rowsSlider.SetValue(8)
+	clipSlider.SetValue(2)
+	modeSelect := widget.NewSelect(clahe.Modes(), func(name string) {
+		mode, _ = clahe.ParseMode(name) // The options are the names of the modes
+		update()
+	})
+	modeSelect.SetSelected(mode.String()) // Draws the first preview
+	for _, value := range []binding.Float{columnsValue, rowsValue, clipValue} {
+		value.AddListener(binding.NewDataListener(update))
+	}
+	controls := container.NewVBox(
+		container.NewCenter(widget.NewLabel("Channels")), modeSelect,
+		container.NewCenter(widget.NewLabelWithData(binding.FloatToStringWithFormat(columnsValue, "Columns of tiles: %.0f"))), columnsSlider,
+		container.NewCenter(widget.NewLabelWithData(binding.FloatToStringWithFormat(rowsValue, "Rows of tiles: %.0f"))), rowsSlider,
+		container.NewCenter(widget.NewLabelWithData(binding.FloatToStringWithFormat(clipValue, "Clip limit: %.1f"))), clipSlider,
+	)
+	ui.previewDialog("CLAHE", currentImage, controls, previewImg, func() (*ourimage.OurImage, error) {
+		columns, _ := columnsValue.Get()
+		rows, _ := rowsValue.Get()
+		clip, _ := clipValue.Get()
+		return currentImage.CLAHE(int(columns), int(rows), clip, mode)
+	})
+}
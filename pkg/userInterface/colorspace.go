@@ -0,0 +1,159 @@
+package userinterface
+
+import (
+	"fmt"
+	"image"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/vision-go/vision-go/pkg/colorspace"
+	ourimage "github.com/vision-go/vision-go/pkg/ourImage"
+)
+
+// everyChannel is the pointChannel that applies the point operations to the
+// whole image.
+const everyChannel = -1
+
+func (ui *UI) colorSpaceMenuItem() *fyne.MenuItem {
+	ui.pointChannel = everyChannel
+	item := fyne.NewMenuItem("Colour Space", nil)
+	item.ChildMenu = fyne.NewMenu("",
+		fyne.NewMenuItem("Split Channels...", ui.splitChannelsOp),
+		fyne.NewMenuItem("Merge Channels...", ui.mergeChannelsOp),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Point Operations Channel...", ui.pointChannelDialog),
+	)
+	return item
+}
+
+// splitChannelsOp opens a tab for every channel of the chosen space.
+func (ui *UI) splitChannelsOp() {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	spaceSelect := widget.NewSelect(colorspace.Spaces(), nil)
+	spaceSelect.SetSelected(colorspace.HSV.String())
+	form := []*widget.FormItem{widget.NewFormItem("Colour space", spaceSelect)}
+	dialog.ShowForm("Split channels", "Ok", "Cancel", form,
+		func(choice bool) {
+			if !choice {
+				return
+			}
+			space, _ := colorspace.ParseSpace(spaceSelect.Selected) // The options are the names of the spaces
+			for _, channel := range currentImage.Split(space) {
+				ui.newImage(channel)
+			}
+		},
+		ui.MainWindow)
+}
+
+// mergeChannelsOp joins tabs of the same size as the channels of a space,
+// the first one being the current tab.
+func (ui *UI) mergeChannelsOp() {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	names := make([]string, len(ui.tabsElements))
+	for i, img := range ui.tabsElements {
+		names[i] = fmt.Sprintf("%v. %v", i+1, img.Name()) // Tabs may share the name
+	}
+	var channelSelects []*widget.Select
+	channels := container.NewVBox()
+	spaceSelect := widget.NewSelect(colorspace.Spaces(), func(name string) {
+		space, _ := colorspace.ParseSpace(name) // The options are the names of the spaces
+		channelSelects = channelSelects[:0]
+		channels.Objects = nil
+		for i, channel := range space.Channels() {
+			channelSelect := widget.NewSelect(names, nil)
+			channelSelect.SetSelected(names[(ui.tabs.SelectedIndex()+i)%len(names)])
+			channelSelects = append(channelSelects, channelSelect)
+			channels.Add(container.NewBorder(nil, nil, widget.NewLabel(channel), nil, channelSelect))
+		}
+	})
+	spaceSelect.SetSelected(colorspace.HSV.String())
+	content := container.NewVBox(widget.NewLabel("Colour space"), spaceSelect, channels)
+	dialog.ShowCustomConfirm("Merge channels", "Ok", "Cancel", content,
+		func(choice bool) {
+			if !choice {
+				return
+			}
+			space, _ := colorspace.ParseSpace(spaceSelect.Selected)
+			var selected []*ourimage.OurImage
+			for _, channelSelect := range channelSelects {
+				selected = append(selected, ui.tabsElements[channelSelect.SelectedIndex()])
+			}
+			result, err := selected[0].Merge(space, selected[1:]...)
+			if err != nil {
+				dialog.ShowError(err, ui.MainWindow)
+				return
+			}
+			ui.showResult(currentImage, result)
+		},
+		ui.MainWindow)
+}
+
+// pointChannelDialog chooses the channel the point operations (gamma,
+// brightness and contrast and linear transformation) change.
+func (ui *UI) pointChannelDialog() {
+	const every = "every channel"
+	channelSelect := widget.NewSelect(nil, nil)
+	spaceSelect := widget.NewSelect(colorspace.Spaces(), func(name string) {
+		space, _ := colorspace.ParseSpace(name) // The options are the names of the spaces
+		channelSelect.Options = append([]string{every}, space.Channels()...)
+		channelSelect.SetSelected(every)
+	})
+	spaceSelect.SetSelected(ui.pointSpace.String())
+	if ui.pointChannel != everyChannel {
+		channelSelect.SetSelected(ui.pointSpace.Channels()[ui.pointChannel])
+	}
+	form := []*widget.FormItem{
+		widget.NewFormItem("Colour space", spaceSelect),
+		widget.NewFormItem("Channel", channelSelect),
+	}
+	dialog.ShowForm("Point operations channel", "Ok", "Cancel", form,
+		func(choice bool) {
+			if !choice {
+				return
+			}
+			ui.pointSpace, _ = colorspace.ParseSpace(spaceSelect.Selected)
+			ui.pointChannel = channelSelect.SelectedIndex() - 1 // every is everyChannel
+		},
+		ui.MainWindow)
+}
+
+// showPointResult shows the result of a point operation, applied to the
+// channel of pointChannelDialog.
+func (ui *UI) showPointResult(currentImage *ourimage.OurImage, op func(*ourimage.OurImage) *ourimage.OurImage) {
+	result, err := ui.pointResult(currentImage, op)
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	ui.showResult(currentImage, result)
+}
+
+// pointResult is the image showPointResult shows.
+func (ui *UI) pointResult(currentImage *ourimage.OurImage, op func(*ourimage.OurImage) *ourimage.OurImage) (*ourimage.OurImage, error) {
+	if ui.pointChannel == everyChannel {
+		return op(currentImage), nil
+	}
+	return currentImage.OnChannel(ui.pointSpace, ui.pointChannel, op)
+}
+
+// pointPreview is the preview of a point operation, see showPointResult.
+func (ui *UI) pointPreview(img image.Image, op func(image.Image) image.Image) image.Image {
+	if ui.pointChannel == everyChannel {
+		return op(img)
+	}
+	result, _ := colorspace.OnChannel(img, ui.pointSpace, ui.pointChannel, func(channel image.Image) (image.Image, error) {
+		return op(channel), nil
+	}) // Point operations keep the size
+	return result
+}
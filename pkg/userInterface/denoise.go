@@ -0,0 +1,114 @@
+package userinterface
+
+import (
+	"image"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/vision-go/vision-go/pkg/denoise"
+	ourimage "github.com/vision-go/vision-go/pkg/ourImage"
+)
+
+// denoiseParam is a slider of a denoising dialog. The odd window sizes are
+// chosen by their radius, size = 2*radius+1.
+type denoiseParam struct {
+	format         string
+	min, max, step float64
+	initial        float64
+}
+
+func (ui *UI) denoiseMenuItem() *fyne.MenuItem {
+	denoiseItem := fyne.NewMenuItem("Denoise", nil)
+	denoiseItem.ChildMenu = fyne.NewMenu("",
+		fyne.NewMenuItem("Median...", ui.medianOp),
+		fyne.NewMenuItem("Bilateral...", ui.bilateralOp),
+		fyne.NewMenuItem("Non-local means...", ui.nonLocalMeansOp),
+	)
+	return denoiseItem
+}
+
+func (ui *UI) medianOp() {
+	ui.denoiseDialog("Median", []denoiseParam{{"Radius: %.0f", 1, 15, 1, 1}},
+		func(img image.Image, values []float64, mode denoise.Mode) (image.Image, error) {
+			return denoise.Median(img, 2*int(values[0])+1, mode)
+		},
+		func(img *ourimage.OurImage, values []float64, mode denoise.Mode) (*ourimage.OurImage, error) {
+			return img.Median(2*int(values[0])+1, mode)
+		})
+}
+
+func (ui *UI) bilateralOp() {
+	ui.denoiseDialog("Bilateral", []denoiseParam{{"Spatial sigma: %.1f", 0.5, 20, 0.5, 3}, {"Range sigma: %.0f", 1, 255, 1, 25}},
+		func(img image.Image, values []float64, mode denoise.Mode) (image.Image, error) {
+			return denoise.Bilateral(img, values[0], values[1], mode)
+		},
+		func(img *ourimage.OurImage, values []float64, mode denoise.Mode) (*ourimage.OurImage, error) {
+			return img.Bilateral(values[0], values[1], mode)
+		})
+}
+
+func (ui *UI) nonLocalMeansOp() {
+	ui.denoiseDialog("Non-local means",
+		[]denoiseParam{{"Strength: %.0f", 1, 100, 1, 10}, {"Patch radius: %.0f", 0, 7, 1, 2}, {"Search radius: %.0f", 1, 20, 1, 5}},
+		func(img image.Image, values []float64, mode denoise.Mode) (image.Image, error) {
+			return denoise.NonLocalMeans(img, values[0], 2*int(values[1])+1, 2*int(values[2])+1, mode)
+		},
+		func(img *ourimage.OurImage, values []float64, mode denoise.Mode) (*ourimage.OurImage, error) {
+			return img.NonLocalMeans(values[0], 2*int(values[1])+1, 2*int(values[2])+1, mode)
+		})
+}
+
+// denoiseDialog shows the sliders of params and the channels to filter
+// with a preview of a rescaled copy of the current image, running apply on
+// it when accepted.
+func (ui *UI) denoiseDialog(title string, params []denoiseParam,
+	preview func(image.Image, []float64, denoise.Mode) (image.Image, error),
+	apply func(*ourimage.OurImage, []float64, denoise.Mode) (*ourimage.OurImage, error)) {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	originalPreview, previewImg := newDetailPreview(currentImage) // The sizes and sigmas are in pixels of the image
+	mode := denoise.RGB
+	bindings := make([]binding.Float, len(params))
+	values := func() []float64 {
+		result := make([]float64, len(bindings))
+		for i, value := range bindings {
+			result[i], _ = value.Get()
+		}
+		return result
+	}
+	update := func() {
+		NewImage, err := preview(originalPreview, values(), mode)
+		if err != nil {
+			return
+		}
+		previewImg.Image = NewImage
+		previewImg.Refresh()
+	}
+	modeSelect := widget.NewSelect(denoise.Modes(), func(name string) {
+		mode, _ = denoise.ParseMode(name) // The options are the names of the modes
+		update()
+	})
+	controls := container.NewVBox(container.NewCenter(widget.NewLabel("Channels")), modeSelect)
+	for i, param := range params {
+		bindings[i] = binding.NewFloat()
+		slider := widget.NewSliderWithData(param.min, param.max, bindings[i])
+		slider.Step = param.step
+		slider.SetValue(param.initial)
+		controls.Add(container.NewCenter(widget.NewLabelWithData(binding.FloatToStringWithFormat(bindings[i], param.format))))
+		controls.Add(slider)
+	}
+	modeSelect.SetSelected(mode.String()) // Draws the first preview
+	for _, value := range bindings {
+		value.AddListener(binding.NewDataListener(update))
+	}
+	ui.previewDialog(title, currentImage, controls, previewImg, func() (*ourimage.OurImage, error) {
+		return apply(currentImage, values(), mode)
+	})
+}
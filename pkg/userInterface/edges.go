@@ -0,0 +1,90 @@
+package userinterface
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/vision-go/vision-go/pkg/edges"
+	ourimage "github.com/vision-go/vision-go/pkg/ourImage"
+)
+
+func (ui *UI) edgesMenuItem() *fyne.MenuItem {
+	var items []*fyne.MenuItem
+	for i, name := range edges.Operators() {
+		op := edges.Operator(i)
+		item := fyne.NewMenuItem(strings.Title(name), nil)
+		item.ChildMenu = fyne.NewMenu("",
+			fyne.NewMenuItem("Magnitude", ui.gradientOp(op, false)),
+			fyne.NewMenuItem("Direction", ui.gradientOp(op, true)),
+		)
+		items = append(items, item)
+	}
+	items = append(items, fyne.NewMenuItem("Canny", ui.cannyOp))
+	edgesItem := fyne.NewMenuItem("Edges", nil)
+	edgesItem.ChildMenu = fyne.NewMenu("", items...)
+	return edgesItem
+}
+
+func (ui *UI) gradientOp(op edges.Operator, direction bool) func() {
+	return func() {
+		currentImage, err := ui.getCurrentImage()
+		if err != nil {
+			dialog.ShowError(err, ui.MainWindow)
+			return
+		}
+		if direction {
+			ui.showResult(currentImage, currentImage.GradientDirection(op))
+			return
+		}
+		ui.showResult(currentImage, currentImage.GradientMagnitude(op))
+	}
+}
+
+func (ui *UI) cannyOp() {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	originalPreview, previewImg := newDetailPreview(currentImage) // Sigma is in pixels of the image
+	sigmaValue, lowValue, highValue := binding.NewFloat(), binding.NewFloat(), binding.NewFloat()
+	sigmaSlider, lowSlider, highSlider :=
+		widget.NewSliderWithData(0, 5, sigmaValue),
+		widget.NewSliderWithData(0, 255, lowValue),
+		widget.NewSliderWithData(0, 255, highValue)
+	sigmaSlider.Step = 0.1
+	update := func() {
+		sigma, _ := sigmaValue.Get()
+		low, _ := lowValue.Get()
+		high, _ := highValue.Get()
+		preview, err := ourimage.CannyPreview(originalPreview, sigma, low, high, currentImage.Luminance())
+		if err != nil { // The low threshold above the high one
+			return
+		}
+		previewImg.Image = preview
+		previewImg.Refresh()
+	}
+	sigmaSlider.SetValue(1.4)
+	lowSlider.SetValue(20)
+	highSlider.SetValue(50)
+	update()
+	for _, value := range []binding.Float{sigmaValue, lowValue, highValue} {
+		value.AddListener(binding.NewDataListener(update))
+	}
+	controls := container.NewGridWithRows(6,
+		container.NewCenter(widget.NewLabelWithData(binding.FloatToStringWithFormat(sigmaValue, "Sigma: %.1f"))), sigmaSlider,
+		container.NewCenter(widget.NewLabelWithData(binding.FloatToStringWithFormat(lowValue, "Low threshold: %.0f"))), lowSlider,
+		container.NewCenter(widget.NewLabelWithData(binding.FloatToStringWithFormat(highValue, "High threshold: %.0f"))), highSlider,
+	)
+	ui.previewDialog("Canny", currentImage, controls, previewImg, func() (*ourimage.OurImage, error) {
+		sigma, _ := sigmaValue.Get()
+		low, _ := lowValue.Get()
+		high, _ := highValue.Get()
+		return currentImage.Canny(sigma, low, high)
+	})
+}
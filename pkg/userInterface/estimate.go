@@ -0,0 +1,67 @@
+package userinterface
+
+import (
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/dustin/go-humanize"
+
+	ourimage "github.com/vision-go/vision-go/pkg/ourImage"
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// estimateDelay is how long the settings of the save dialog have to stay
+// the same before the size of the file is estimated.
+const estimateDelay = 300 * time.Millisecond
+
+// sizeEstimate shows in label the size of img saved with the last settings
+// given to update. Encoding large images takes a while, so it's done in the
+// background once the settings stop changing, which moving a slider doesn't.
+type sizeEstimate struct {
+	img   *ourimage.OurImage
+	label *widget.Label
+	mutex sync.Mutex
+	timer *time.Timer
+	last  int // Count of updates, so the estimates of old settings aren't shown
+}
+
+func newSizeEstimate(img *ourimage.OurImage) *sizeEstimate {
+	return &sizeEstimate{img: img, label: widget.NewLabel("")}
+}
+
+func (e *sizeEstimate) update(format string, options processing.SaveOptions) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.stopLocked()
+	current := e.last
+	e.label.SetText("Estimating size...")
+	e.timer = time.AfterFunc(estimateDelay, func() {
+		size, err := e.img.EstimateSize(format, options)
+		e.mutex.Lock()
+		defer e.mutex.Unlock()
+		if current != e.last { // The settings changed while encoding
+			return
+		}
+		if err != nil {
+			e.label.SetText(err.Error())
+			return
+		}
+		e.label.SetText("Estimated size: " + humanize.Bytes(uint64(size)))
+	})
+}
+
+// stop drops the pending estimate, when the dialog is closed.
+func (e *sizeEstimate) stop() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.stopLocked()
+}
+
+func (e *sizeEstimate) stopLocked() {
+	e.last++
+	if e.timer != nil {
+		e.timer.Stop()
+	}
+}
@@ -0,0 +1,185 @@
+package userinterface
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/vision-go/vision-go/pkg/convolution"
+	ourimage "github.com/vision-go/vision-go/pkg/ourImage"
+)
+
+func (ui *UI) filtersMenu() *fyne.Menu {
+	names := convolution.Borders()
+	ui.borderItems = make([]*fyne.MenuItem, len(names))
+	for i, name := range names {
+		border := convolution.Border(i)
+		ui.borderItems[i] = fyne.NewMenuItem(strings.Title(name), func() { ui.setBorder(border) })
+	}
+	ui.border = convolution.Replicate
+	ui.borderItems[ui.border].Checked = true
+	borders := fyne.NewMenuItem("Border", nil)
+	borders.ChildMenu = fyne.NewMenu("", ui.borderItems...)
+	return fyne.NewMenu("Filters",
+		fyne.NewMenuItem("Box Blur", ui.boxBlurOp),
+		fyne.NewMenuItem("Gaussian Blur", ui.gaussianBlurOp),
+		fyne.NewMenuItem("Sharpen", ui.filterOp((*ourimage.OurImage).Sharpen)),
+		fyne.NewMenuItem("Emboss", ui.filterOp((*ourimage.OurImage).Emboss)),
+		fyne.NewMenuItem("Laplacian", ui.filterOp((*ourimage.OurImage).Laplacian)),
+		fyne.NewMenuItem("Custom Kernel", ui.customKernelOp),
+		fyne.NewMenuItemSeparator(),
+		ui.edgesMenuItem(),
+		ui.denoiseMenuItem(),
+		fyne.NewMenuItemSeparator(),
+		borders,
+	)
+}
+
+// setBorder chooses how every filter treats the pixels outside of the image.
+func (ui *UI) setBorder(border convolution.Border) {
+	ui.border = border
+	for i, item := range ui.borderItems {
+		item.Checked = convolution.Border(i) == border
+	}
+	ui.MainWindow.SetMainMenu(ui.menu)
+}
+
+func (ui *UI) filterOp(filter func(*ourimage.OurImage, convolution.Border) *ourimage.OurImage) func() {
+	return func() {
+		currentImage, err := ui.getCurrentImage()
+		if err != nil {
+			dialog.ShowError(err, ui.MainWindow)
+			return
+		}
+		ui.showResult(currentImage, filter(currentImage, ui.border))
+	}
+}
+
+func (ui *UI) boxBlurOp() {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	entry := widget.NewEntry()
+	entry.SetText("3")
+	entry.Validator = func(value string) error {
+		valueInt, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		if valueInt < 1 || valueInt%2 == 0 {
+			return fmt.Errorf("the size of the box must be a positive odd number")
+		}
+		return nil
+	}
+	form := []*widget.FormItem{widget.NewFormItem("Size", entry)}
+	dialog.ShowForm("Box blur", "Ok", "Cancel", form,
+		func(choice bool) {
+			if !choice {
+				return
+			}
+			size, _ := strconv.Atoi(entry.Text) // No need to check thanks to validator
+			result, err := currentImage.BoxBlur(size, ui.border)
+			if err != nil {
+				dialog.ShowError(err, ui.MainWindow)
+				return
+			}
+			ui.showResult(currentImage, result)
+		},
+		ui.MainWindow)
+}
+
+func (ui *UI) gaussianBlurOp() {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	entry := widget.NewEntry()
+	entry.SetText("1")
+	entry.Validator = func(value string) error {
+		valueFloat, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		if valueFloat <= 0 || valueFloat > 50 {
+			return fmt.Errorf("sigma must be in the range (0, 50]")
+		}
+		return nil
+	}
+	form := []*widget.FormItem{widget.NewFormItem("Sigma", entry)}
+	dialog.ShowForm("Gaussian blur", "Ok", "Cancel", form,
+		func(choice bool) {
+			if !choice {
+				return
+			}
+			sigma, _ := strconv.ParseFloat(entry.Text, 64) // No need to check thanks to validator
+			result, err := currentImage.GaussianBlur(sigma, ui.border)
+			if err != nil {
+				dialog.ShowError(err, ui.MainWindow)
+				return
+			}
+			ui.showResult(currentImage, result)
+		},
+		ui.MainWindow)
+}
+
+func (ui *UI) customKernelOp() {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	kernelEntry := widget.NewMultiLineEntry()
+	kernelEntry.SetText("1 2 1\n2 4 2\n1 2 1")
+	kernelEntry.Validator = func(value string) error {
+		_, err := convolution.Parse(value)
+		return err
+	}
+	divisorEntry := widget.NewEntry()
+	divisorEntry.SetPlaceHolder("Sum of the weights")
+	divisorEntry.Validator = func(value string) error {
+		if value == "" {
+			return nil
+		}
+		valueFloat, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		if valueFloat == 0 {
+			return fmt.Errorf("the divisor can't be 0")
+		}
+		return nil
+	}
+	offsetEntry := widget.NewEntry()
+	offsetEntry.SetText("0")
+	offsetEntry.Validator = func(value string) error {
+		_, err := strconv.ParseFloat(value, 64)
+		return err
+	}
+	form := []*widget.FormItem{
+		widget.NewFormItem("Kernel", kernelEntry),
+		widget.NewFormItem("Divisor", divisorEntry),
+		widget.NewFormItem("Offset", offsetEntry),
+	}
+	dialog.ShowForm("Custom kernel", "Ok", "Cancel", form,
+		func(choice bool) {
+			if !choice {
+				return
+			}
+			k, _ := convolution.Parse(kernelEntry.Text) // No need to check thanks to validator
+			if divisor, err := strconv.ParseFloat(divisorEntry.Text, 64); err == nil {
+				k = k.Scale(1 / divisor)
+			} else {
+				k = k.Normalize()
+			}
+			k.Offset, _ = strconv.ParseFloat(offsetEntry.Text, 64)
+			ui.showResult(currentImage, currentImage.Convolve(k, ui.border))
+		},
+		ui.MainWindow)
+}
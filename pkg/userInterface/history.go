@@ -0,0 +1,170 @@
+package userinterface
+
+import (
+	"fmt"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+
+	ourimage "github.com/vision-go/vision-go/pkg/ourImage"
+)
+
+// showResult opens result in a new tab or, when editing in place, replaces
+// the content of the tab of original keeping it in its history.
+func (ui *UI) showResult(original, result *ourimage.OurImage) {
+	if !ui.inPlace {
+		ui.newImage(result)
+		return
+	}
+	if err := original.SetHistoryLimit(ui.historyLimit); err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	original.Commit(result)
+	ui.refreshTab(original)
+}
+
+// refreshTab updates the title and the history panel after img changed.
+func (ui *UI) refreshTab(img *ourimage.OurImage) {
+	for i, element := range ui.tabsElements {
+		if element == img {
+			ui.tabs.Items[i].Text = img.Name()
+			ui.tabs.Refresh()
+		}
+	}
+	ui.refreshHistory()
+}
+
+func (ui *UI) initHistory() {
+	ui.historyLimit = ourimage.DefaultHistoryLimit
+	ui.historyList = widget.NewList(
+		func() int {
+			img, err := ui.getCurrentImage()
+			if err != nil {
+				return 0
+			}
+			steps, _ := img.History()
+			return len(steps)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Brightness/Contrast")
+		},
+		func(id widget.ListItemID, object fyne.CanvasObject) {
+			img, err := ui.getCurrentImage()
+			if err != nil {
+				return
+			}
+			steps, _ := img.History()
+			if id < len(steps) {
+				object.(*widget.Label).SetText(strconv.Itoa(id+1) + ". " + steps[id])
+			}
+		},
+	)
+	ui.historyList.OnSelected = func(id widget.ListItemID) {
+		img, err := ui.getCurrentImage()
+		if err != nil {
+			return
+		}
+		if _, current := img.History(); current == id {
+			return
+		}
+		if err := img.GoToHistory(id); err != nil {
+			dialog.ShowError(err, ui.MainWindow)
+			return
+		}
+		ui.refreshTab(img)
+	}
+	ui.historyPanel = container.NewBorder(widget.NewLabelWithStyle("History", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}), nil, nil, nil, ui.historyList)
+	ui.historyPanel.Hide()
+
+	ui.MainWindow.Canvas().AddShortcut(&desktop.CustomShortcut{KeyName: fyne.KeyZ, Modifier: desktop.ControlModifier},
+		func(fyne.Shortcut) { ui.undo() })
+	ui.MainWindow.Canvas().AddShortcut(&desktop.CustomShortcut{KeyName: fyne.KeyZ, Modifier: desktop.ControlModifier | desktop.ShiftModifier},
+		func(fyne.Shortcut) { ui.redo() })
+}
+
+func (ui *UI) refreshHistory() {
+	ui.historyList.UnselectAll()
+	ui.historyList.Refresh()
+	if img, err := ui.getCurrentImage(); err == nil {
+		_, current := img.History()
+		ui.historyList.Select(current)
+	}
+}
+
+func (ui *UI) undo() {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	if err := currentImage.Undo(); err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	ui.refreshTab(currentImage)
+}
+
+func (ui *UI) redo() {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	if err := currentImage.Redo(); err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	ui.refreshTab(currentImage)
+}
+
+func (ui *UI) toggleInPlace(item *fyne.MenuItem) func() {
+	return func() {
+		ui.inPlace = !ui.inPlace
+		item.Checked = ui.inPlace
+		ui.MainWindow.SetMainMenu(ui.menu)
+	}
+}
+
+func (ui *UI) toggleHistoryPanel() {
+	if ui.historyPanel.Visible() {
+		ui.historyPanel.Hide()
+		return
+	}
+	ui.refreshHistory()
+	ui.historyPanel.Show()
+}
+
+func (ui *UI) historyLimitDialog() {
+	entry := widget.NewEntry()
+	entry.SetText(strconv.Itoa(ui.historyLimit))
+	entry.Validator = func(value string) error {
+		valueInt, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		if valueInt < 2 {
+			return fmt.Errorf("the history must keep at least 2 snapshots")
+		}
+		return nil
+	}
+	dialog.ShowForm("History limit", "Ok", "Cancel",
+		[]*widget.FormItem{widget.NewFormItem("Snapshots per tab", entry)},
+		func(choice bool) {
+			if !choice {
+				return
+			}
+			ui.historyLimit, _ = strconv.Atoi(entry.Text) // No need to check thanks to validator
+			for _, img := range ui.tabsElements {
+				if img.CanUndo() || img.CanRedo() {
+					img.SetHistoryLimit(ui.historyLimit)
+				}
+			}
+			ui.refreshHistory()
+		},
+		ui.MainWindow)
+}
@@ -0,0 +1,81 @@
+package userinterface
+
+import (
+	"fmt"
+	"strconv"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// luminanceDialog chooses the weights of the grey levels of the current
+// image: its grey histogram, its brightness and contrast and the operations
+// computing grey levels, which pass them to their results.
+func (ui *UI) luminanceDialog() {
+	const custom = "custom"
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	current := currentImage.Luminance()
+	entries := []*widget.Entry{widget.NewEntry(), widget.NewEntry(), widget.NewEntry()}
+	for i, weight := range []float64{current.R, current.G, current.B} {
+		entries[i].SetText(strconv.FormatFloat(weight, 'g', 4, 64))
+		entries[i].Validator = func(value string) error {
+			weight, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return err
+			}
+			if weight < 0 {
+				return fmt.Errorf("the weights must not be below 0")
+			}
+			return nil
+		}
+	}
+	weightsSelect := widget.NewSelect(append(processing.Luminances(), custom), func(name string) {
+		for _, entry := range entries {
+			if name == custom {
+				entry.Enable()
+			} else {
+				entry.Disable()
+			}
+		}
+	})
+	selected := custom
+	for _, name := range processing.Luminances() {
+		if name == current.String() {
+			selected = name
+		}
+	}
+	weightsSelect.SetSelected(selected)
+	form := []*widget.FormItem{
+		widget.NewFormItem("Weights", weightsSelect),
+		widget.NewFormItem("Red", entries[0]),
+		widget.NewFormItem("Green", entries[1]),
+		widget.NewFormItem("Blue", entries[2]),
+	}
+	dialog.ShowForm("Luminance", "Ok", "Cancel", form,
+		func(choice bool) {
+			if !choice {
+				return
+			}
+			l, err := processing.ParseLuminance(weightsSelect.Selected)
+			if weightsSelect.Selected == custom {
+				var weights [3]float64
+				for i, entry := range entries {
+					weights[i], _ = strconv.ParseFloat(entry.Text, 64) // No need to check thanks to validator
+				}
+				l, err = processing.NewLuminance(weights[0], weights[1], weights[2])
+			}
+			if err != nil {
+				dialog.ShowError(err, ui.MainWindow)
+				return
+			}
+			currentImage.SetLuminance(l)
+			ui.selectionCallback(currentImage)
+		},
+		ui.MainWindow)
+}
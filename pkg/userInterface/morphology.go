@@ -0,0 +1,102 @@
+package userinterface
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/vision-go/vision-go/pkg/morphology"
+	ourimage "github.com/vision-go/vision-go/pkg/ourImage"
+)
+
+func (ui *UI) morphologyMenu() *fyne.Menu {
+	ui.element, _ = morphology.New(morphology.Square, 3)
+	ui.elementShape, ui.elementSize, ui.elementMatrix = morphology.Square.String(), 3, "0 1 0\n1 1 1\n0 1 0"
+	return fyne.NewMenu("Morphology",
+		fyne.NewMenuItem("Erosion", ui.morphologyOp((*ourimage.OurImage).Erode)),
+		fyne.NewMenuItem("Dilation", ui.morphologyOp((*ourimage.OurImage).Dilate)),
+		fyne.NewMenuItem("Opening", ui.morphologyOp((*ourimage.OurImage).Open)),
+		fyne.NewMenuItem("Closing", ui.morphologyOp((*ourimage.OurImage).Close)),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Gradient", ui.morphologyOp((*ourimage.OurImage).MorphologicalGradient)),
+		fyne.NewMenuItem("Top-hat", ui.morphologyOp((*ourimage.OurImage).TopHat)),
+		fyne.NewMenuItem("Black-hat", ui.morphologyOp((*ourimage.OurImage).BlackHat)),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Hit-or-miss", ui.morphologyOp((*ourimage.OurImage).HitOrMiss)),
+		fyne.NewMenuItem("Skeleton", ui.morphologyOp((*ourimage.OurImage).Skeleton)),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Structuring element...", ui.structuringElementDialog),
+	)
+}
+
+// morphologyOp applies op with the structuring element of the menu.
+func (ui *UI) morphologyOp(op func(*ourimage.OurImage, morphology.Element) *ourimage.OurImage) func() {
+	return func() {
+		currentImage, err := ui.getCurrentImage()
+		if err != nil {
+			dialog.ShowError(err, ui.MainWindow)
+			return
+		}
+		ui.showResult(currentImage, op(currentImage, ui.element))
+	}
+}
+
+// structuringElementDialog chooses the element of every morphology
+// operation: a shape with its size or a custom matrix, where 1 is
+// foreground, 0 background and x don't care.
+func (ui *UI) structuringElementDialog() {
+	const custom = "custom"
+	sizeEntry := widget.NewEntry()
+	sizeEntry.SetText(strconv.Itoa(ui.elementSize))
+	sizeEntry.Validator = func(value string) error {
+		valueInt, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		if valueInt < 1 || valueInt > 101 || valueInt%2 == 0 {
+			return fmt.Errorf("the size must be an odd number from 1 to 101")
+		}
+		return nil
+	}
+	matrixEntry := widget.NewMultiLineEntry()
+	matrixEntry.SetText(ui.elementMatrix)
+	matrixEntry.Validator = func(value string) error {
+		_, err := morphology.Parse(value)
+		return err
+	}
+	shapeSelect := widget.NewSelect(append(morphology.Shapes(), custom), func(name string) {
+		if name == custom {
+			sizeEntry.Disable()
+			matrixEntry.Enable()
+			return
+		}
+		sizeEntry.Enable()
+		matrixEntry.Disable()
+	})
+	shapeSelect.SetSelected(ui.elementShape)
+	form := []*widget.FormItem{
+		widget.NewFormItem("Shape", shapeSelect),
+		widget.NewFormItem("Size", sizeEntry),
+		widget.NewFormItem("Matrix", matrixEntry),
+	}
+	dialog.ShowForm("Structuring element", "Ok", "Cancel", form,
+		func(choice bool) {
+			if !choice {
+				return
+			}
+			ui.elementShape = shapeSelect.Selected
+			if ui.elementShape == custom {
+				ui.elementMatrix = strings.TrimSpace(matrixEntry.Text)
+				ui.element, _ = morphology.Parse(ui.elementMatrix) // No need to check thanks to validator
+				return
+			}
+			ui.elementSize, _ = strconv.Atoi(sizeEntry.Text) // No need to check thanks to validator
+			shape, _ := morphology.ParseShape(ui.elementShape)
+			ui.element, _ = morphology.New(shape, ui.elementSize)
+		},
+		ui.MainWindow)
+}
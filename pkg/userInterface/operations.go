@@ -21,6 +21,7 @@ import (
 
 	"github.com/dustin/go-humanize"
 	"github.com/vision-go/vision-go/pkg/histogram"
+	"github.com/vision-go/vision-go/pkg/processing"
 	"github.com/wcharczuk/go-chart/v2"
 	"github.com/wcharczuk/go-chart/v2/drawing"
 
@@ -33,7 +34,7 @@ func (ui *UI) equializationOp() {
 		dialog.ShowError(fmt.Errorf("no image selected"), ui.MainWindow)
 		return
 	}
-	ui.newImage(img.Equalization()) // TODO Improve name
+	ui.showResult(img, img.Equalization()) // TODO Improve name
 }
 
 func (ui *UI) negativeOp() {
@@ -42,7 +43,7 @@ func (ui *UI) negativeOp() {
 		dialog.ShowError(err, ui.MainWindow)
 		return
 	}
-	ui.newImage(currentImage.Negative())
+	ui.showResult(currentImage, currentImage.Negative())
 }
 
 func (ui *UI) monochromeOp() {
@@ -51,7 +52,32 @@ func (ui *UI) monochromeOp() {
 		dialog.ShowError(err, ui.MainWindow)
 		return
 	}
-	ui.newImage(currentImage.Monochrome())
+	ui.showResult(currentImage, currentImage.Monochrome())
+}
+
+// depthOp converts the image to another precision, so that 8-bit images can
+// go through several operations without rounding in between.
+func (ui *UI) depthOp() {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	var descriptions []string
+	for _, name := range processing.Depths() {
+		depth, _ := processing.ParseDepth(name)
+		descriptions = append(descriptions, depth.Description())
+	}
+	depthSelect := widget.NewSelect(descriptions, nil)
+	depthSelect.SetSelected(currentImage.Depth().Description())
+	dialog.ShowForm("Bit Depth", "Ok", "Cancel", []*widget.FormItem{widget.NewFormItem("Depth", depthSelect)},
+		func(choice bool) {
+			if !choice || depthSelect.SelectedIndex() == -1 {
+				return
+			}
+			ui.showResult(currentImage, currentImage.ConvertDepth(processing.Depth(depthSelect.SelectedIndex())))
+		},
+		ui.MainWindow)
 }
 
 func (ui *UI) adjustBrightnessAndContrastOp() {
@@ -60,10 +86,7 @@ func (ui *UI) adjustBrightnessAndContrastOp() {
 		dialog.ShowError(err, ui.MainWindow)
 		return
 	}
-	scale := 500 / math.Max(float64(currentImage.Dimensions().X), float64(currentImage.Dimensions().Y))
-	originalPreview := currentImage.Rescaling(scale, false).CanvasImage().Image
-	previewImg := canvas.NewImageFromImage(originalPreview)
-	previewImg.SetMinSize(fyne.NewSize(500, 500)) // TODO dynamic size
+	originalPreview, previewImg := newPreview(currentImage)
 	brightnessValue, contrastValue := binding.NewFloat(), binding.NewFloat()
 	brightnessLabel, contrastLabel :=
 		widget.NewLabelWithData(binding.FloatToStringWithFormat(brightnessValue, "%v")),
@@ -71,39 +94,81 @@ func (ui *UI) adjustBrightnessAndContrastOp() {
 	brightnessSlider, contrastSlider :=
 		widget.NewSliderWithData(0, 255, brightnessValue),
 		widget.NewSliderWithData(0, 255, contrastValue)
-	brightnessSlider.SetValue(currentImage.Brightness())
-	contrastSlider.SetValue(currentImage.Contrast())
-	brightnessSlider.OnChanged = func(value float64) {
-		brightnessValue.Set(value)
+	stats := currentImage.SelectionStatistics() // What BrightnessAndContrast maps from
+	if ui.pointChannel != everyChannel {
+		stats = currentImage.ChannelStatistics(ui.pointSpace, ui.pointChannel)
+	}
+	oldBrightness, oldContrast := stats.Brightness, stats.Contrast
+	brightnessSlider.SetValue(oldBrightness)
+	contrastSlider.SetValue(oldContrast)
+	preview := func() {
 		newBrightness, _ := brightnessValue.Get()
 		newContrast, _ := contrastValue.Get()
-		previewImg.Image = ourimage.BrightnessAndContrastPreview(originalPreview, currentImage.Brightness(), currentImage.Contrast(), newBrightness, newContrast)
+		previewImg.Image = ui.pointPreview(originalPreview, func(img image.Image) image.Image {
+			return ourimage.BrightnessAndContrastPreview(img, oldBrightness, oldContrast, newBrightness, newContrast)
+		})
 		previewImg.Refresh()
 	}
+	brightnessSlider.OnChanged = func(value float64) {
+		brightnessValue.Set(value)
+		preview()
+	}
 	contrastSlider.OnChanged = func(value float64) {
 		contrastValue.Set(value)
-		newBrightness, _ := brightnessValue.Get()
-		newContrast, _ := contrastValue.Get()
-		previewImg.Image = ourimage.BrightnessAndContrastPreview(originalPreview, currentImage.Brightness(), currentImage.Contrast(), newBrightness, newContrast)
+		preview()
+	}
+	controls := container.NewGridWithRows(4, container.NewCenter(brightnessLabel), brightnessSlider, container.NewCenter(contrastLabel), contrastSlider)
+	ui.previewDialog("Adjust Brightness and Contrast", currentImage, controls, previewImg, func() (*ourimage.OurImage, error) {
+		brightness, _ := brightnessValue.Get()
+		contrast, _ := contrastValue.Get()
+		return ui.pointResult(currentImage, func(img *ourimage.OurImage) *ourimage.OurImage {
+			return img.BrightnessAndContrast(brightness, contrast)
+		})
+	})
+}
+
+// adjustChannelsBrightnessAndContrastOp sets the brightness and the contrast
+// of each colour channel, starting from the ones of the selection.
+func (ui *UI) adjustChannelsBrightnessAndContrastOp() {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	originalPreview, previewImg := newPreview(currentImage)
+	stats := currentImage.SelectionStatistics()
+	var brightnessValues, contrastValues [3]binding.Float
+	values := func() (brightness, contrast [3]float64) {
+		for i := range brightness {
+			brightness[i], _ = brightnessValues[i].Get()
+			contrast[i], _ = contrastValues[i].Get()
+		}
+		return brightness, contrast
+	}
+	update := func() {
+		brightness, contrast := values()
+		previewImg.Image = processing.ChannelsBrightnessAndContrast(originalPreview, stats.ChannelBrightness, stats.ChannelContrast, brightness, contrast)
 		previewImg.Refresh()
 	}
-	content := container.NewGridWithColumns(2, container.NewGridWithRows(4, container.NewCenter(brightnessLabel), brightnessSlider, container.NewCenter(contrastLabel), contrastSlider), previewImg)
-	dialog.ShowCustomConfirm("Adjust Brightness and Contrast", "Ok", "Cancel", content,
-		func(choice bool) {
-			if !choice {
-				return
-			}
-			brightness, err := brightnessValue.Get()
-			if err != nil {
-				dialog.ShowError(err, ui.MainWindow)
-			}
-			contrast, err := contrastValue.Get()
-			if err != nil {
-				dialog.ShowError(err, ui.MainWindow)
-			}
-			ui.newImage(currentImage.BrightnessAndContrast(brightness, contrast))
-		},
-		ui.MainWindow)
+	controls := container.NewVBox()
+	for i, channel := range []string{"Red", "Green", "Blue"} {
+		brightnessValues[i], contrastValues[i] = binding.NewFloat(), binding.NewFloat()
+		brightnessSlider, contrastSlider := widget.NewSliderWithData(0, 255, brightnessValues[i]), widget.NewSliderWithData(0, 255, contrastValues[i])
+		brightnessSlider.SetValue(stats.ChannelBrightness[i])
+		contrastSlider.SetValue(stats.ChannelContrast[i])
+		controls.Add(container.NewCenter(widget.NewLabelWithData(binding.FloatToStringWithFormat(brightnessValues[i], channel+" brightness: %.1f"))))
+		controls.Add(brightnessSlider)
+		controls.Add(container.NewCenter(widget.NewLabelWithData(binding.FloatToStringWithFormat(contrastValues[i], channel+" contrast: %.1f"))))
+		controls.Add(contrastSlider)
+	}
+	for i := range brightnessValues {
+		brightnessValues[i].AddListener(binding.NewDataListener(update))
+		contrastValues[i].AddListener(binding.NewDataListener(update))
+	}
+	ui.previewDialog("Adjust Brightness and Contrast of the Channels", currentImage, controls, previewImg, func() (*ourimage.OurImage, error) {
+		brightness, contrast := values()
+		return currentImage.ChannelsBrightnessAndContrast(brightness, contrast), nil
+	})
 }
 
 func (ui *UI) gammaCorrectionOp() {
@@ -130,7 +195,9 @@ func (ui *UI) gammaCorrectionOp() {
 				return
 			}
 			gamma, _ := strconv.ParseFloat(entry.Text, 64) // No need to check thanks to validator
-			ui.newImage(currentImage.GammaCorrection(gamma))
+			ui.showPointResult(currentImage, func(img *ourimage.OurImage) *ourimage.OurImage {
+				return img.GammaCorrection(gamma)
+			})
 		},
 		ui.MainWindow)
 }
@@ -195,7 +262,7 @@ func (ui *UI) linearTransformationOp() {
 				graph.Refresh()
 			}
 			for i := 0; i < pointsN; i++ {
-				rawPoint, canvasPoint := histogram.NewPoint(i, updateGraph)
+				rawPoint, canvasPoint := newPointEntry(i, updateGraph)
 				points = append(points, rawPoint)
 				canvasPoints = append(canvasPoints, canvasPoint)
 			}
@@ -211,7 +278,9 @@ func (ui *UI) linearTransformationOp() {
 							return
 						}
 					}
-					ui.newImage(currentImage.LinearTransformation(points))
+					ui.showPointResult(currentImage, func(img *ourimage.OurImage) *ourimage.OurImage {
+						return img.LinearTransformation(points)
+					})
 				},
 				ui.MainWindow)
 		},
@@ -227,36 +296,70 @@ func (ui *UI) infoView() {
 	format := currentImage.Format()
 	size := currentImage.Dimensions()
 	message := fmt.Sprintf("Format: %v\n Size: %v bytes (%v x %v)\n", format, humanize.Bytes(uint64(size.X*size.Y)), size.X, size.Y)
+	message += fmt.Sprintf("Depth: %v\n", currentImage.Depth().Description())
 	minColor, maxColor := currentImage.MinAndMaxColor()
 	message += fmt.Sprintf("Range: [%v, %v]", minColor, maxColor)
 	message += "\nBrightness: " + fmt.Sprintf("%f", currentImage.Brightness())
 	message += "\nContrast: " + fmt.Sprintf("%f", currentImage.Contrast())
+	channelBrightness, channelContrast := currentImage.ChannelBrightnessAndContrast()
+	message += fmt.Sprintf("\nBrightness (R, G, B): %.2f, %.2f, %.2f", channelBrightness[0], channelBrightness[1], channelBrightness[2])
+	message += fmt.Sprintf("\nContrast (R, G, B): %.2f, %.2f, %.2f", channelContrast[0], channelContrast[1], channelContrast[2])
+	luminance := currentImage.Luminance()
+	message += fmt.Sprintf("\nLuminance: %v (R %.4g, G %.4g, B %.4g)", luminance, luminance.R, luminance.G, luminance.B)
 	entropy, numberOfColors := currentImage.EntropyAndNumberOfColors()
 	message += "\nEntropy: " + fmt.Sprintf("%f", entropy) + " with " + strconv.Itoa(numberOfColors) + " diferent colors"
+	if selection := currentImage.Selection(); !selection.Empty() {
+		stats := currentImage.SelectionStatistics()
+		message += fmt.Sprintf("\n\nSelection: x=%v, y=%v, %v x %v (%v pixels)", selection.Min.X, selection.Min.Y, selection.Dx(), selection.Dy(), stats.Size)
+		message += fmt.Sprintf("\nRange: [%v, %v]", stats.MinColor, stats.MaxColor)
+		message += "\nBrightness: " + fmt.Sprintf("%f", stats.Brightness)
+		message += "\nContrast: " + fmt.Sprintf("%f", stats.Contrast)
+		message += fmt.Sprintf("\nBrightness (R, G, B): %.2f, %.2f, %.2f", stats.ChannelBrightness[0], stats.ChannelBrightness[1], stats.ChannelBrightness[2])
+		message += fmt.Sprintf("\nContrast (R, G, B): %.2f, %.2f, %.2f", stats.ChannelContrast[0], stats.ChannelContrast[1], stats.ChannelContrast[2])
+		message += "\nEntropy: " + fmt.Sprintf("%f", stats.Entropy) + " with " + strconv.Itoa(stats.NumberOfColors) + " diferent colors"
+	}
+	message += "\n\n" + currentImage.Provenance().String()
 	dialog.ShowInformation("Information", message, ui.MainWindow)
 }
 
+// histogramBins are the bin counts offered by the histogram window, the
+// finer ones for 16-bit and float images.
+var histogramBins = []string{"256", "1024", "4096", "65536"}
+
 func (ui *UI) histogram() {
-	if ui.tabs.SelectedIndex() == -1 {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
 		dialog.ShowError(fmt.Errorf("no image selected"), ui.MainWindow)
 		return
 	}
 	a := ui.App.NewWindow(ui.tabs.Selected().Text + " || (Histogram)")
 	a.Resize(fyne.NewSize(500, 500))
 
-	image := ui.calculateHistogramGraph(convertToFloat(ui.tabsElements[ui.tabs.SelectedIndex()].Histogram[:]), drawing.ColorBlack)
-	imageR := ui.calculateHistogramGraph(convertToFloat(ui.tabsElements[ui.tabs.SelectedIndex()].HistogramR[:]), drawing.ColorRed)
-	imageG := ui.calculateHistogramGraph(convertToFloat(ui.tabsElements[ui.tabs.SelectedIndex()].HistogramG[:]), drawing.ColorGreen)
-	imageB := ui.calculateHistogramGraph(convertToFloat(ui.tabsElements[ui.tabs.SelectedIndex()].HistogramB[:]), drawing.ColorBlue)
+	image1 := canvas.NewImageFromImage(ui.calculateHistogramGraph(convertToFloat(currentImage.Histogram[:]), drawing.ColorBlack))
+	image2 := canvas.NewImageFromImage(ui.calculateHistogramGraph(convertToFloat(currentImage.HistogramR[:]), drawing.ColorRed))
+	image3 := canvas.NewImageFromImage(ui.calculateHistogramGraph(convertToFloat(currentImage.HistogramG[:]), drawing.ColorGreen))
+	image4 := canvas.NewImageFromImage(ui.calculateHistogramGraph(convertToFloat(currentImage.HistogramB[:]), drawing.ColorBlue))
 
-	image1 := canvas.NewImageFromImage(image)
-	image2 := canvas.NewImageFromImage(imageR)
-	image3 := canvas.NewImageFromImage(imageG)
-	image4 := canvas.NewImageFromImage(imageB)
+	binsSelect := widget.NewSelect(histogramBins, func(value string) {
+		bins, _ := strconv.Atoi(value)
+		hist, err := processing.NewBinnedHistograms(currentImage.Image(), bins, currentImage.Luminance())
+		if err != nil {
+			dialog.ShowError(err, a)
+			return
+		}
+		image1.Image = ui.calculateHistogramGraph(convertToFloat(hist.Histogram), drawing.ColorBlack)
+		image2.Image = ui.calculateHistogramGraph(convertToFloat(hist.HistogramR), drawing.ColorRed)
+		image3.Image = ui.calculateHistogramGraph(convertToFloat(hist.HistogramG), drawing.ColorGreen)
+		image4.Image = ui.calculateHistogramGraph(convertToFloat(hist.HistogramB), drawing.ColorBlue)
+		for _, image := range []*canvas.Image{image1, image2, image3, image4} {
+			image.Refresh()
+		}
+	})
+	binsSelect.Selected = histogramBins[0] // The ones of the image, already drawn
 
 	content := container.New(layout.NewAdaptiveGridLayout(2), image1, image2, image3, image4)
 
-	a.SetContent(content)
+	a.SetContent(container.NewBorder(container.NewHBox(widget.NewLabel("Bins"), binsSelect), nil, nil, nil, content))
 	a.Show()
 }
 
@@ -309,14 +412,16 @@ func (ui *UI) normalizedHistogram() {
 	a.Show()
 }
 
-func (ui *UI) calculateHistogramGraph(valuesY []float64, color drawing.Color) image.Image {
+// calculateHistogramGraph draws the histogram valuesY, with any number of
+// bins, with a vertical line at every threshold.
+func (ui *UI) calculateHistogramGraph(valuesY []float64, color drawing.Color, thresholds ...int) image.Image {
 	var indexValues []float64
 	strokeColor := color
 	fillColor := color
 	fillColor.A = 128
 
-	for i := 0; i < 256; i++ {
-		indexValues = append(indexValues, float64(i))
+	for i := range valuesY { // Spread over the levels [0, 255] whatever the bins
+		indexValues = append(indexValues, float64(i)*255/float64(len(valuesY)-1))
 	}
 	graph := chart.Chart{
 		Series: []chart.Series{
@@ -330,6 +435,17 @@ func (ui *UI) calculateHistogramGraph(valuesY []float64, color drawing.Color) im
 			},
 		},
 	}
+	top := 0.0
+	for _, value := range valuesY {
+		top = math.Max(top, value)
+	}
+	for _, t := range thresholds {
+		graph.Series = append(graph.Series, chart.ContinuousSeries{
+			Style:   chart.Style{StrokeColor: drawing.ColorRed, StrokeWidth: 2},
+			XValues: []float64{float64(t), float64(t)},
+			YValues: []float64{0, top},
+		})
+	}
 	collector := &chart.ImageWriter{}
 	graph.Render(chart.PNG, collector)
 
@@ -358,10 +474,11 @@ func (ui *UI) histogramEqual() {
 			ui.label, ui.MainWindow, ui.ROIcallback, ui.closeTabsCallback)
 		if err != nil {
 			dialog.ShowError(err, ui.MainWindow)
+			return
 		}
-		ui.newImage(currentImage.HistogramIgualation(img))
+		ui.showResult(currentImage, currentImage.HistogramIgualation(img))
 	}, ui.MainWindow)
-	dialog.SetFilter(storage.NewExtensionFileFilter([]string{".png", ".jpeg", ".jpg", ".tfe", ".tfi"}))
+	dialog.SetFilter(storage.NewExtensionFileFilter(processing.Extensions))
 	dialog.Show()
 
 }
@@ -384,14 +501,16 @@ func (ui *UI) imgDifference() {
 			ui.label, ui.MainWindow, ui.ROIcallback, ui.closeTabsCallback)
 		if err != nil {
 			dialog.ShowError(err, ui.MainWindow)
+			return
 		}
 		img, err = currentImage.ImageDiference(img)
 		if err != nil {
 			dialog.ShowError(err, ui.MainWindow)
+			return
 		}
-		ui.newImage(img)
+		ui.showResult(currentImage, img)
 	}, ui.MainWindow)
-	dialog.SetFilter(storage.NewExtensionFileFilter([]string{".png", ".jpeg", ".jpg", ".tfe", ".tif"}))
+	dialog.SetFilter(storage.NewExtensionFileFilter(processing.Extensions))
 	dialog.Show()
 }
 
@@ -450,15 +569,16 @@ func (ui *UI) imgChangeMap() {
 					ui.label, ui.MainWindow, ui.ROIcallback, ui.closeTabsCallback)
 				if err != nil {
 					dialog.ShowError(err, ui.MainWindow)
+					return
 				}
 				img, err = currentImage.ChangeMap(img, colorPicked, tValue) // TODO changemap doesn't need a full ourImage
 				if err != nil {
 					dialog.ShowError(err, ui.MainWindow)
 					return
 				}
-				ui.newImage(img)
+				ui.showResult(currentImage, img)
 			}, ui.MainWindow)
-			dialog.SetFilter(storage.NewExtensionFileFilter([]string{".png", ".jpeg", ".jpg", ".tfe", ".tfi"}))
+			dialog.SetFilter(storage.NewExtensionFileFilter(processing.Extensions))
 			dialog.Show()
 		},
 		ui.MainWindow)
@@ -472,6 +592,32 @@ func convertToFloat(f32 []int) []float64 {
 	return f64
 }
 
+// newPointEntry returns a point of a linear transformation and the entries
+// of its coordinates, which update it and call onChanged. Invalid values
+// leave it at -1.
+func newPointEntry(id int, onChanged func()) (*histogram.Point, fyne.CanvasObject) {
+	p := &histogram.Point{X: -1, Y: -1}
+	widgetForX := widget.NewEntry()
+	widgetForX.OnChanged = func(change string) {
+		changeInt, err := strconv.Atoi(change)
+		if err != nil {
+			changeInt = -1
+		}
+		p.X = changeInt
+		onChanged()
+	}
+	widgetForY := widget.NewEntry()
+	widgetForY.OnChanged = func(change string) {
+		changeInt, err := strconv.Atoi(change)
+		if err != nil {
+			changeInt = -1
+		}
+		p.Y = changeInt
+		onChanged()
+	}
+	return p, container.NewAdaptiveGrid(3, widget.NewLabel("Point "+strconv.Itoa(id+1)+": "), widgetForX, widgetForY)
+}
+
 func createGraph(points []histogram.Point) (image.Image, error) {
 	Xs := make([]float64, len(points))
 	Ys := make([]float64, len(points))
@@ -506,7 +652,7 @@ func (ui *UI) horizontal() {
 		dialog.ShowError(err, ui.MainWindow)
 		return
 	}
-	ui.newImage(currentImage.HorizontalMirror())
+	ui.showResult(currentImage, currentImage.HorizontalMirror())
 }
 
 func (ui *UI) vertical() {
@@ -515,7 +661,7 @@ func (ui *UI) vertical() {
 		dialog.ShowError(err, ui.MainWindow)
 		return
 	}
-	ui.newImage(currentImage.VerticalMirror())
+	ui.showResult(currentImage, currentImage.VerticalMirror())
 }
 
 func (ui *UI) rotateRight() {
@@ -524,7 +670,7 @@ func (ui *UI) rotateRight() {
 		dialog.ShowError(err, ui.MainWindow)
 		return
 	}
-	ui.newImage(currentImage.RotateRight())
+	ui.showResult(currentImage, currentImage.RotateRight())
 }
 
 func (ui *UI) rotateLeft() {
@@ -533,7 +679,7 @@ func (ui *UI) rotateLeft() {
 		dialog.ShowError(err, ui.MainWindow)
 		return
 	}
-	ui.newImage(currentImage.RotateLeft())
+	ui.showResult(currentImage, currentImage.RotateLeft())
 }
 
 func (ui *UI) transpose() {
@@ -542,7 +688,7 @@ func (ui *UI) transpose() {
 		dialog.ShowError(err, ui.MainWindow)
 		return
 	}
-	ui.newImage(currentImage.Transpose())
+	ui.showResult(currentImage, currentImage.Transpose())
 }
 
 func (ui *UI) rescaling() {
@@ -581,7 +727,7 @@ func (ui *UI) rescaling() {
 				return
 			}
 			rescalingFactor, _ := strconv.ParseFloat(entry.Text, 64) // No need to check thanks to validator
-			ui.newImage(currentImage.Rescaling(rescalingFactor/100, typeSelect))
+			ui.showResult(currentImage, currentImage.Rescaling(rescalingFactor/100, typeSelect))
 		},
 		ui.MainWindow)
 }
@@ -602,7 +748,7 @@ func (ui *UI) rotateAndPrint() {
 				return
 			}
 			angle, _ := strconv.ParseFloat(entry.Text, 64)
-			ui.newImage(currentImage.RotateAndPrint(angle))
+			ui.showResult(currentImage, currentImage.RotateAndPrint(angle))
 		},
 		ui.MainWindow)
 }
@@ -626,7 +772,7 @@ func (ui *UI) rotate() {
 				return
 			}
 			angle, _ := strconv.ParseFloat(entry.Text, 64)
-			ui.newImage(currentImage.Rotate(angle, selection.SelectedIndex()))
+			ui.showResult(currentImage, currentImage.Rotate(angle, selection.SelectedIndex()))
 		},
 		ui.MainWindow)
 }
@@ -0,0 +1,72 @@
+package userinterface
+
+import (
+	"image"
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+
+	ourimage "github.com/vision-go/vision-go/pkg/ourImage"
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// previewSize is the largest side of the previews of the dialogs.
+const previewSize = 500
+
+// newPreview returns a copy of img without the selection, rescaled so its
+// largest side is previewSize, and the canvas that shows it at that size.
+func newPreview(img *ourimage.OurImage) (image.Image, *canvas.Image) {
+	scale := previewSize / math.Max(float64(img.Dimensions().X), float64(img.Dimensions().Y))
+	original := processing.Rescaling(img.Image(), scale, false)
+	return original, previewCanvas(original)
+}
+
+// newDetailPreview returns the centre of img without the selection, at most
+// previewSize on each side, at the scale of img, and the canvas that shows
+// it. Operations whose parameters are in pixels, as sigmas and windows, look
+// the same on it as on img, which they don't on a rescaled preview.
+func newDetailPreview(img *ourimage.OurImage) (image.Image, *canvas.Image) {
+	bounds := img.Image().Bounds()
+	size := bounds.Size()
+	if size.X > previewSize {
+		size.X = previewSize
+	}
+	if size.Y > previewSize {
+		size.Y = previewSize
+	}
+	min := bounds.Min.Add(bounds.Size().Sub(size).Div(2))
+	original := processing.ROI(img.Image(), image.Rectangle{Min: min, Max: min.Add(size)})
+	return original, previewCanvas(original)
+}
+
+// previewCanvas shows img at its size.
+func previewCanvas(img image.Image) *canvas.Image {
+	previewImg := canvas.NewImageFromImage(img)
+	previewImg.FillMode = canvas.ImageFillContain
+	size := img.Bounds().Size()
+	previewImg.SetMinSize(fyne.NewSize(float32(size.X), float32(size.Y)))
+	return previewImg
+}
+
+// previewDialog shows controls next to preview and, when accepted, the
+// image apply makes from currentImage.
+func (ui *UI) previewDialog(title string, currentImage *ourimage.OurImage, controls fyne.CanvasObject, preview *canvas.Image,
+	apply func() (*ourimage.OurImage, error)) {
+	content := container.NewGridWithColumns(2, controls, preview)
+	dialog.ShowCustomConfirm(title, "Ok", "Cancel", content,
+		func(choice bool) {
+			if !choice {
+				return
+			}
+			result, err := apply()
+			if err != nil {
+				dialog.ShowError(err, ui.MainWindow)
+				return
+			}
+			ui.showResult(currentImage, result)
+		},
+		ui.MainWindow)
+}
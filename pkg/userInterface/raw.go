@@ -0,0 +1,127 @@
+package userinterface
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	ourimage "github.com/vision-go/vision-go/pkg/ourImage"
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// maxRawGuesses is how many guesses of the options of a raw file are
+// offered.
+const maxRawGuesses = 20
+
+func (ui *UI) openRawDialog() {
+	dialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, ui.MainWindow)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		reader.Close() // Read again with the options
+		ui.rawOptionsDialog(reader.URI().Path(), reader.URI().Name())
+	}, ui.MainWindow)
+	dialog.SetFilter(storage.NewExtensionFileFilter([]string{".tfe", ".tfi", ".raw", ".bin"}))
+	dialog.Show()
+}
+
+// rawOptionsDialog asks how the pixels of the raw file path are stored,
+// filled with the likeliest guess for its size.
+func (ui *UI) rawOptionsDialog(path, name string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	guesses := processing.GuessRaw(info.Size())
+	if len(guesses) > maxRawGuesses {
+		guesses = guesses[:maxRawGuesses]
+	}
+	widthEntry, heightEntry, offsetEntry := widget.NewEntry(), widget.NewEntry(), widget.NewEntry()
+	positive := func(value string) error {
+		valueInt, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		if valueInt < 1 {
+			return fmt.Errorf("the value must be positive")
+		}
+		return nil
+	}
+	widthEntry.Validator, heightEntry.Validator = positive, positive
+	offsetEntry.Validator = func(value string) error {
+		offset, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		if offset < 0 || offset >= info.Size() {
+			return fmt.Errorf("the header must be shorter than the file")
+		}
+		return nil
+	}
+	depthSelect := widget.NewSelect([]string{"8", "16"}, nil)
+	orderSelect := widget.NewSelect([]string{"little", "big"}, nil)
+	channelsSelect := widget.NewSelect([]string{"1", "3", "4"}, nil)
+	fill := func(o processing.RawOptions) {
+		widthEntry.SetText(strconv.Itoa(o.Width))
+		heightEntry.SetText(strconv.Itoa(o.Height))
+		depthSelect.SetSelected(strconv.Itoa(o.BitDepth))
+		orderSelect.SetSelected("little")
+		if o.BigEndian {
+			orderSelect.SetSelected("big")
+		}
+		channelsSelect.SetSelected(strconv.Itoa(o.Channels))
+		offsetEntry.SetText(strconv.FormatInt(o.Offset, 10))
+	}
+	names := make([]string, len(guesses))
+	for i, guess := range guesses {
+		names[i] = fmt.Sprintf("%vx%v, %v-bit, %v channels, header %v", guess.Width, guess.Height, guess.BitDepth, guess.Channels, guess.Offset)
+	}
+	guessSelect := widget.NewSelect(names, func(string) {})
+	guessSelect.OnChanged = func(string) {
+		fill(guesses[guessSelect.SelectedIndex()])
+	}
+	if len(guesses) != 0 {
+		guessSelect.SetSelected(names[0])
+	} else {
+		fill(processing.DefaultRaw)
+	}
+	form := []*widget.FormItem{
+		widget.NewFormItem("Guess", guessSelect),
+		widget.NewFormItem("Width", widthEntry),
+		widget.NewFormItem("Height", heightEntry),
+		widget.NewFormItem("Bit depth", depthSelect),
+		widget.NewFormItem("Byte order", orderSelect),
+		widget.NewFormItem("Channels", channelsSelect),
+		widget.NewFormItem("Header bytes", offsetEntry),
+	}
+	dialog.ShowForm(fmt.Sprintf("Raw %v (%v bytes)", name, info.Size()), "Ok", "Cancel", form,
+		func(choice bool) {
+			if !choice {
+				return
+			}
+			var o processing.RawOptions
+			o.Width, _ = strconv.Atoi(widthEntry.Text) // No need to check thanks to validator
+			o.Height, _ = strconv.Atoi(heightEntry.Text)
+			o.BitDepth, _ = strconv.Atoi(depthSelect.Selected)
+			o.BigEndian = orderSelect.Selected == "big"
+			o.Channels, _ = strconv.Atoi(channelsSelect.Selected)
+			o.Offset, _ = strconv.ParseInt(offsetEntry.Text, 10, 64)
+			img, err := ourimage.NewFromRaw(path, name, o, ui.label, ui.MainWindow, ui.ROIcallback, ui.closeTabsCallback)
+			if err != nil {
+				dialog.ShowError(err, ui.MainWindow)
+				return
+			}
+			ui.newImage(img)
+		},
+		ui.MainWindow)
+}
@@ -0,0 +1,162 @@
+package userinterface
+
+import (
+	"fmt"
+	"image"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	ourimage "github.com/vision-go/vision-go/pkg/ourImage"
+)
+
+// editSelectionDialog lets the selection be typed instead of drawn.
+func (ui *UI) editSelectionDialog() {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	selection, size := currentImage.Selection(), currentImage.Dimensions()
+	if selection.Empty() {
+		selection = image.Rect(0, 0, size.X, size.Y)
+	}
+	limits := []int{size.X - 1, size.Y - 1, size.X, size.Y}
+	names := []string{"X", "Y", "Width", "Height"}
+	values := []int{selection.Min.X, selection.Min.Y, selection.Dx(), selection.Dy()}
+	entries := make([]*widget.Entry, len(values))
+	form := make([]*widget.FormItem, len(values))
+	for i := range entries {
+		i := i
+		entries[i] = widget.NewEntry()
+		entries[i].SetText(strconv.Itoa(values[i]))
+		entries[i].Validator = func(value string) error {
+			valueInt, err := strconv.Atoi(value)
+			if err != nil {
+				return err
+			}
+			min := 0
+			if i >= 2 {
+				min = 1
+			}
+			if valueInt < min || valueInt > limits[i] {
+				return fmt.Errorf("the value must be between %v and %v", min, limits[i])
+			}
+			return nil
+		}
+		form[i] = widget.NewFormItem(names[i], entries[i])
+	}
+	dialog.ShowForm("Selection", "Ok", "Cancel", form,
+		func(choice bool) {
+			if !choice {
+				return
+			}
+			for i := range entries {
+				values[i], _ = strconv.Atoi(entries[i].Text) // No need to check thanks to validator
+			}
+			rect := image.Rect(values[0], values[1], values[0]+values[2], values[1]+values[3])
+			if err := currentImage.SetSelection(rect); err != nil {
+				dialog.ShowError(err, ui.MainWindow)
+			}
+		},
+		ui.MainWindow)
+}
+
+func (ui *UI) selectAll() {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	currentImage.SelectAll()
+}
+
+func (ui *UI) clearSelection() {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	currentImage.ClearSelection()
+}
+
+// cropToSelection opens the selected part of the image in a new tab.
+func (ui *UI) cropToSelection() {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	cropped, err := currentImage.Crop()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	ui.newImage(cropped)
+}
+
+// setTool changes the selection tool of every image.
+func (ui *UI) setTool(tool int) {
+	ui.selectionTool = tool
+	for i, item := range ui.toolItems {
+		item.Checked = i == tool
+	}
+	for _, img := range ui.tabsElements {
+		img.SetTool(tool)
+	}
+	ui.MainWindow.SetMainMenu(ui.menu)
+}
+
+func (ui *UI) wandToleranceDialog() {
+	entry := widget.NewEntry()
+	entry.SetText(strconv.Itoa(ui.wandTolerance))
+	entry.Validator = func(value string) error {
+		valueInt, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		if valueInt < 0 || valueInt > 255 {
+			return fmt.Errorf("the values must be integers in the range [0, 255]")
+		}
+		return nil
+	}
+	form := []*widget.FormItem{
+		widget.NewFormItem("Tolerance", entry),
+	}
+	dialog.ShowForm("Magic wand", "Ok", "Cancel", form,
+		func(choice bool) {
+			if !choice {
+				return
+			}
+			ui.wandTolerance, _ = strconv.Atoi(entry.Text) // No need to check thanks to validator
+			for _, img := range ui.tabsElements {
+				img.SetWandTolerance(ui.wandTolerance)
+			}
+		},
+		ui.MainWindow)
+}
+
+func (ui *UI) selectionMenu() *fyne.Menu {
+	ui.wandTolerance = ourimage.DefaultWandTolerance
+	names := []string{"Rectangle", "Ellipse", "Polygon", "Freehand", "Magic wand"}
+	ui.toolItems = make([]*fyne.MenuItem, len(names))
+	for tool, name := range names {
+		tool := tool
+		ui.toolItems[tool] = fyne.NewMenuItem(name, func() { ui.setTool(tool) })
+	}
+	ui.toolItems[ourimage.RectangleTool].Checked = true
+	tools := fyne.NewMenuItem("Tool", nil)
+	tools.ChildMenu = fyne.NewMenu("", ui.toolItems...)
+	return fyne.NewMenu("Selection",
+		tools,
+		fyne.NewMenuItem("Magic wand tolerance...", ui.wandToleranceDialog),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Edit selection...", ui.editSelectionDialog),
+		fyne.NewMenuItem("Select all", ui.selectAll),
+		fyne.NewMenuItem("Clear selection", ui.clearSelection),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Crop to selection", ui.cropToSelection),
+	)
+}
@@ -0,0 +1,159 @@
+package userinterface
+
+import (
+	"image/color"
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/vision-go/vision-go/pkg/histogram"
+	ourimage "github.com/vision-go/vision-go/pkg/ourImage"
+	"github.com/vision-go/vision-go/pkg/processing"
+)
+
+// curveStep is the distance between the levels of the points of a drawn
+// curve, which is linear between them.
+const curveStep = 4
+
+// curveEditor shows a target distribution and lets it be drawn with the
+// mouse, every level being as tall as its relative frequency.
+type curveEditor struct {
+	widget.BaseWidget
+	values    [256]float64 // 0-1
+	last      int          // Level of the previous drag event, -1 if none
+	raster    *canvas.Raster
+	onChanged func() // After drawing
+}
+
+func newCurveEditor(onChanged func()) *curveEditor {
+	editor := &curveEditor{last: -1, onChanged: onChanged}
+	editor.raster = canvas.NewRasterWithPixels(func(x, y, w, h int) color.Color {
+		if 1-float64(y)/float64(h) <= editor.values[x*256/w] {
+			return color.Gray{Y: 64}
+		}
+		return color.White
+	})
+	editor.ExtendBaseWidget(editor)
+	return editor
+}
+
+func (editor *curveEditor) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(editor.raster)
+}
+
+func (editor *curveEditor) MinSize() fyne.Size {
+	return fyne.NewSize(256, 200)
+}
+
+// setTarget shows target scaled to the height of the editor.
+func (editor *curveEditor) setTarget(target histogram.HistogramNormalized) {
+	top := 0.0
+	for _, value := range target {
+		top = math.Max(top, value)
+	}
+	for i, value := range target {
+		editor.values[i] = value / top
+	}
+	editor.raster.Refresh()
+}
+
+func (editor *curveEditor) Dragged(event *fyne.DragEvent) {
+	size := editor.Size()
+	level := int(float64(event.Position.X) / float64(size.Width) * 256)
+	level = int(math.Max(0, math.Min(255, float64(level))))
+	value := math.Max(0, math.Min(1, 1-float64(event.Position.Y)/float64(size.Height)))
+	from, fromValue := level, value
+	if editor.last >= 0 {
+		from, fromValue = editor.last, editor.values[editor.last]
+	}
+	for i := from; ; { // Without gaps when the mouse moves fast
+		t := 1.0
+		if level != from {
+			t = float64(i-from) / float64(level-from)
+		}
+		editor.values[i] = fromValue + (value-fromValue)*t
+		if i == level {
+			break
+		}
+		if level > from {
+			i++
+		} else {
+			i--
+		}
+	}
+	editor.last = level
+	editor.raster.Refresh()
+}
+
+func (editor *curveEditor) DragEnd() {
+	editor.last = -1
+	editor.onChanged()
+}
+
+// points samples the curve every curveStep levels, in the scale of the
+// points of the histogram package.
+func (editor *curveEditor) points() []*histogram.Point {
+	var points []*histogram.Point
+	for level := 0; level < 256; level += curveStep {
+		points = append(points, &histogram.Point{X: level, Y: int(math.Round(editor.values[level] * 255))})
+	}
+	return append(points, &histogram.Point{X: 255, Y: int(math.Round(editor.values[255] * 255))})
+}
+
+// histogramSpecificationOp maps the image to an analytic distribution or to
+// one drawn by the user, which starts as the last analytic one.
+func (ui *UI) histogramSpecificationOp() {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	originalPreview, previewImg := newPreview(currentImage)
+	distribution := histogram.Uniform
+	meanValue, sigmaValue := binding.NewFloat(), binding.NewFloat()
+	meanSlider, sigmaSlider := widget.NewSliderWithData(1, 255, meanValue), widget.NewSliderWithData(1, 128, sigmaValue)
+	meanSlider.SetValue(128)
+	sigmaSlider.SetValue(40)
+	var editor *curveEditor
+	var distributionSelect *widget.Select
+	update := func() {
+		mean, _ := meanValue.Get()
+		sigma, _ := sigmaValue.Get()
+		target, err := histogram.Target(distribution, mean, sigma, editor.points())
+		if err != nil { // A curve drawn at 0
+			return
+		}
+		if distribution != histogram.Curve {
+			editor.setTarget(target)
+		}
+		previewImg.Image = processing.HistogramSpecification(originalPreview, target, nil) // Without the selection
+		previewImg.Refresh()
+	}
+	editor = newCurveEditor(func() {
+		distributionSelect.SetSelected(histogram.Curve.String()) // Which updates the preview
+	})
+	distributionSelect = widget.NewSelect(histogram.Distributions(), func(name string) {
+		distribution, _ = histogram.ParseDistribution(name) // The options are the names of the distributions
+		update()
+	})
+	distributionSelect.SetSelected(distribution.String())
+	for _, value := range []binding.Float{meanValue, sigmaValue} {
+		value.AddListener(binding.NewDataListener(update))
+	}
+	controls := container.NewVBox(
+		container.NewCenter(widget.NewLabel("Distribution")), distributionSelect,
+		container.NewCenter(widget.NewLabelWithData(binding.FloatToStringWithFormat(meanValue, "Mean: %.0f"))), meanSlider,
+		container.NewCenter(widget.NewLabelWithData(binding.FloatToStringWithFormat(sigmaValue, "Sigma of the gaussian: %.0f"))), sigmaSlider,
+		container.NewCenter(widget.NewLabel("Target (draw to change it)")), editor,
+	)
+	ui.previewDialog("Histogram Specification", currentImage, controls, previewImg, func() (*ourimage.OurImage, error) {
+		mean, _ := meanValue.Get()
+		sigma, _ := sigmaValue.Get()
+		return currentImage.HistogramSpecification(distribution, mean, sigma, editor.points())
+	})
+}
@@ -0,0 +1,91 @@
+package userinterface
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"github.com/wcharczuk/go-chart/v2/drawing"
+
+	ourimage "github.com/vision-go/vision-go/pkg/ourImage"
+)
+
+// selectionStatistics is the window with the statistics and histograms of
+// the selection of the current image, updated while the selection changes.
+type selectionStatistics struct {
+	window     fyne.Window
+	label      *widget.Label
+	histograms [4]*canvas.Image
+	pending    chan *ourimage.OurImage // Only the last change is computed
+}
+
+func (ui *UI) selectionStatisticsView() {
+	if ui.statistics != nil {
+		ui.statistics.window.RequestFocus()
+		return
+	}
+	stats := &selectionStatistics{
+		window:  ui.App.NewWindow("Selection statistics"),
+		label:   widget.NewLabel(""),
+		pending: make(chan *ourimage.OurImage, 1),
+	}
+	grid := container.New(layout.NewAdaptiveGridLayout(2))
+	for i := range stats.histograms {
+		stats.histograms[i] = canvas.NewImageFromImage(nil)
+		stats.histograms[i].FillMode = canvas.ImageFillContain
+		stats.histograms[i].SetMinSize(fyne.NewSize(250, 200))
+		grid.Add(stats.histograms[i])
+	}
+	stats.window.SetContent(container.NewBorder(stats.label, nil, nil, nil, grid))
+	stats.window.SetOnClosed(func() {
+		close(stats.pending)
+		ui.statistics = nil
+	})
+	ui.statistics = stats
+	go ui.computeStatistics(stats)
+	if img, err := ui.getCurrentImage(); err == nil {
+		ui.selectionCallback(img)
+	}
+	stats.window.Show()
+}
+
+// selectionCallback is told by the images when their selection changes.
+func (ui *UI) selectionCallback(img *ourimage.OurImage) {
+	if ui.statistics == nil {
+		return
+	}
+	if current, err := ui.getCurrentImage(); err != nil || current != img {
+		return
+	}
+	select {
+	case <-ui.statistics.pending: // Replaced by the newer one
+	default:
+	}
+	ui.statistics.pending <- img
+}
+
+func (ui *UI) computeStatistics(stats *selectionStatistics) {
+	colors := []drawing.Color{drawing.ColorBlack, drawing.ColorRed, drawing.ColorGreen, drawing.ColorBlue}
+	for img := range stats.pending {
+		statistics := img.SelectionStatistics()
+		selection := img.Selection()
+		message := img.Name() + "\nSelection: whole image"
+		if !selection.Empty() {
+			message = fmt.Sprintf("%v\nSelection: x=%v, y=%v, %v x %v", img.Name(), selection.Min.X, selection.Min.Y, selection.Dx(), selection.Dy())
+		}
+		message += fmt.Sprintf("\nPixels: %v\nRange: [%v, %v]\nBrightness: %f\nContrast: %f\nEntropy: %f with %v diferent colors",
+			statistics.Size, statistics.MinColor, statistics.MaxColor, statistics.Brightness, statistics.Contrast, statistics.Entropy, statistics.NumberOfColors)
+		message += fmt.Sprintf("\nBrightness (R, G, B): %.2f, %.2f, %.2f\nContrast (R, G, B): %.2f, %.2f, %.2f",
+			statistics.ChannelBrightness[0], statistics.ChannelBrightness[1], statistics.ChannelBrightness[2],
+			statistics.ChannelContrast[0], statistics.ChannelContrast[1], statistics.ChannelContrast[2])
+		stats.label.SetText(message)
+		histograms := [][]int{statistics.Histogram[:], statistics.HistogramR[:], statistics.HistogramG[:], statistics.HistogramB[:]}
+		for i, histogram := range histograms {
+			stats.histograms[i].Image = ui.calculateHistogramGraph(convertToFloat(histogram), colors[i])
+			stats.histograms[i].Refresh()
+		}
+	}
+}
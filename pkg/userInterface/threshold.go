@@ -0,0 +1,132 @@
+package userinterface
+
+import (
+	"fmt"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/wcharczuk/go-chart/v2/drawing"
+
+	ourimage "github.com/vision-go/vision-go/pkg/ourImage"
+	"github.com/vision-go/vision-go/pkg/threshold"
+)
+
+const multiOtsu = "multi-otsu" // Shown with the methods of the threshold package
+
+// thresholdOp shows the grey histogram of the selection with the threshold
+// of the chosen method drawn on it, and a preview of the binarized image.
+func (ui *UI) thresholdOp() {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	hist := currentImage.SelectionStatistics().Histogram
+	originalPreview, previewImg := newPreview(currentImage)
+	histogramImg := canvas.NewImageFromImage(nil)
+	histogramImg.FillMode = canvas.ImageFillContain
+	histogramImg.SetMinSize(fyne.NewSize(400, 300))
+	thresholdLabel := widget.NewLabel("")
+	method := threshold.Otsu.String()
+	value, classes := binding.NewFloat(), binding.NewFloat()
+	valueSlider, classesSlider := widget.NewSliderWithData(0, 255, value), widget.NewSliderWithData(2, 5, classes)
+	valueSlider.SetValue(128)
+	classesSlider.SetValue(3)
+	thresholds := func() []int {
+		if method == multiOtsu {
+			n, _ := classes.Get()
+			levels, _ := threshold.MultiOtsu(hist, int(n)) // The slider keeps it valid
+			return levels
+		}
+		v, _ := value.Get()
+		m, _ := threshold.ParseMethod(method)
+		return []int{threshold.Compute(m, hist, int(v))}
+	}
+	update := func() {
+		levels := thresholds()
+		thresholdLabel.SetText(fmt.Sprint("Threshold: ", levels))
+		histogramImg.Image = ui.calculateHistogramGraph(convertToFloat(hist[:]), drawing.ColorBlack, levels...)
+		histogramImg.Refresh()
+		previewImg.Image = threshold.Levels(originalPreview, levels, currentImage.Luminance())
+		previewImg.Refresh()
+	}
+	methodSelect := widget.NewSelect(append(threshold.Methods(), multiOtsu), func(name string) {
+		method = name
+		update()
+	})
+	methodSelect.SetSelected(method)
+	for _, data := range []binding.Float{value, classes} {
+		data.AddListener(binding.NewDataListener(update))
+	}
+	controls := container.NewVBox(
+		container.NewCenter(widget.NewLabel("Method")), methodSelect,
+		container.NewCenter(widget.NewLabelWithData(binding.FloatToStringWithFormat(value, "Threshold of the manual method: %.0f"))), valueSlider,
+		container.NewCenter(widget.NewLabelWithData(binding.FloatToStringWithFormat(classes, "Classes of multi-otsu: %.0f"))), classesSlider,
+		thresholdLabel,
+		histogramImg,
+	)
+	ui.previewDialog("Threshold", currentImage, controls, previewImg, func() (*ourimage.OurImage, error) {
+		if method == multiOtsu {
+			n, _ := classes.Get()
+			result, _, err := currentImage.MultiOtsu(int(n))
+			return result, err
+		}
+		m, _ := threshold.ParseMethod(method)
+		v, _ := value.Get()
+		result, _ := currentImage.Threshold(m, int(v))
+		return result, nil
+	})
+}
+
+func (ui *UI) adaptiveThresholdOp() {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	localSelect := widget.NewSelect(threshold.Locals(), nil)
+	localSelect.SetSelected(threshold.Mean.String())
+	sizeEntry, cEntry := widget.NewEntry(), widget.NewEntry()
+	sizeEntry.SetText("15")
+	cEntry.SetText("5")
+	sizeEntry.Validator = func(value string) error {
+		size, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		return threshold.ValidateAdaptive(size, 0)
+	}
+	cEntry.Validator = func(value string) error {
+		c, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		return threshold.ValidateAdaptive(3, c)
+	}
+	form := []*widget.FormItem{
+		widget.NewFormItem("Average", localSelect),
+		widget.NewFormItem("Window", sizeEntry),
+		widget.NewFormItem("C", cEntry),
+	}
+	dialog.ShowForm("Adaptive threshold", "Ok", "Cancel", form,
+		func(choice bool) {
+			if !choice {
+				return
+			}
+			local, _ := threshold.ParseLocal(localSelect.Selected)
+			size, _ := strconv.Atoi(sizeEntry.Text) // No need to check thanks to validator
+			c, _ := strconv.ParseFloat(cEntry.Text, 64)
+			result, err := currentImage.AdaptiveThreshold(local, size, c)
+			if err != nil {
+				dialog.ShowError(err, ui.MainWindow)
+				return
+			}
+			ui.showResult(currentImage, result)
+		},
+		ui.MainWindow)
+}
@@ -2,17 +2,22 @@ package userinterface
 
 import (
 	"fmt"
+	"image/jpeg"
 	"os"
 	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/dialog"
-	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/widget"
 
+	"github.com/vision-go/vision-go/pkg/colorspace"
+	"github.com/vision-go/vision-go/pkg/convolution"
+	"github.com/vision-go/vision-go/pkg/morphology"
 	ourimage "github.com/vision-go/vision-go/pkg/ourImage"
+	"github.com/vision-go/vision-go/pkg/processing"
 )
 
 type UI struct {
@@ -23,6 +28,23 @@ type UI struct {
 	progessBar   *widget.ProgressBarInfinite
 	tabsElements []*ourimage.OurImage // To avoid reflection on tabs
 	menu         *fyne.MainMenu
+	inPlace      bool // Operations replace the current tab instead of opening a new one
+	historyLimit int
+	historyList  *widget.List
+	historyPanel *fyne.Container
+
+	selectionTool int
+	wandTolerance int
+	toolItems     []*fyne.MenuItem
+	statistics    *selectionStatistics // Nil while its window is closed
+	border        convolution.Border
+	borderItems   []*fyne.MenuItem
+	element       morphology.Element // Of every morphology operation
+	elementShape  string             // Name of the shape or custom
+	elementSize   int
+	elementMatrix string
+	pointSpace    colorspace.Space // Of pointChannel
+	pointChannel  int              // Changed by the point operations, everyChannel for all of them
 }
 
 func (ui *UI) Init() {
@@ -47,6 +69,14 @@ func (ui *UI) Init() {
 	ui.progessBar = widget.NewProgressBarInfinite()
 	ui.progessBar.Hide()
 	ui.progessBar.Stop()
+	ui.initHistory()
+	ui.initZoom()
+	ui.tabs.OnSelected = func(*container.TabItem) {
+		ui.refreshHistory()
+		if img, err := ui.getCurrentImage(); err == nil {
+			ui.selectionCallback(img)
+		}
+	}
 
 	histograms := fyne.NewMenuItem("Histograms", nil)
 	histograms.ChildMenu = fyne.NewMenu("",
@@ -70,22 +100,53 @@ func (ui *UI) Init() {
 	rescaling.ChildMenu = fyne.NewMenu("",
 		fyne.NewMenuItem("Geometric", ui.rescaling),
 	)
+	zoom := fyne.NewMenuItem("Zoom", nil)
+	zoom.ChildMenu = fyne.NewMenu("",
+		fyne.NewMenuItem("Zoom In", ui.zoomIn),
+		fyne.NewMenuItem("Zoom Out", ui.zoomOut),
+		fyne.NewMenuItem("Fit to Window", ui.zoomFit),
+		fyne.NewMenuItem("Actual Size (100%)", ui.zoomReset),
+	)
+	inPlace := fyne.NewMenuItem("In-place editing", nil)
+	inPlace.Action = ui.toggleInPlace(inPlace)
 	ui.menu = fyne.NewMainMenu(
 		fyne.NewMenu("File",
 			fyne.NewMenuItem("Open", ui.openDialog),
+			fyne.NewMenuItem("Open Raw...", ui.openRawDialog),
 			fyne.NewMenuItem("Save As...", ui.saveAsDialog),
+			fyne.NewMenuItemSeparator(),
+			fyne.NewMenuItem("Batch...", ui.batchDialog),
+			fyne.NewMenuItem("Run pipeline...", ui.runPipelineDialog),
+			fyne.NewMenuItem("Export pipeline...", ui.exportPipelineDialog),
 		),
+		fyne.NewMenu("Edit",
+			fyne.NewMenuItem("Undo", ui.undo),
+			fyne.NewMenuItem("Redo", ui.redo),
+			fyne.NewMenuItemSeparator(),
+			inPlace,
+			fyne.NewMenuItem("History limit...", ui.historyLimitDialog),
+		),
+		ui.selectionMenu(),
 		fyne.NewMenu("Image",
 			fyne.NewMenuItem("Negative", ui.negativeOp),
 			fyne.NewMenuItem("Monochrome", ui.monochromeOp),
+			fyne.NewMenuItem("Luminance Weights...", ui.luminanceDialog),
+			fyne.NewMenuItem("Bit Depth...", ui.depthOp),
 			fyne.NewMenuItem("Adjust Brightness/Contrast", ui.adjustBrightnessAndContrastOp),
+			fyne.NewMenuItem("Adjust Channels Brightness/Contrast...", ui.adjustChannelsBrightnessAndContrastOp),
 			fyne.NewMenuItem("Linear Transformation", ui.linearTransformationOp),
 			fyne.NewMenuItemSeparator(),
 			fyne.NewMenuItem("Gamma Correction", ui.gammaCorrectionOp),
 			fyne.NewMenuItem("Difference", ui.imgDifference),
 			fyne.NewMenuItem("Change Map From..", ui.imgChangeMap),
+			fyne.NewMenuItem("Threshold...", ui.thresholdOp),
+			fyne.NewMenuItem("Adaptive Threshold...", ui.adaptiveThresholdOp),
 			fyne.NewMenuItem("Equalization", ui.equializationOp),
+			fyne.NewMenuItem("CLAHE...", ui.claheOp),
 			fyne.NewMenuItem("Histogram Igualation", ui.histogramEqual),
+			fyne.NewMenuItem("Histogram Specification...", ui.histogramSpecificationOp),
+			fyne.NewMenuItemSeparator(),
+			ui.colorSpaceMenuItem(),
 		),
 		fyne.NewMenu("Transformation",
 			mirror,
@@ -93,15 +154,21 @@ func (ui *UI) Init() {
 			fyne.NewMenuItem("Transpose", ui.transpose),
 			rescaling,
 		),
+		ui.morphologyMenu(),
+		ui.filtersMenu(),
 		fyne.NewMenu("View",
 			fyne.NewMenuItem("Info", ui.infoView),
 			histograms,
+			fyne.NewMenuItem("Selection statistics", ui.selectionStatisticsView),
+			fyne.NewMenuItem("History", ui.toggleHistoryPanel),
+			fyne.NewMenuItemSeparator(),
+			zoom,
 		),
 	)
 
 	ui.MainWindow.SetMainMenu(ui.menu)
 	ui.MainWindow.Resize(fyne.NewSize(500, 500))
-	ui.MainWindow.SetContent(container.NewBorder(nil, container.NewBorder(nil, nil, ui.label, ui.progessBar), nil, nil, ui.tabs))
+	ui.MainWindow.SetContent(container.NewBorder(nil, container.NewBorder(nil, nil, ui.label, ui.progessBar), nil, ui.historyPanel, ui.tabs))
 	ui.MainWindow.ShowAndRun()
 }
 
@@ -125,62 +192,109 @@ func (ui *UI) openDialog() {
 		ui.progessBar.Show()
 		img, err := ourimage.NewFromPath(reader.URI().Path(), reader.URI().Name(),
 			ui.label, ui.MainWindow, ui.ROIcallback, ui.closeTabsCallback)
+		ui.progessBar.Hide()
+		ui.progessBar.Stop()
 		if err != nil {
 			dialog.ShowError(err, ui.MainWindow)
+			return
 		}
 		ui.newImage(img)
-		ui.progessBar.Hide()
-		ui.progessBar.Stop()
 	}, ui.MainWindow)
-	dialog.SetFilter(storage.NewExtensionFileFilter([]string{".png", ".jpeg", ".jpg", ".tfe", ".tif"}))
+	dialog.SetFilter(storage.NewExtensionFileFilter(processing.Extensions))
 	dialog.Show()
 }
 
 func (ui *UI) saveAsDialog() {
-	if ui.tabs.SelectedIndex() == -1 {
-		dialog.ShowError(fmt.Errorf("no image selected"), ui.MainWindow)
+	img, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
 		return
 	}
-	selectionWidget := widget.NewRadioGroup([]string{"png", "jpg", "tif"}, func(string) {})
-	selectionWidget.SetSelected("png")
-	dialog.ShowCustomConfirm("Select format", "Ok", "Cancel", selectionWidget,
+	var options processing.SaveOptions
+	format := "png"
+	qualityValue := binding.NewFloat()
+	qualityValue.Set(jpeg.DefaultQuality)
+	qualitySlider := widget.NewSliderWithData(1, 100, qualityValue)
+	quality := container.NewVBox(
+		container.NewCenter(widget.NewLabelWithData(binding.FloatToStringWithFormat(qualityValue, "Quality: %.0f"))), qualitySlider)
+	estimate := newSizeEstimate(img)
+	update := func() {
+		jpegQuality, _ := qualityValue.Get()
+		options.JPEGQuality = int(jpegQuality)
+		estimate.update(format, options)
+	}
+	pngCompression := widget.NewSelect(processing.PNGCompressions(), func(name string) {
+		options.PNGCompression, _ = processing.ParsePNGCompression(name) // The options are the names
+		update()
+	})
+	pngCompression.SetSelected(options.PNGCompression.String())
+	tiffCompression := widget.NewSelect(processing.TIFFCompressions(), func(name string) {
+		options.TIFFCompression, _ = processing.ParseTIFFCompression(name)
+		update()
+	})
+	tiffCompression.SetSelected(options.TIFFCompression.String())
+	plain := widget.NewCheck("Plain (ASCII)", func(checked bool) {
+		options.PNMPlain = checked
+		update()
+	})
+	// Only the settings of the selected format are shown
+	settings := map[string]fyne.CanvasObject{"png": pngCompression, "jpg": quality, "tif": tiffCompression,
+		"pgm": plain, "ppm": plain, "pnm": plain}
+	selectionWidget := widget.NewRadioGroup(processing.SaveFormats, func(selected string) {
+		format = selected
+		for _, setting := range settings {
+			setting.Hide()
+		}
+		if setting, ok := settings[format]; ok {
+			setting.Show()
+		}
+		update()
+	})
+	selectionWidget.SetSelected(format)
+	qualityValue.AddListener(binding.NewDataListener(update))
+	content := container.NewVBox(selectionWidget, pngCompression, quality, tiffCompression, plain, estimate.label)
+	dialog.ShowCustomConfirm("Select format", "Ok", "Cancel", content,
 		func(choice bool) {
+			estimate.stop()
 			if !choice {
 				return
 			}
-			dialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
-				if err != nil {
-					dialog.ShowError(err, ui.MainWindow)
-				}
-				if writer == nil {
-					return
-				}
-				outputFile, errFile := os.Create(writer.URI().Path())
-				if errFile != nil {
-					dialog.ShowError(errFile, ui.MainWindow)
-				}
-				defer outputFile.Close()
-
-				img, _ := ui.getCurrentImage() // Already checked
-				err = img.Save(outputFile, selectionWidget.Selected)
-				if err != nil {
-					dialog.ShowError(err, ui.MainWindow)
-				}
-			}, ui.MainWindow)
-			formatedName := func(originalName, format string) string {
-				pointIndex := strings.LastIndex(originalName, ".")
-				if pointIndex == -1 {
-					return originalName + "." + format
-				}
-				return originalName[:pointIndex+1] + format
-			}(ui.tabs.Selected().Text, selectionWidget.Selected)
-			dialog.SetFileName(formatedName)
-			dialog.SetFilter(storage.NewExtensionFileFilter([]string{"." + selectionWidget.Selected}))
-			dialog.Show()
+			ui.saveDialog(img, format, options)
 		},
 		ui.MainWindow)
 }
 
+func (ui *UI) saveDialog(img *ourimage.OurImage, format string, options processing.SaveOptions) {
+	dialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, ui.MainWindow)
+		}
+		if writer == nil {
+			return
+		}
+		outputFile, errFile := os.Create(writer.URI().Path())
+		if errFile != nil {
+			dialog.ShowError(errFile, ui.MainWindow)
+		}
+		defer outputFile.Close()
+
+		err = img.Save(outputFile, format, options)
+		if err != nil {
+			dialog.ShowError(err, ui.MainWindow)
+		}
+	}, ui.MainWindow)
+	formatedName := func(originalName, format string) string {
+		pointIndex := strings.LastIndex(originalName, ".")
+		if pointIndex == -1 {
+			return originalName + "." + format
+		}
+		return originalName[:pointIndex+1] + format
+	}(img.Name(), format)
+	dialog.SetFileName(formatedName)
+	dialog.SetFilter(storage.NewExtensionFileFilter([]string{"." + format}))
+	dialog.Show()
+}
+
 func (ui *UI) ROIcallback(cropped *ourimage.OurImage) {
 	dialog.ShowCustomConfirm("Do you want this sub-image?", "Ok", "Cancel", container.NewCenter(cropped),
 		func(choice bool) {
@@ -193,12 +307,17 @@ func (ui *UI) ROIcallback(cropped *ourimage.OurImage) {
 }
 
 func (ui *UI) newImage(img *ourimage.OurImage) {
-	ui.tabs.Append(container.NewTabItem(img.Name(), container.NewScroll(container.New(layout.NewCenterLayout(), img))))
+	img.SetTool(ui.selectionTool)
+	img.SetWandTolerance(ui.wandTolerance)
+	img.SetSelectionCallback(ui.selectionCallback)
+	ui.tabs.Append(container.NewTabItem(img.Name(), img.View()))
 	ui.tabs.SelectIndex(len(ui.tabs.Items) - 1) // Select the last one
 	ui.tabsElements = append(ui.tabsElements, img)
 	if len(ui.tabsElements) != 0 {
 		ui.tabs.Show()
 	}
+	ui.refreshHistory()
+	ui.selectionCallback(img)
 }
 
 func (ui *UI) removeImage(index int) error {
@@ -210,6 +329,7 @@ func (ui *UI) removeImage(index int) error {
 	if len(ui.tabsElements) == 0 {
 		ui.tabs.Hide()
 	}
+	ui.refreshHistory()
 	return nil
 }
 
@@ -0,0 +1,58 @@
+package userinterface
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+)
+
+func (ui *UI) initZoom() {
+	shortcuts := map[fyne.KeyName]func(){
+		fyne.KeyEqual: ui.zoomIn,
+		fyne.KeyPlus:  ui.zoomIn,
+		fyne.KeyMinus: ui.zoomOut,
+		fyne.Key0:     ui.zoomReset,
+		fyne.Key9:     ui.zoomFit,
+	}
+	for key, action := range shortcuts {
+		action := action
+		ui.MainWindow.Canvas().AddShortcut(&desktop.CustomShortcut{KeyName: key, Modifier: desktop.ControlModifier},
+			func(fyne.Shortcut) { action() })
+	}
+}
+
+func (ui *UI) zoomIn() {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	currentImage.ZoomIn()
+}
+
+func (ui *UI) zoomOut() {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	currentImage.ZoomOut()
+}
+
+func (ui *UI) zoomFit() {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	currentImage.ZoomFit()
+}
+
+func (ui *UI) zoomReset() {
+	currentImage, err := ui.getCurrentImage()
+	if err != nil {
+		dialog.ShowError(err, ui.MainWindow)
+		return
+	}
+	currentImage.ZoomReset()
+}